@@ -0,0 +1,119 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command frugal-idl prints the Thrift IDL of frugal-tagged Go structs.
+//
+// Usage:
+//
+//	frugal-idl [-o output.thrift] <package> <Type>...
+//
+// It must be run in the Go module which can import the package and github.com/cloudwego/frugal,
+// since it builds a temporary program calling frugal.ExportIDL with the types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+)
+
+var programTmpl = template.Must(template.New("main").Parse(`package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/cloudwego/frugal"
+
+	target {{printf "%q" .Package}}
+)
+
+func main() {
+	s, err := frugal.ExportIDL(
+{{- range .Types}}
+		reflect.TypeOf((*target.{{.}})(nil)),
+{{- end}}
+	)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Print(s)
+}
+`))
+
+func main() {
+	output := flag.String("o", "", "write the IDL to the file instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-o output.thrift] <package> <Type>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*output, flag.Arg(0), flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "frugal-idl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(output, pkg string, types []string) error {
+	for _, t := range types {
+		if !token.IsIdentifier(t) || !token.IsExported(t) {
+			return fmt.Errorf("invalid type name %q", t)
+		}
+	}
+
+	// the program must be in the current module to import the package
+	dir, err := os.MkdirTemp(".", "_frugal_idl")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	f, err := os.Create(filepath.Join(dir, "main.go"))
+	if err != nil {
+		return err
+	}
+	err = programTmpl.Execute(f, struct {
+		Package string
+		Types   []string
+	}{pkg, types})
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+
+	w := os.Stdout
+	if output != "" {
+		if w, err = os.Create(output); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+	cmd := exec.Command("go", "run", "./"+filepath.ToSlash(dir))
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
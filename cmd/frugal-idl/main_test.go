@@ -0,0 +1,59 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+const samplePkg = "github.com/cloudwego/frugal/cmd/frugal-idl/testdata/sample"
+
+func TestRun(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "sample.thrift")
+	assert.Nil(t, run(output, samplePkg, []string{"Order"}))
+	b, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, `struct Item {
+  1: required i64 ID
+  2: string Name
+}
+
+struct Order {
+  1: list<Item> Items
+  2: optional map<string, bool> Attrs
+}
+
+`, string(b))
+
+	// the temporary program is removed
+	m, err := filepath.Glob("_frugal_idl*")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(m))
+}
+
+func TestRunErrors(t *testing.T) {
+	err := run("", samplePkg, []string{"order"})
+	assert.True(t, err != nil && strings.Contains(err.Error(), `invalid type name "order"`), err)
+
+	err = run(os.DevNull, samplePkg, []string{"Unknown"})
+	assert.True(t, err != nil, "unknown type")
+}
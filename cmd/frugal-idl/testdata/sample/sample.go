@@ -0,0 +1,28 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sample contains the structs for testing frugal-idl.
+package sample
+
+type Item struct {
+	ID   int64  `frugal:"1,required,i64"`
+	Name string `frugal:"2,default,string"`
+}
+
+type Order struct {
+	Items []*Item         `frugal:"1,default,list<Item>"`
+	Attrs map[string]bool `frugal:"2,optional,map<string:bool>"`
+}
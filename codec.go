@@ -0,0 +1,86 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frugal
+
+import (
+	"encoding/binary"
+	"io"
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/defs"
+	ireflect "github.com/cloudwego/frugal/internal/reflect"
+	"github.com/cloudwego/frugal/schema"
+)
+
+// RegisterCodec registers a custom codec of type T, which is encoded as the Thrift type wt.
+// wt must be a scalar type other than BOOL, like schema.I64 or schema.BINARY.
+//
+// The funcs work with Thrift binary protocol, values are converted for compact protocol:
+// encode appends v to b, including the length header for STRING and BINARY,
+// decode decodes a value from b and returns the number of bytes read,
+// and size returns the encoded size of v.
+//
+// Fields of type T, or *T for optional fields, are encoded with the codec,
+// so are elements and keys of containers. The type in tags is wt, like `frugal:"1,default,i64"`.
+// T can't be a pointer type. It must be called before types with T fields are used, like in init(),
+// and a type can only be registered once.
+func RegisterCodec[T any](wt schema.Kind, encode func(b []byte, v T) ([]byte, error), decode func(b []byte) (T, int, error), size func(v T) int) error {
+	return ireflect.RegisterCodec(reflect.TypeOf((*T)(nil)).Elem(), defs.Tag(wt), &ireflect.Codec{
+		Append: func(b []byte, p unsafe.Pointer) ([]byte, error) {
+			return encode(b, *(*T)(p))
+		},
+		Decode: func(b []byte, p unsafe.Pointer) (int, error) {
+			v, n, err := decode(b)
+			if err == nil {
+				*(*T)(p) = v
+			}
+			return n, err
+		},
+		Size: func(p unsafe.Pointer) int {
+			return size(*(*T)(p))
+		},
+	})
+}
+
+// RegisterTimeCodec registers the codec of time.Time, which is encoded as i64 of unix nanoseconds,
+// decoded values are in UTC. The zero time.Time is encoded as 0, and vice versa.
+//
+// time.Duration needs no codec, it's int64 and encoded as i64 of nanoseconds.
+func RegisterTimeCodec() error {
+	return RegisterCodec(schema.I64, encodeTime, decodeTime, func(time.Time) int { return 8 })
+}
+
+func encodeTime(b []byte, v time.Time) ([]byte, error) {
+	var n int64
+	if !v.IsZero() {
+		n = v.UnixNano()
+	}
+	return binary.BigEndian.AppendUint64(b, uint64(n)), nil
+}
+
+func decodeTime(b []byte) (time.Time, int, error) {
+	if len(b) < 8 {
+		return time.Time{}, 0, io.ErrShortBuffer
+	}
+	n := int64(binary.BigEndian.Uint64(b))
+	if n == 0 {
+		return time.Time{}, 8, nil
+	}
+	return time.Unix(0, n).UTC(), 8, nil
+}
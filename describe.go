@@ -0,0 +1,69 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frugal
+
+import (
+	"reflect"
+
+	"github.com/cloudwego/frugal/idl"
+	"github.com/cloudwego/frugal/internal/defs"
+	"github.com/cloudwego/frugal/schema"
+)
+
+// Describe returns the schema of the frugal-tagged struct type vt, which can also be a pointer to a struct.
+// It returns the same errors as encoding or decoding values of vt for invalid tags.
+//
+// The schema is built on every call, it's safe to change it.
+// Field names are taken from "thrift" tags if any, otherwise they're names of the Go fields.
+// Default values are taken from the InitDefault method, and values of enums are unknown.
+func Describe(vt reflect.Type) (*schema.Struct, error) {
+	return defs.Describe(vt)
+}
+
+// ExportIDL returns the Thrift IDL document of the frugal-tagged struct types and all the types they refer to.
+// Enums are declared as i32 since their values are unknown, and default values are taken from InitDefault.
+func ExportIDL(types ...reflect.Type) (string, error) {
+	ss, err := defs.DescribeTypes(types)
+	if err != nil {
+		return "", err
+	}
+	b, err := idl.Format(ss...)
+	return string(b), err
+}
+
+// CheckCompatibility compares two versions of a frugal-tagged struct type recursively,
+// and returns the breaking and non-breaking changes, see schema.Compare for details.
+//
+// It's designed for unit tests against golden types, like:
+//
+//	changes, err := frugal.CheckCompatibility(reflect.TypeOf(golden.Request{}), reflect.TypeOf(Request{}))
+//	for _, c := range changes {
+//		if c.Breaking {
+//			t.Error(c)
+//		}
+//	}
+func CheckCompatibility(oldType, newType reflect.Type) ([]schema.Change, error) {
+	old, err := Describe(oldType)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Describe(newType)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Compare(old, s), nil
+}
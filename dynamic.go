@@ -0,0 +1,66 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frugal
+
+import (
+	"fmt"
+
+	"github.com/cloudwego/frugal/internal/reflect"
+	"github.com/cloudwego/frugal/schema"
+)
+
+// The dynamic value model used by DecodeDynamic and EncodeDynamic:
+//
+//	bool                       bool
+//	byte, i16, i32, i64        int8, int16, int32, int64
+//	double                     float64
+//	string, binary             string, []byte
+//	uuid                       [16]byte
+//	enum                       int32
+//	struct                     map[string]interface{}, keyed by field names
+//	list, set                  []interface{}
+//	map                        map[interface{}]interface{}, binary keys are decoded as string
+//
+// When encoding, integers of any Go type are accepted as long as they fit,
+// string and []byte are interchangeable, enums can also be names of the values,
+// and map[string]interface{} can be used for maps.
+// nil values in structs are treated as unset fields.
+
+// DynamicEncodedSize measures the encoded size of v with Thrift Binary Protocol by the schema s.
+func DynamicEncodedSize(s *schema.Struct, v map[string]interface{}) (int, error) {
+	return reflect.DynamicEncodedSize(s, v)
+}
+
+// EncodeDynamic serializes v into buf with Thrift Binary Protocol by the schema s.
+// buf must be large enough to contain the entire serialization result, see DynamicEncodedSize.
+func EncodeDynamic(buf []byte, s *schema.Struct, v map[string]interface{}) (int, error) {
+	ret, err := reflect.AppendDynamic(buf[:0], s, v)
+	if len(ret) > len(buf) {
+		return 0, fmt.Errorf("index out of range [%d] with length %d.\n"+ //nolint:staticcheck // ST1005: newlines
+			"Please make sure the input will not be changed after calling DynamicEncodedSize or during EncodeDynamic(concurrency issues).",
+			len(ret), len(buf))
+	}
+	return len(ret), err
+}
+
+// DecodeDynamic deserializes buf with Thrift Binary Protocol by the schema s,
+// and returns the struct in the dynamic value model.
+//
+// Unknown fields are skipped, and absent fields with default values are set to the defaults.
+func DecodeDynamic(buf []byte, s *schema.Struct) (map[string]interface{}, int, error) {
+	return reflect.DecodeDynamic(buf, s)
+}
@@ -18,6 +18,7 @@ package frugal
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/cloudwego/frugal/internal/reflect"
 	"github.com/cloudwego/gopkg/protocol/thrift"
@@ -30,8 +31,19 @@ func EncodedSize(val interface{}) int {
 
 // EncodeObject serializes val into buf with Thrift Binary Protocol, with optional Zero-Copy thrift.NocopyWriter.
 // buf must be large enough to contain the entire serialization result.
+//
+// If w is not nil, strings or binaries not smaller than the threshold (see SetNocopyWriteThreshold)
+// are written by w.WriteDirect instead of being copied to buf,
+// and the returned length excludes them. It still needs len(buf) >= EncodedSize(val).
 func EncodeObject(buf []byte, w thrift.NocopyWriter, val interface{}) (int, error) {
-	ret, err := reflect.Append(buf[:0], val)
+	var ret []byte
+	var err error
+	if w == nil {
+		ret, err = reflect.Append(buf[:0], val)
+	} else {
+		// cap is set to len(buf) for the remainCap of WriteDirect
+		ret, err = reflect.AppendNocopy(buf[:0:len(buf)], w, val)
+	}
 	if len(ret) > len(buf) {
 		return 0, fmt.Errorf("index out of range [%d] with length %d.\n"+ //nolint:staticcheck // ST1005: newlines
 			"Please make sure the input will not be changed after calling EncodedSize or during EncodeObject(concurrency issues).",
@@ -40,11 +52,128 @@ func EncodeObject(buf []byte, w thrift.NocopyWriter, val interface{}) (int, erro
 	return len(ret), err
 }
 
+// EncodeTo serializes val to w with Thrift Binary Protocol, and returns the number of bytes written.
+// It uses a small fixed-size buffer and flushes it as it goes, EncodedSize is not needed.
+// Large string or binary fields are written to w directly without copying.
+func EncodeTo(w io.Writer, val interface{}) (int, error) {
+	return reflect.EncodeTo(w, val)
+}
+
+// CheckRequired returns thrift.ProtocolException with INVALID_DATA if any required field of val
+// or its nested structs is not set, like a nil struct pointer or a nil []byte,
+// which is the same check as encoding with SetCheckRequired(true).
+func CheckRequired(val interface{}) error {
+	return reflect.CheckRequired(val)
+}
+
 // DecodeObject deserializes buf into val with Thrift Binary Protocol.
 func DecodeObject(buf []byte, val interface{}) (int, error) {
 	return reflect.Decode(buf, val)
 }
 
+// DecodeObjectWithOptions is the same as DecodeObject, but with options like WithMaxStringSize.
+func DecodeObjectWithOptions(buf []byte, val interface{}, options ...Option) (int, error) {
+	return reflect.DecodeWithOptions(buf, val, newOptions(options))
+}
+
+// DecodeFrom deserializes a struct from r into val with Thrift Binary Protocol.
+// Bytes are read on demand, it doesn't need the whole struct to be buffered in advance.
+// It returns the number of bytes consumed, and io.EOF if no bytes are available.
+//
+// If r is not a *bufio.Reader, it's wrapped by bufio.NewReader,
+// and bytes after the struct may be read ahead and discarded.
+// Use a *bufio.Reader for reading subsequent data from r.
+func DecodeFrom(r io.Reader, val interface{}) (int, error) {
+	return reflect.DecodeFrom(r, val)
+}
+
+// DecodeFromWithOptions is the same as DecodeFrom, but with options like WithMaxStringSize.
+func DecodeFromWithOptions(r io.Reader, val interface{}, options ...Option) (int, error) {
+	return reflect.DecodeFromWithOptions(r, val, newOptions(options))
+}
+
+// CompactEncodedSize measures the encoded size of val with Thrift Compact Protocol.
+func CompactEncodedSize(val interface{}) int {
+	return reflect.CompactEncodedSize(val)
+}
+
+// EncodeCompact serializes val into buf with Thrift Compact Protocol.
+// buf must be large enough to contain the entire serialization result, see CompactEncodedSize.
+//
+// NOTE: _unknownFields keeps the raw bytes of the protocol it's decoded with,
+// DO NOT mix up the protocols if a struct has _unknownFields.
+func EncodeCompact(buf []byte, val interface{}) (int, error) {
+	ret, err := reflect.AppendCompact(buf[:0], val)
+	if len(ret) > len(buf) {
+		return 0, fmt.Errorf("index out of range [%d] with length %d.\n"+ //nolint:staticcheck // ST1005: newlines
+			"Please make sure the input will not be changed after calling CompactEncodedSize or during EncodeCompact(concurrency issues).",
+			len(ret), len(buf))
+	}
+	return len(ret), err
+}
+
+// DecodeCompact deserializes buf into val with Thrift Compact Protocol.
+func DecodeCompact(buf []byte, val interface{}) (int, error) {
+	return reflect.DecodeCompact(buf, val)
+}
+
+// DecodeCompactWithOptions is the same as DecodeCompact, but with options like WithMaxStringSize.
+func DecodeCompactWithOptions(buf []byte, val interface{}, options ...Option) (int, error) {
+	return reflect.DecodeCompactWithOptions(buf, val, newOptions(options))
+}
+
+// MessageHeader is the header of a Thrift message.
+type MessageHeader struct {
+	Name  string
+	Type  thrift.TMessageType
+	SeqID int32
+
+	// Strict reports whether the header is in strict(versioned) format,
+	// it's false for old-style headers without version.
+	Strict bool
+}
+
+// MessageEncodedSize measures the encoded size of a message with Thrift Binary Protocol.
+// It's also large enough for EncodeMessageNonStrict.
+func MessageEncodedSize(name string, msgType thrift.TMessageType, args interface{}) int {
+	return reflect.MessageEncodedSize(name, msgType, true, args)
+}
+
+// EncodeMessage serializes a message with strict header and args as payload into buf with Thrift Binary Protocol.
+// buf must be large enough to contain the entire serialization result, see MessageEncodedSize.
+//
+// args is usually the args or result wrapper struct of a method.
+// For thrift.EXCEPTION messages, args can be an error like *thrift.ApplicationException,
+// it's encoded as TApplicationException.
+func EncodeMessage(buf []byte, name string, msgType thrift.TMessageType, seqID int32, args interface{}) (int, error) {
+	return encodeMessage(buf, name, msgType, seqID, true, args)
+}
+
+// EncodeMessageNonStrict is the same as EncodeMessage, but with old-style header without version.
+func EncodeMessageNonStrict(buf []byte, name string, msgType thrift.TMessageType, seqID int32, args interface{}) (int, error) {
+	return encodeMessage(buf, name, msgType, seqID, false, args)
+}
+
+func encodeMessage(buf []byte, name string, msgType thrift.TMessageType, seqID int32, strict bool, args interface{}) (int, error) {
+	ret, err := reflect.AppendMessage(buf[:0], name, msgType, seqID, strict, args)
+	if len(ret) > len(buf) {
+		return 0, fmt.Errorf("index out of range [%d] with length %d.\n"+ //nolint:staticcheck // ST1005: newlines
+			"Please make sure the input will not be changed after calling MessageEncodedSize or during EncodeMessage(concurrency issues).",
+			len(ret), len(buf))
+	}
+	return len(ret), err
+}
+
+// DecodeMessage deserializes a message with either strict or non-strict header from buf,
+// and decodes the payload into args with Thrift Binary Protocol.
+//
+// For thrift.EXCEPTION messages, the payload is decoded as TApplicationException
+// and returned as a *thrift.ApplicationException error, args is left untouched.
+func DecodeMessage(buf []byte, args interface{}) (MessageHeader, int, error) {
+	name, msgType, seqID, strict, n, err := reflect.DecodeMessage(buf, args)
+	return MessageHeader{Name: name, Type: msgType, SeqID: seqID, Strict: strict}, n, err
+}
+
 // Pretouch ...
 //
 // Deprecated: It was for JIT
@@ -0,0 +1,187 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package idl loads Thrift IDL files into schemas at runtime.
+//
+// Structs, unions, exceptions, enums, typedefs, consts and includes are supported,
+// and field default values are converted to the dynamic value model of frugal.DecodeDynamic.
+// Services and annotations are parsed and ignored.
+//
+// Example:
+//
+//	f, err := idl.ParseFile("echo.thrift")
+//	if err != nil {
+//		return err
+//	}
+//	req := f.Struct("EchoRequest")
+//	buf := make([]byte, 0, 1024)
+//	n, err := frugal.EncodeDynamic(buf, req, map[string]interface{}{"msg": "hello"})
+package idl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cloudwego/frugal/schema"
+)
+
+// File is a loaded IDL file.
+type File struct {
+	Path       string
+	Namespaces map[string]string // language -> namespace
+
+	// Includes are the included files keyed by the names used to refer to them,
+	// which are the aliases like `include "a/common.thrift" as acommon` if given,
+	// or the file names without the extension.
+	Includes map[string]*File
+
+	Structs  []*schema.Struct // structs, unions and exceptions in the order of declaration
+	Enums    []*schema.Enum
+	Typedefs map[string]*schema.Type
+	Consts   map[string]*Const
+}
+
+// Const is a const defined in IDL.
+type Const struct {
+	Name  string
+	Type  *schema.Type
+	Value interface{} // in the dynamic value model
+}
+
+// ParseFile loads the IDL file and the files it includes.
+//
+// Included files are searched in the directory of the including file first, then in includeDirs.
+func ParseFile(path string, includeDirs ...string) (*File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(path, src, includeDirs...)
+}
+
+// Parse is like ParseFile, but reads the IDL of path from src.
+func Parse(path string, src []byte, includeDirs ...string) (*File, error) {
+	l := &loader{dirs: includeDirs, files: map[string]*File{}}
+	return l.load(path, src)
+}
+
+// Struct returns the struct, union or exception with the given name, or nil if not found.
+// Names of the included files can be used as prefixes, like "base.Base".
+func (f *File) Struct(name string) *schema.Struct {
+	if g, n := f.scope(name); g != nil {
+		for _, s := range g.Structs {
+			if s.Name == n {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// Enum returns the enum with the given name, or nil if not found.
+// Names of the included files can be used as prefixes.
+func (f *File) Enum(name string) *schema.Enum {
+	if g, n := f.scope(name); g != nil {
+		for _, e := range g.Enums {
+			if e.Name == n {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// scope returns the file and the unprefixed name which the name refers to.
+func (f *File) scope(name string) (*File, string) {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return f.Includes[name[:i]], name[i+1:]
+	}
+	return f, name
+}
+
+type loader struct {
+	dirs  []string
+	files map[string]*File // loaded files by absolute paths, nil if it's being loaded
+}
+
+func (l *loader) load(path string, src []byte) (*File, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := l.files[abs]; ok {
+		if f == nil {
+			return nil, fmt.Errorf("%s: circular include", path)
+		}
+		return f, nil
+	}
+	if src == nil {
+		if src, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	l.files[abs] = nil
+	doc, err := parse(path, src)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{
+		Path:       path,
+		Namespaces: doc.namespaces,
+		Includes:   map[string]*File{},
+		Typedefs:   map[string]*schema.Type{},
+		Consts:     map[string]*Const{},
+	}
+	for _, inc := range doc.includes {
+		p, err := l.find(filepath.Dir(path), inc.path)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d:%d: %w", path, inc.pos.line, inc.pos.col, err)
+		}
+		g, err := l.load(p, nil)
+		if err != nil {
+			return nil, err
+		}
+		name := inc.alias
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(p), filepath.Ext(p))
+		}
+		if h := f.Includes[name]; h != nil && h != g {
+			return nil, fmt.Errorf("%s:%d:%d: include name %q is used by both %s and %s, an alias is required",
+				path, inc.pos.line, inc.pos.col, name, h.Path, g.Path)
+		}
+		f.Includes[name] = g
+	}
+	if err := newResolver(f, doc).resolve(); err != nil {
+		return nil, err
+	}
+	l.files[abs] = f
+	return f, nil
+}
+
+func (l *loader) find(dir, path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return path, nil
+	}
+	for _, d := range append([]string{dir}, l.dirs...) {
+		p := filepath.Join(d, path)
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("include file %q not found", path)
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package idl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/schema"
+)
+
+func TestParseFile(t *testing.T) {
+	f, err := ParseFile("testdata/example.thrift")
+	assert.Nil(t, err)
+	assert.Equal(t, "example", f.Namespaces["go"])
+	base := f.Includes["base"]
+	assert.True(t, base != nil)
+	assert.Equal(t, "base", base.Namespaces["go"])
+
+	status := f.Enum("base.Status")
+	assert.True(t, status != nil && status == base.Enum("Status"))
+	assert.DeepEqual(t, []schema.EnumValue{{Name: "OK", Value: 0}, {Name: "FAILED", Value: 10}, {Name: "UNKNOWN", Value: 11}}, status.Values)
+	assert.DeepEqual(t, int32(0x100), base.Consts["MaxSize"].Value)
+	assert.Equal(t, "map<string,string>", base.Typedefs["Extra"].String())
+
+	assert.Equal(t, "list<User>", f.Typedefs["Users"].String())
+	assert.DeepEqual(t, 1.0, f.Consts["Ratio"].Value)
+	assert.DeepEqual(t, []interface{}{int32(0), int32(11)}, f.Consts["Statuses"].Value)
+
+	u := f.Struct("User")
+	assert.True(t, u != nil)
+	var types []string
+	for _, fd := range u.Fields {
+		types = append(types, fd.Requiredness.String()+" "+fd.Type.String()+" "+fd.Name)
+	}
+	assert.DeepEqual(t, []string{
+		"required i64 ID",
+		"default string Name",
+		"optional binary Avatar",
+		"default bool Active",
+		"default double Score",
+		"default set<i16> Tags",
+		"default map<i32,Status> Flags",
+		"default Base Base",
+		"optional User Parent",
+	}, types)
+	assert.True(t, u.FieldByName("Parent").Type.Struct == u)
+	assert.True(t, u.FieldByName("Base").Type.Struct == f.Struct("base.Base"))
+	assert.DeepEqual(t, "anonymous", u.FieldByName("Name").Default)
+	assert.DeepEqual(t, []byte("x"), u.FieldByName("Avatar").Default)
+	assert.DeepEqual(t, true, u.FieldByName("Active").Default)
+	assert.DeepEqual(t, 1.0, u.FieldByName("Score").Default)
+	assert.DeepEqual(t, []interface{}{int16(1), int16(2)}, u.FieldByName("Tags").Default)
+	assert.DeepEqual(t, map[interface{}]interface{}{int32(1): int32(10)}, u.FieldByName("Flags").Default)
+	assert.DeepEqual(t, map[string]interface{}{
+		"LogID": "x",
+		"Extra": map[interface{}]interface{}{"k": "v"},
+	}, u.FieldByName("Base").Default)
+
+	assert.True(t, f.Struct("Target").Union)
+	assert.True(t, f.Struct("NotFound").Exception)
+	assert.True(t, f.Struct("UserService") == nil)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src, err string
+	}{
+		{"struct A { 1: B b }", "t.thrift:1:15: undefined type B"},
+		{"struct A { 1: i32 a\n 1: i32 b }", "duplicated field id 1"},
+		{"struct A {}\nenum A {}", "t.thrift:2:6: A redeclared"},
+		{"typedef B A\ntypedef A B", "circular typedef"},
+		{"const i32 A = B\nconst i32 B = A", "circular const"},
+		{"const i8 A = 128", "128 overflows byte"},
+		{"const string A = 1", "cannot use 1 (int64) as string"},
+		{"struct A { 1: i32 a } const A X = {\"b\": 1}", `unknown field "b" of A`},
+		{"const list<i32> A = {}", "cannot use map as list<i32>"},
+		{"const i32 A = X.Y", "undefined X.Y"},
+		{"struct A { 1: i32 a = \"x }", "string literal not terminated"},
+		{"/* struct A {}", "comment not terminated"},
+		{"struct A { 1 i32 a }", `t.thrift:1:14: expect ":", got "i32"`},
+		{"include \"nonexistent.thrift\"", "not found"},
+		{"const uuid A = \"x\"", "cannot use"},
+		{"const string A = \"\\q\"", `t.thrift:1:19: unknown escape sequence \q`},
+		{"const double A = -.", `t.thrift:1:18: unexpected character '-'`},
+	} {
+		_, err := Parse("testdata/t.thrift", []byte(tc.src))
+		assert.True(t, err != nil && strings.Contains(err.Error(), tc.err), tc.src, err)
+	}
+
+	_, err := Parse("testdata/example.thrift", []byte(`include "example.thrift"`))
+	assert.True(t, err != nil && strings.Contains(err.Error(), "circular include"), err)
+}
+
+func TestParseIncludeAlias(t *testing.T) {
+	f, err := Parse("testdata/t.thrift", []byte(`
+include "a/common.thrift"
+include "b/common.thrift" as bcommon
+struct S {
+  1: common.Common a
+  2: bcommon.Common b
+}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(f.Includes))
+	assert.Equal(t, "i32", f.Struct("common.Common").Fields[0].Type.String())
+	assert.Equal(t, "string", f.Struct("bcommon.Common").Fields[0].Type.String())
+	s := f.Struct("S")
+	assert.True(t, s.Fields[0].Type.Struct == f.Struct("common.Common"))
+	assert.True(t, s.Fields[1].Type.Struct == f.Struct("bcommon.Common"))
+
+	// files with the same name must not overwrite each other
+	_, err = Parse("testdata/t.thrift", []byte("include \"a/common.thrift\"\ninclude \"b/common.thrift\""))
+	assert.True(t, err != nil && strings.Contains(err.Error(), `t.thrift:2:9: include name "common" is used by both`), err)
+	_, err = Parse("testdata/t.thrift", []byte(`include "a/common.thrift" as a.b`))
+	assert.True(t, err != nil && strings.Contains(err.Error(), `invalid include alias "a.b"`), err)
+
+	// the same file can be included more than once
+	f, err = Parse("testdata/t.thrift", []byte("include \"a/common.thrift\"\ninclude \"a/../a/common.thrift\""))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(f.Includes))
+}
+
+func TestParseLiterals(t *testing.T) {
+	f, err := Parse("testdata/t.thrift", []byte(`
+const double A = -.5
+const double B = +.5
+const double C = .5e1
+const string S = "\\ \" \' \n \r \t \0"
+const string Q = '\''`))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, -0.5, f.Consts["A"].Value)
+	assert.DeepEqual(t, 0.5, f.Consts["B"].Value)
+	assert.DeepEqual(t, 5.0, f.Consts["C"].Value)
+	assert.DeepEqual(t, "\\ \" ' \n \r \t \x00", f.Consts["S"].Value)
+	assert.DeepEqual(t, "'", f.Consts["Q"].Value)
+}
+
+func TestFormat(t *testing.T) {
+	f, err := ParseFile("testdata/example.thrift")
+	assert.Nil(t, err)
+	b, err := Format(f.Structs...)
+	assert.Nil(t, err)
+	g, err := Parse("testdata/formatted.thrift", b)
+	assert.Nil(t, err, string(b))
+	for _, s := range f.Structs {
+		assert.DeepEqual(t, s, g.Struct(s.Name))
+	}
+	assert.DeepEqual(t, f.Enum("base.Status"), g.Enum("Status"))
+	assert.True(t, g.Struct("Base") != nil)
+
+	// output is stable
+	b2, err := Format(g.Structs...)
+	assert.Nil(t, err)
+	assert.Equal(t, string(b), string(b2))
+
+	// enums without values are declared as i32
+	e := &schema.Enum{Name: "E"}
+	s := &schema.Struct{Name: "S", Fields: []*schema.Field{
+		{ID: 1, Name: "e", Type: &schema.Type{Kind: schema.ENUM, Enum: e}, Default: int32(1)},
+		{ID: 2, Name: "m", Type: &schema.Type{Kind: schema.MAP,
+			Key: &schema.Type{Kind: schema.STRING}, Elem: &schema.Type{Kind: schema.DOUBLE}},
+			Default: map[interface{}]interface{}{"b\"\n": 1.5, "a": 2.0}, Requiredness: schema.Optional},
+	}}
+	b, err = Format(s)
+	assert.Nil(t, err)
+	assert.Equal(t, "struct S {\n  1: i32 e = 1\n  2: optional map<string, double> m = {\"a\": 2, \"b\\\"\\n\": 1.5}\n}\n\n", string(b))
+
+	_, err = Format(s, &schema.Struct{Name: "S"})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "conflicting declarations of S"), err)
+
+	// uuids are formatted as strings
+	s = &schema.Struct{Name: "U", Fields: []*schema.Field{
+		{ID: 1, Name: "id", Type: &schema.Type{Kind: schema.UUID},
+			Default: [16]byte{0: 0x12, 15: 0xab}},
+	}}
+	b, err = Format(s)
+	assert.Nil(t, err)
+	assert.Equal(t, "struct U {\n  1: uuid id = \"12000000-0000-0000-0000-0000000000ab\"\n}\n\n", string(b))
+	g, err = Parse("testdata/uuid.thrift", b)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, s, g.Struct("U"))
+}
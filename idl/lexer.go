@@ -0,0 +1,233 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package idl
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind uint8
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokInt
+	tokDouble
+	tokString
+	tokPunct // one of {}()<>[],;:=
+)
+
+type token struct {
+	kind tokenKind
+	text string // for tokString it's the unquoted value
+	pos  pos
+}
+
+type pos struct {
+	line, col int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "EOF"
+	}
+	return strconv.Quote(t.text)
+}
+
+// lexer splits Thrift IDL into tokens, comments are dropped.
+type lexer struct {
+	path string
+	src  []byte
+	off  int
+	pos  pos
+}
+
+func newLexer(path string, src []byte) *lexer {
+	return &lexer{path: path, src: src, pos: pos{1, 1}}
+}
+
+func (l *lexer) errorf(p pos, format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d:%d: %s", l.path, p.line, p.col, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) advance(n int) {
+	for _, c := range l.src[l.off : l.off+n] {
+		if c == '\n' {
+			l.pos.line++
+			l.pos.col = 1
+		} else {
+			l.pos.col++
+		}
+	}
+	l.off += n
+}
+
+func (l *lexer) skipSpacesAndComments() error {
+	for l.off < len(l.src) {
+		c := l.src[l.off]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			l.advance(1)
+		case c == '#' || bytes.HasPrefix(l.src[l.off:], []byte("//")):
+			n := len(l.src) - l.off
+			if i := bytes.IndexByte(l.src[l.off:], '\n'); i >= 0 {
+				n = i
+			}
+			l.advance(n)
+		case bytes.HasPrefix(l.src[l.off:], []byte("/*")):
+			i := bytes.Index(l.src[l.off+2:], []byte("*/"))
+			if i < 0 {
+				return l.errorf(l.pos, "comment not terminated")
+			}
+			l.advance(i + 4)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpacesAndComments(); err != nil {
+		return token{}, err
+	}
+	p := l.pos
+	if l.off >= len(l.src) {
+		return token{kind: tokEOF, pos: p}, nil
+	}
+	c := l.src[l.off]
+	switch {
+	case isIdentStart(c):
+		n := 1
+		for l.off+n < len(l.src) && isIdentPart(l.src[l.off+n]) {
+			n++
+		}
+		s := string(l.src[l.off : l.off+n])
+		l.advance(n)
+		return token{kind: tokIdent, text: s, pos: p}, nil
+
+	case isDigit(c) || (c == '.' && l.isDigitAt(l.off+1)) ||
+		((c == '+' || c == '-') && (l.isDigitAt(l.off+1) || l.isByteAt(l.off+1, '.') && l.isDigitAt(l.off+2))):
+		return l.number(p)
+
+	case c == '"' || c == '\'':
+		return l.literal(p, c)
+
+	case strings.IndexByte("{}()<>[],;:=", c) >= 0:
+		l.advance(1)
+		return token{kind: tokPunct, text: string(c), pos: p}, nil
+	}
+	return token{}, l.errorf(p, "unexpected character %q", c)
+}
+
+func (l *lexer) isByteAt(i int, c byte) bool {
+	return i < len(l.src) && l.src[i] == c
+}
+
+func (l *lexer) isDigitAt(i int) bool {
+	return i < len(l.src) && isDigit(l.src[i])
+}
+
+func (l *lexer) number(p pos) (token, error) {
+	n := 0
+	if c := l.src[l.off]; c == '+' || c == '-' {
+		n++
+	}
+	kind := tokInt
+	if bytes.HasPrefix(l.src[l.off+n:], []byte("0x")) || bytes.HasPrefix(l.src[l.off+n:], []byte("0X")) {
+		n += 2
+		for l.off+n < len(l.src) && isHexDigit(l.src[l.off+n]) {
+			n++
+		}
+	} else {
+		for l.off+n < len(l.src) {
+			c := l.src[l.off+n]
+			if isDigit(c) {
+				n++
+			} else if c == '.' || c == 'e' || c == 'E' {
+				kind = tokDouble
+				n++
+				if c != '.' && l.off+n < len(l.src) && (l.src[l.off+n] == '+' || l.src[l.off+n] == '-') {
+					n++
+				}
+			} else {
+				break
+			}
+		}
+	}
+	if l.off+n < len(l.src) && isIdentPart(l.src[l.off+n]) {
+		return token{}, l.errorf(p, "invalid number %q", string(l.src[l.off:l.off+n+1]))
+	}
+	s := string(l.src[l.off : l.off+n])
+	l.advance(n)
+	return token{kind: kind, text: s, pos: p}, nil
+}
+
+// literal reads a string literal quoted by q, escape sequences \\, \", \', \n, \r, \t and \0 are supported.
+func (l *lexer) literal(p pos, q byte) (token, error) {
+	var sb strings.Builder
+	i := l.off + 1
+	for ; i < len(l.src) && l.src[i] != q; i++ {
+		c := l.src[i]
+		if c != '\\' || i+1 >= len(l.src) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = l.src[i]; c {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '0':
+			sb.WriteByte(0)
+		case '\\', '"', '\'':
+			sb.WriteByte(c)
+		default:
+			l.advance(i - 1 - l.off)
+			return token{}, l.errorf(l.pos, "unknown escape sequence \\%c", c)
+		}
+	}
+	if i >= len(l.src) {
+		return token{}, l.errorf(p, "string literal not terminated")
+	}
+	l.advance(i + 1 - l.off)
+	return token{kind: tokString, text: sb.String(), pos: p}, nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentPart reports whether c can be a part of an identifier,
+// dots are included for references like `include_name.TypeName` and `Enum.VALUE`.
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '.'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,573 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package idl
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/frugal/schema"
+)
+
+// typeRef is a type in IDL which is not resolved yet.
+type typeRef struct {
+	kind      schema.Kind // zero if it refers to a named type
+	name      string      // the named type, maybe prefixed by an include name
+	key, elem *typeRef
+	pos       pos
+}
+
+type constKind uint8
+
+const (
+	constInt constKind = iota
+	constDouble
+	constString
+	constIdent
+	constList
+	constMap
+)
+
+// constValue is a const value in IDL which is not evaluated yet.
+type constValue struct {
+	kind       constKind
+	text       string
+	list       []*constValue // for constList
+	keys, vals []*constValue // for constMap
+	pos        pos
+}
+
+type fieldDecl struct {
+	id    int16
+	hasID bool
+	name  string
+	req   schema.Requiredness
+	typ   *typeRef
+	def   *constValue // nil if no default value
+	pos   pos
+}
+
+type structDecl struct {
+	name      string
+	fields    []*fieldDecl
+	union     bool
+	exception bool
+	pos       pos
+}
+
+type enumDecl struct {
+	name   string
+	values []schema.EnumValue
+	pos    pos
+}
+
+type typedefDecl struct {
+	name string
+	typ  *typeRef
+	pos  pos
+}
+
+type constDecl struct {
+	name string
+	typ  *typeRef
+	val  *constValue
+	pos  pos
+}
+
+type includeDecl struct {
+	path  string
+	alias string // the name to refer to the file, empty for the file name
+	pos   pos
+}
+
+// document is the syntax tree of an IDL file.
+type document struct {
+	includes   []includeDecl
+	namespaces map[string]string
+	structs    []*structDecl
+	enums      []*enumDecl
+	typedefs   []*typedefDecl
+	consts     []*constDecl
+}
+
+var baseTypes = map[string]schema.Kind{
+	"bool":   schema.BOOL,
+	"byte":   schema.BYTE,
+	"i8":     schema.BYTE,
+	"i16":    schema.I16,
+	"i32":    schema.I32,
+	"i64":    schema.I64,
+	"double": schema.DOUBLE,
+	"string": schema.STRING,
+	"binary": schema.BINARY,
+	"uuid":   schema.UUID,
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func parse(path string, src []byte) (*document, error) {
+	p := &parser{lex: newLexer(path, src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	doc := &document{namespaces: map[string]string{}}
+	for p.tok.kind != tokEOF {
+		if err := p.parseDefinition(doc); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return p.lex.errorf(p.tok.pos, format, args...)
+}
+
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lex.next()
+	return err
+}
+
+func (p *parser) is(kind tokenKind, text string) bool {
+	return p.tok.kind == kind && p.tok.text == text
+}
+
+// accept consumes the current token if it's the given punctuation.
+func (p *parser) accept(punct string) (bool, error) {
+	if !p.is(tokPunct, punct) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(tokPunct, punct) {
+		return p.errorf("expect %q, got %s", punct, p.tok)
+	}
+	return p.advance()
+}
+
+func (p *parser) expectKind(kind tokenKind, what string) (token, error) {
+	t := p.tok
+	if t.kind != kind {
+		return t, p.errorf("expect %s, got %s", what, t)
+	}
+	return t, p.advance()
+}
+
+// skipSeparator skips an optional list separator.
+func (p *parser) skipSeparator() error {
+	if p.is(tokPunct, ",") || p.is(tokPunct, ";") {
+		return p.advance()
+	}
+	return nil
+}
+
+// skipAnnotations skips annotations like `(go.tag = "json:\"x\"", cpp.type = "T")` if any.
+func (p *parser) skipAnnotations() error {
+	if !p.is(tokPunct, "(") {
+		return nil
+	}
+	depth := 0
+	for {
+		switch {
+		case p.tok.kind == tokEOF:
+			return p.errorf("annotations not terminated")
+		case p.is(tokPunct, "("):
+			depth++
+		case p.is(tokPunct, ")"):
+			depth--
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseDefinition(doc *document) error {
+	t, err := p.expectKind(tokIdent, "definition")
+	if err != nil {
+		return err
+	}
+	switch t.text {
+	case "include":
+		s, err := p.expectKind(tokString, "include path")
+		if err != nil {
+			return err
+		}
+		inc := includeDecl{path: s.text, pos: s.pos}
+		if p.is(tokIdent, "as") { // include "path" as alias
+			if err = p.advance(); err != nil {
+				return err
+			}
+			a, err := p.expectKind(tokIdent, "include alias")
+			if err != nil {
+				return err
+			}
+			if strings.IndexByte(a.text, '.') >= 0 {
+				return p.lex.errorf(a.pos, "invalid include alias %q", a.text)
+			}
+			inc.alias = a.text
+		}
+		doc.includes = append(doc.includes, inc)
+
+	case "cpp_include":
+		if _, err := p.expectKind(tokString, "include path"); err != nil {
+			return err
+		}
+
+	case "namespace":
+		scope, err := p.expectKind(tokIdent, "namespace scope")
+		if err != nil {
+			return err
+		}
+		ns, err := p.expectKind(tokIdent, "namespace")
+		if err != nil {
+			return err
+		}
+		doc.namespaces[scope.text] = ns.text
+
+	case "typedef":
+		typ, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		name, err := p.expectKind(tokIdent, "typedef name")
+		if err != nil {
+			return err
+		}
+		doc.typedefs = append(doc.typedefs, &typedefDecl{name: name.text, typ: typ, pos: name.pos})
+
+	case "const":
+		typ, err := p.parseType()
+		if err != nil {
+			return err
+		}
+		name, err := p.expectKind(tokIdent, "const name")
+		if err != nil {
+			return err
+		}
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		val, err := p.parseConstValue()
+		if err != nil {
+			return err
+		}
+		doc.consts = append(doc.consts, &constDecl{name: name.text, typ: typ, val: val, pos: name.pos})
+
+	case "enum":
+		e, err := p.parseEnum()
+		if err != nil {
+			return err
+		}
+		doc.enums = append(doc.enums, e)
+
+	case "struct", "union", "exception":
+		s, err := p.parseStruct()
+		if err != nil {
+			return err
+		}
+		s.union = t.text == "union"
+		s.exception = t.text == "exception"
+		doc.structs = append(doc.structs, s)
+
+	case "service":
+		if err := p.skipService(); err != nil {
+			return err
+		}
+
+	default:
+		return p.lex.errorf(t.pos, "unexpected %s", t)
+	}
+	if err := p.skipAnnotations(); err != nil {
+		return err
+	}
+	return p.skipSeparator()
+}
+
+func (p *parser) parseType() (*typeRef, error) {
+	t, err := p.expectKind(tokIdent, "type")
+	if err != nil {
+		return nil, err
+	}
+	ret := &typeRef{pos: t.pos}
+	switch t.text {
+	case "map":
+		ret.kind = schema.MAP
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		if ret.key, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if ret.elem, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+	case "list", "set":
+		ret.kind = schema.LIST
+		if t.text == "set" {
+			ret.kind = schema.SET
+		}
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		if ret.elem, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+	default:
+		if k, ok := baseTypes[t.text]; ok {
+			ret.kind = k
+		} else {
+			ret.name = t.text
+		}
+	}
+	return ret, p.skipAnnotations()
+}
+
+func (p *parser) parseConstValue() (*constValue, error) {
+	t := p.tok
+	v := &constValue{text: t.text, pos: t.pos}
+	switch t.kind {
+	case tokInt:
+		v.kind = constInt
+	case tokDouble:
+		v.kind = constDouble
+	case tokString:
+		v.kind = constString
+	case tokIdent:
+		v.kind = constIdent
+	case tokPunct:
+		switch t.text {
+		case "[":
+			v.kind = constList
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			for !p.is(tokPunct, "]") {
+				x, err := p.parseConstValue()
+				if err != nil {
+					return nil, err
+				}
+				v.list = append(v.list, x)
+				if err := p.skipSeparator(); err != nil {
+					return nil, err
+				}
+			}
+			return v, p.advance()
+
+		case "{":
+			v.kind = constMap
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			for !p.is(tokPunct, "}") {
+				k, err := p.parseConstValue()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				x, err := p.parseConstValue()
+				if err != nil {
+					return nil, err
+				}
+				v.keys = append(v.keys, k)
+				v.vals = append(v.vals, x)
+				if err := p.skipSeparator(); err != nil {
+					return nil, err
+				}
+			}
+			return v, p.advance()
+		}
+		fallthrough
+	default:
+		return nil, p.errorf("expect const value, got %s", t)
+	}
+	return v, p.advance()
+}
+
+func (p *parser) parseEnum() (*enumDecl, error) {
+	name, err := p.expectKind(tokIdent, "enum name")
+	if err != nil {
+		return nil, err
+	}
+	e := &enumDecl{name: name.text, pos: name.pos}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	next := int64(0)
+	for !p.is(tokPunct, "}") {
+		n, err := p.expectKind(tokIdent, "enum value name")
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := p.accept("="); err != nil {
+			return nil, err
+		} else if ok {
+			t, err := p.expectKind(tokInt, "enum value")
+			if err != nil {
+				return nil, err
+			}
+			if next, err = strconv.ParseInt(t.text, 0, 32); err != nil {
+				return nil, p.lex.errorf(t.pos, "invalid enum value %s", t.text)
+			}
+		}
+		if next > math.MaxInt32 {
+			return nil, p.lex.errorf(n.pos, "enum value %s overflows i32", n.text)
+		}
+		e.values = append(e.values, schema.EnumValue{Name: n.text, Value: int32(next)})
+		next++
+		if err := p.skipAnnotations(); err != nil {
+			return nil, err
+		}
+		if err := p.skipSeparator(); err != nil {
+			return nil, err
+		}
+	}
+	return e, p.advance()
+}
+
+func (p *parser) parseStruct() (*structDecl, error) {
+	name, err := p.expectKind(tokIdent, "struct name")
+	if err != nil {
+		return nil, err
+	}
+	s := &structDecl{name: name.text, pos: name.pos}
+	if p.is(tokIdent, "xsd_all") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	autoID := int16(0) // fields without ids get negative ids like the Apache compiler does
+	for !p.is(tokPunct, "}") {
+		f, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		if !f.hasID {
+			autoID--
+			f.id = autoID
+		}
+		s.fields = append(s.fields, f)
+	}
+	return s, p.advance()
+}
+
+func (p *parser) parseField() (*fieldDecl, error) {
+	f := &fieldDecl{}
+	if p.tok.kind == tokInt {
+		t := p.tok
+		id, err := strconv.ParseInt(t.text, 0, 16)
+		if err != nil {
+			return nil, p.lex.errorf(t.pos, "invalid field id %s", t.text)
+		}
+		f.id, f.hasID = int16(id), true
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case p.is(tokIdent, "required"):
+		f.req = schema.Required
+	case p.is(tokIdent, "optional"):
+		f.req = schema.Optional
+	}
+	if f.req != schema.Default {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if f.typ, err = p.parseType(); err != nil {
+		return nil, err
+	}
+	name, err := p.expectKind(tokIdent, "field name")
+	if err != nil {
+		return nil, err
+	}
+	f.name, f.pos = name.text, name.pos
+	if ok, err := p.accept("="); err != nil {
+		return nil, err
+	} else if ok {
+		if f.def, err = p.parseConstValue(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.skipAnnotations(); err != nil {
+		return nil, err
+	}
+	return f, p.skipSeparator()
+}
+
+// skipService skips a service definition, services are not part of schemas.
+func (p *parser) skipService() error {
+	if _, err := p.expectKind(tokIdent, "service name"); err != nil {
+		return err
+	}
+	if p.is(tokIdent, "extends") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if _, err := p.expectKind(tokIdent, "service name"); err != nil {
+			return err
+		}
+	}
+	if !p.is(tokPunct, "{") {
+		return p.errorf("expect %q, got %s", "{", p.tok)
+	}
+	depth := 0
+	for {
+		switch {
+		case p.tok.kind == tokEOF:
+			return p.errorf("service not terminated")
+		case p.is(tokPunct, "{"):
+			depth++
+		case p.is(tokPunct, "}"):
+			depth--
+		}
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,275 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package idl
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/cloudwego/frugal/schema"
+)
+
+// Format returns the IDL document of the structs and all the structs and enums they refer to.
+//
+// Structs are declared before they're used unless there are circular references.
+// Enums without values are declared as i32, since they can't be declared as empty enums.
+func Format(structs ...*schema.Struct) ([]byte, error) {
+	p := &printer{
+		names:   map[string]interface{}{},
+		visited: map[*schema.Struct]bool{},
+	}
+	for _, s := range structs {
+		if err := p.collect(s); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range p.enums {
+		p.printEnum(e)
+	}
+	for _, s := range p.structs {
+		if err := p.printStruct(s); err != nil {
+			return nil, err
+		}
+	}
+	return p.buf.Bytes(), nil
+}
+
+type printer struct {
+	buf bytes.Buffer
+
+	names   map[string]interface{} // declared names, for detecting conflicts
+	visited map[*schema.Struct]bool
+	structs []*schema.Struct // in the order of declaration
+	enums   []*schema.Enum
+}
+
+// collect adds s and the types it refers to in depth-first order.
+func (p *printer) collect(s *schema.Struct) error {
+	if p.visited[s] {
+		return nil
+	}
+	p.visited[s] = true
+	if err := p.declare(s.Name, s); err != nil {
+		return err
+	}
+	for _, f := range s.Fields {
+		if err := p.collectType(f.Type); err != nil {
+			return err
+		}
+	}
+	p.structs = append(p.structs, s)
+	return nil
+}
+
+func (p *printer) collectType(t *schema.Type) error {
+	switch t.Kind {
+	case schema.STRUCT:
+		return p.collect(t.Struct)
+	case schema.ENUM:
+		if t.Enum == nil || len(t.Enum.Values) == 0 || p.names[t.Enum.Name] == t.Enum {
+			return nil
+		}
+		if err := p.declare(t.Enum.Name, t.Enum); err != nil {
+			return err
+		}
+		p.enums = append(p.enums, t.Enum)
+	case schema.MAP:
+		if err := p.collectType(t.Key); err != nil {
+			return err
+		}
+		return p.collectType(t.Elem)
+	case schema.SET, schema.LIST:
+		return p.collectType(t.Elem)
+	}
+	return nil
+}
+
+func (p *printer) declare(name string, v interface{}) error {
+	if name == "" {
+		return fmt.Errorf("anonymous struct or enum is not supported")
+	}
+	if x, ok := p.names[name]; ok && x != v {
+		return fmt.Errorf("conflicting declarations of %s", name)
+	}
+	p.names[name] = v
+	return nil
+}
+
+func (p *printer) printEnum(e *schema.Enum) {
+	fmt.Fprintf(&p.buf, "enum %s {\n", e.Name)
+	for _, v := range e.Values {
+		fmt.Fprintf(&p.buf, "  %s = %d\n", v.Name, v.Value)
+	}
+	p.buf.WriteString("}\n\n")
+}
+
+func (p *printer) printStruct(s *schema.Struct) error {
+	kw := "struct"
+	if s.Union {
+		kw = "union"
+	} else if s.Exception {
+		kw = "exception"
+	}
+	fmt.Fprintf(&p.buf, "%s %s {\n", kw, s.Name)
+	for _, f := range s.Fields {
+		fmt.Fprintf(&p.buf, "  %d: ", f.ID)
+		if f.Requiredness != schema.Default {
+			p.buf.WriteString(f.Requiredness.String() + " ")
+		}
+		p.buf.WriteString(typeName(f.Type) + " " + f.Name)
+		if f.Default != nil {
+			p.buf.WriteString(" = ")
+			if err := p.printValue(f.Type, f.Default); err != nil {
+				return fmt.Errorf("default value of %s.%s: %w", s.Name, f.Name, err)
+			}
+		}
+		p.buf.WriteByte('\n')
+	}
+	p.buf.WriteString("}\n\n")
+	return nil
+}
+
+func typeName(t *schema.Type) string {
+	switch t.Kind {
+	case schema.MAP:
+		return "map<" + typeName(t.Key) + ", " + typeName(t.Elem) + ">"
+	case schema.SET, schema.LIST:
+		return t.Kind.String() + "<" + typeName(t.Elem) + ">"
+	case schema.ENUM:
+		if t.Enum == nil || len(t.Enum.Values) == 0 {
+			return "i32"
+		}
+	}
+	return t.String()
+}
+
+// printValue prints the const value v of the dynamic value model.
+func (p *printer) printValue(t *schema.Type, v interface{}) error {
+	switch x := v.(type) {
+	case bool:
+		p.buf.WriteString(strconv.FormatBool(x))
+	case int8, int16, int32, int64:
+		if t.Kind == schema.ENUM && t.Enum != nil {
+			for _, ev := range t.Enum.Values {
+				if i, _ := toInt(v); i == int64(ev.Value) {
+					p.buf.WriteString(t.Enum.Name + "." + ev.Name)
+					return nil
+				}
+			}
+		}
+		fmt.Fprintf(&p.buf, "%d", v)
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return fmt.Errorf("%v can't be represented in IDL", x)
+		}
+		p.buf.WriteString(strconv.FormatFloat(x, 'g', -1, 64))
+	case string:
+		p.printString(x)
+	case []byte:
+		p.printString(string(x))
+	case [16]byte:
+		p.printString(formatUUID(x))
+
+	case []interface{}:
+		p.buf.WriteByte('[')
+		for i, e := range x {
+			if i != 0 {
+				p.buf.WriteString(", ")
+			}
+			if err := p.printValue(t.Elem, e); err != nil {
+				return err
+			}
+		}
+		p.buf.WriteByte(']')
+
+	case map[interface{}]interface{}:
+		// keys are sorted for stable output
+		keys := make([]string, 0, len(x))
+		vals := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			sub := &printer{}
+			if err := sub.printValue(t.Key, k); err != nil {
+				return err
+			}
+			ks := sub.buf.String()
+			keys = append(keys, ks)
+			vals[ks] = e
+		}
+		sort.Strings(keys)
+		p.buf.WriteByte('{')
+		for i, k := range keys {
+			if i != 0 {
+				p.buf.WriteString(", ")
+			}
+			p.buf.WriteString(k + ": ")
+			if err := p.printValue(t.Elem, vals[k]); err != nil {
+				return err
+			}
+		}
+		p.buf.WriteByte('}')
+
+	case map[string]interface{}:
+		p.buf.WriteByte('{')
+		n := 0
+		for _, f := range t.Struct.Fields {
+			e, ok := x[f.Name]
+			if !ok || e == nil {
+				continue
+			}
+			if n != 0 {
+				p.buf.WriteString(", ")
+			}
+			n++
+			p.printString(f.Name)
+			p.buf.WriteString(": ")
+			if err := p.printValue(f.Type, e); err != nil {
+				return err
+			}
+		}
+		p.buf.WriteByte('}')
+
+	default:
+		return fmt.Errorf("unsupported value %v (%T)", v, v)
+	}
+	return nil
+}
+
+// printString prints s as a string literal, only the escape sequences supported by the lexer are used.
+func (p *printer) printString(s string) {
+	p.buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			p.buf.WriteByte('\\')
+			p.buf.WriteByte(c)
+		case '\n':
+			p.buf.WriteString(`\n`)
+		case '\r':
+			p.buf.WriteString(`\r`)
+		case '\t':
+			p.buf.WriteString(`\t`)
+		case 0:
+			p.buf.WriteString(`\0`)
+		default:
+			p.buf.WriteByte(c)
+		}
+	}
+	p.buf.WriteByte('"')
+}
@@ -0,0 +1,497 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package idl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/frugal/schema"
+)
+
+// resolver resolves names in a document and builds the File.
+// Names can be used before they're declared, so typedefs and consts are resolved on demand.
+type resolver struct {
+	f   *File
+	doc *document
+
+	structs  map[string]*schema.Struct
+	enums    map[string]*schema.Enum
+	typedefs map[string]*typedefDecl
+	consts   map[string]*constDecl
+
+	resolving map[string]bool // typedefs and consts being resolved, for detecting cycles
+}
+
+func newResolver(f *File, doc *document) *resolver {
+	return &resolver{
+		f:         f,
+		doc:       doc,
+		structs:   map[string]*schema.Struct{},
+		enums:     map[string]*schema.Enum{},
+		typedefs:  map[string]*typedefDecl{},
+		consts:    map[string]*constDecl{},
+		resolving: map[string]bool{},
+	}
+}
+
+func (r *resolver) errorf(p pos, format string, args ...interface{}) error {
+	return fmt.Errorf("%s:%d:%d: %s", r.f.Path, p.line, p.col, fmt.Sprintf(format, args...))
+}
+
+func (r *resolver) resolve() error {
+	names := map[string]bool{}
+	declare := func(name string, p pos) error {
+		if strings.IndexByte(name, '.') >= 0 {
+			return r.errorf(p, "invalid name %q", name)
+		}
+		if names[name] {
+			return r.errorf(p, "%s redeclared", name)
+		}
+		names[name] = true
+		return nil
+	}
+	for _, d := range r.doc.structs {
+		if err := declare(d.name, d.pos); err != nil {
+			return err
+		}
+		s := &schema.Struct{Name: d.name, Union: d.union, Exception: d.exception}
+		r.f.Structs = append(r.f.Structs, s)
+		r.structs[d.name] = s
+	}
+	for _, d := range r.doc.enums {
+		if err := declare(d.name, d.pos); err != nil {
+			return err
+		}
+		e := &schema.Enum{Name: d.name, Values: d.values}
+		r.f.Enums = append(r.f.Enums, e)
+		r.enums[d.name] = e
+	}
+	for _, d := range r.doc.typedefs {
+		if err := declare(d.name, d.pos); err != nil {
+			return err
+		}
+		r.typedefs[d.name] = d
+	}
+	for _, d := range r.doc.consts {
+		if err := declare(d.name, d.pos); err != nil {
+			return err
+		}
+		r.consts[d.name] = d
+	}
+
+	for _, d := range r.doc.typedefs {
+		if _, err := r.typedef(d); err != nil {
+			return err
+		}
+	}
+	for i, d := range r.doc.structs {
+		s := r.f.Structs[i]
+		for _, fd := range d.fields {
+			t, err := r.resolveType(fd.typ)
+			if err != nil {
+				return err
+			}
+			s.Fields = append(s.Fields, &schema.Field{ID: fd.id, Name: fd.name, Type: t, Requiredness: fd.req})
+		}
+	}
+	for i, s := range r.f.Structs {
+		if err := s.Validate(); err != nil {
+			return r.errorf(r.doc.structs[i].pos, "%s", err)
+		}
+	}
+
+	// values are evaluated after all struct fields are resolved
+	for _, d := range r.doc.consts {
+		if _, err := r.constant(d); err != nil {
+			return err
+		}
+	}
+	for i, d := range r.doc.structs {
+		s := r.f.Structs[i]
+		for j, fd := range d.fields {
+			if fd.def == nil {
+				continue
+			}
+			f := s.Fields[j]
+			v, err := r.value(f.Type, fd.def)
+			if err != nil {
+				return err
+			}
+			f.Default = v
+		}
+	}
+	return nil
+}
+
+func (r *resolver) typedef(d *typedefDecl) (*schema.Type, error) {
+	if t := r.f.Typedefs[d.name]; t != nil {
+		return t, nil
+	}
+	key := "typedef " + d.name
+	if r.resolving[key] {
+		return nil, r.errorf(d.pos, "circular typedef %s", d.name)
+	}
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+	t, err := r.resolveType(d.typ)
+	if err != nil {
+		return nil, err
+	}
+	r.f.Typedefs[d.name] = t
+	return t, nil
+}
+
+func (r *resolver) constant(d *constDecl) (*Const, error) {
+	if c := r.f.Consts[d.name]; c != nil {
+		return c, nil
+	}
+	key := "const " + d.name
+	if r.resolving[key] {
+		return nil, r.errorf(d.pos, "circular const %s", d.name)
+	}
+	r.resolving[key] = true
+	defer delete(r.resolving, key)
+	t, err := r.resolveType(d.typ)
+	if err != nil {
+		return nil, err
+	}
+	v, err := r.value(t, d.val)
+	if err != nil {
+		return nil, err
+	}
+	c := &Const{Name: d.name, Type: t, Value: v}
+	r.f.Consts[d.name] = c
+	return c, nil
+}
+
+func (r *resolver) resolveType(ref *typeRef) (*schema.Type, error) {
+	if ref.name == "" {
+		t := &schema.Type{Kind: ref.kind}
+		var err error
+		if ref.key != nil {
+			if t.Key, err = r.resolveType(ref.key); err != nil {
+				return nil, err
+			}
+		}
+		if ref.elem != nil {
+			if t.Elem, err = r.resolveType(ref.elem); err != nil {
+				return nil, err
+			}
+		}
+		return t, nil
+	}
+	if i := strings.IndexByte(ref.name, '.'); i >= 0 {
+		if g := r.f.Includes[ref.name[:i]]; g != nil {
+			n := ref.name[i+1:]
+			if s := g.Struct(n); s != nil {
+				return &schema.Type{Kind: schema.STRUCT, Struct: s}, nil
+			}
+			if e := g.Enum(n); e != nil {
+				return &schema.Type{Kind: schema.ENUM, Enum: e}, nil
+			}
+			if t := g.Typedefs[n]; t != nil {
+				return t, nil
+			}
+		}
+	} else {
+		if s := r.structs[ref.name]; s != nil {
+			return &schema.Type{Kind: schema.STRUCT, Struct: s}, nil
+		}
+		if e := r.enums[ref.name]; e != nil {
+			return &schema.Type{Kind: schema.ENUM, Enum: e}, nil
+		}
+		if d := r.typedefs[ref.name]; d != nil {
+			return r.typedef(d)
+		}
+	}
+	return nil, r.errorf(ref.pos, "undefined type %s", ref.name)
+}
+
+// value evaluates the const value as type t.
+func (r *resolver) value(t *schema.Type, cv *constValue) (interface{}, error) {
+	var v interface{}
+	switch cv.kind {
+	case constInt:
+		i, err := strconv.ParseInt(strings.TrimPrefix(cv.text, "+"), 0, 64)
+		if err != nil {
+			return nil, r.errorf(cv.pos, "invalid integer %s", cv.text)
+		}
+		v = i
+	case constDouble:
+		d, err := strconv.ParseFloat(cv.text, 64)
+		if err != nil {
+			return nil, r.errorf(cv.pos, "invalid double %s", cv.text)
+		}
+		v = d
+	case constString:
+		v = cv.text
+	case constIdent:
+		x, err := r.ident(cv)
+		if err != nil {
+			return nil, err
+		}
+		v = x
+
+	case constList:
+		if t.Kind != schema.LIST && t.Kind != schema.SET {
+			return nil, r.errorf(cv.pos, "cannot use list as %s", t)
+		}
+		ret := make([]interface{}, 0, len(cv.list))
+		for _, x := range cv.list {
+			e, err := r.value(t.Elem, x)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, e)
+		}
+		return ret, nil
+
+	case constMap:
+		switch t.Kind {
+		case schema.MAP:
+			ret := make(map[interface{}]interface{}, len(cv.keys))
+			for i := range cv.keys {
+				k, err := r.value(t.Key, cv.keys[i])
+				if err != nil {
+					return nil, err
+				}
+				if b, ok := k.([]byte); ok { // []byte is not hashable
+					k = string(b)
+				}
+				e, err := r.value(t.Elem, cv.vals[i])
+				if err != nil {
+					return nil, err
+				}
+				ret[k] = e
+			}
+			return ret, nil
+		case schema.STRUCT:
+			ret := make(map[string]interface{}, len(cv.keys))
+			for i, k := range cv.keys {
+				if k.kind != constString {
+					return nil, r.errorf(k.pos, "field name of %s must be a string literal", t)
+				}
+				f := t.Struct.FieldByName(k.text)
+				if f == nil {
+					return nil, r.errorf(k.pos, "unknown field %q of %s", k.text, t)
+				}
+				e, err := r.value(f.Type, cv.vals[i])
+				if err != nil {
+					return nil, err
+				}
+				ret[k.text] = e
+			}
+			return ret, nil
+		}
+		return nil, r.errorf(cv.pos, "cannot use map as %s", t)
+	}
+	ret, err := convert(t, v)
+	if err != nil {
+		return nil, r.errorf(cv.pos, "%s", err)
+	}
+	return ret, nil
+}
+
+// ident evaluates true, false, consts and enum values.
+func (r *resolver) ident(cv *constValue) (interface{}, error) {
+	name := cv.text
+	switch name {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if d := r.consts[name]; d != nil {
+		c, err := r.constant(d)
+		if err != nil {
+			return nil, err
+		}
+		return c.Value, nil
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		if g := r.f.Includes[name[:i]]; g != nil {
+			if c := g.Consts[name[i+1:]]; c != nil {
+				return c.Value, nil
+			}
+		}
+	}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		e := r.enums[name[:i]]
+		if e == nil {
+			e = r.f.Enum(name[:i])
+		}
+		if e != nil {
+			if v, ok := e.ValueByName(name[i+1:]); ok {
+				return v, nil
+			}
+		}
+	}
+	return nil, r.errorf(cv.pos, "undefined %s", name)
+}
+
+// convert converts a scalar or a value of a const to type t.
+func convert(t *schema.Type, v interface{}) (interface{}, error) {
+	switch t.Kind {
+	case schema.BOOL:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		if i, ok := toInt(v); ok && (i == 0 || i == 1) {
+			return i == 1, nil
+		}
+
+	case schema.BYTE, schema.I16, schema.I32, schema.I64, schema.ENUM:
+		i, ok := toInt(v)
+		if !ok {
+			break
+		}
+		switch t.Kind {
+		case schema.BYTE:
+			if i >= math.MinInt8 && i <= math.MaxInt8 {
+				return int8(i), nil
+			}
+		case schema.I16:
+			if i >= math.MinInt16 && i <= math.MaxInt16 {
+				return int16(i), nil
+			}
+		case schema.I32, schema.ENUM:
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return int32(i), nil
+			}
+		default:
+			return i, nil
+		}
+		return nil, fmt.Errorf("%d overflows %s", i, t)
+
+	case schema.DOUBLE:
+		if d, ok := v.(float64); ok {
+			return d, nil
+		}
+		if i, ok := toInt(v); ok {
+			return float64(i), nil
+		}
+
+	case schema.STRING:
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case []byte:
+			return string(x), nil
+		}
+
+	case schema.BINARY:
+		switch x := v.(type) {
+		case string:
+			return []byte(x), nil
+		case []byte:
+			return x, nil
+		}
+
+	case schema.UUID:
+		switch x := v.(type) {
+		case string:
+			if u, ok := parseUUID(x); ok {
+				return u, nil
+			}
+		case [16]byte:
+			return x, nil
+		}
+
+	case schema.LIST, schema.SET:
+		if x, ok := v.([]interface{}); ok {
+			ret := make([]interface{}, len(x))
+			for i, e := range x {
+				var err error
+				if ret[i], err = convert(t.Elem, e); err != nil {
+					return nil, err
+				}
+			}
+			return ret, nil
+		}
+
+	case schema.MAP:
+		if x, ok := v.(map[interface{}]interface{}); ok {
+			ret := make(map[interface{}]interface{}, len(x))
+			for k, e := range x {
+				k, err := convert(t.Key, k)
+				if err != nil {
+					return nil, err
+				}
+				if b, ok := k.([]byte); ok {
+					k = string(b)
+				}
+				if ret[k], err = convert(t.Elem, e); err != nil {
+					return nil, err
+				}
+			}
+			return ret, nil
+		}
+
+	case schema.STRUCT:
+		if x, ok := v.(map[string]interface{}); ok {
+			ret := make(map[string]interface{}, len(x))
+			for k, e := range x {
+				f := t.Struct.FieldByName(k)
+				if f == nil {
+					return nil, fmt.Errorf("unknown field %q of %s", k, t)
+				}
+				var err error
+				if ret[k], err = convert(f.Type, e); err != nil {
+					return nil, err
+				}
+			}
+			return ret, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot use %v (%T) as %s", v, v, t)
+}
+
+// parseUUID parses UUIDs like "00112233-4455-6677-8899-aabbccddeeff".
+func parseUUID(s string) (ret [16]byte, ok bool) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return ret, false
+	}
+	h := s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	if _, err := hex.Decode(ret[:], []byte(h)); err != nil {
+		return ret, false
+	}
+	return ret, true
+}
+
+// formatUUID is the reverse of parseUUID.
+func formatUUID(u [16]byte) string {
+	h := hex.EncodeToString(u[:])
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int8:
+		return int64(x), true
+	case int16:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	}
+	return 0, false
+}
@@ -0,0 +1,3 @@
+struct Common {
+  1: i32 A
+}
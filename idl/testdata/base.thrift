@@ -0,0 +1,18 @@
+namespace go base
+
+enum Status {
+  OK,
+  FAILED = 10,
+  UNKNOWN,
+}
+
+typedef map<string, string> Extra
+
+const i32 MaxSize = 0x100
+const Extra DefaultExtra = {"k": "v"}
+
+struct Base {
+  1: string LogID = ""
+  2: optional Extra Extra
+  3: Status Status = Status.OK
+}
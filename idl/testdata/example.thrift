@@ -0,0 +1,37 @@
+include "base.thrift"
+
+namespace go example
+
+/* Requests
+ * and responses. */
+typedef i64 UserID
+typedef list<User> Users
+
+const double Ratio = 1
+const list<base.Status> Statuses = [base.Status.OK, 11]
+
+struct User {
+  1: required UserID ID (go.tag = "json:\"id\"")
+  2: string Name = 'anonymous';
+  3: optional binary Avatar = "x"
+  4: bool Active = 1,
+  5: double Score = Ratio
+  6: set<i16> Tags = [1, 2]
+  7: map<i32, base.Status> Flags = {1: base.Status.FAILED}
+  8: base.Base Base = {"LogID": "x", "Extra": base.DefaultExtra}
+  9: optional User Parent
+}
+
+union Target {
+  1: UserID ID
+  2: string Name
+}
+
+exception NotFound {
+  1: string Msg
+} (annotation = "x")
+
+service UserService extends base.BaseService {
+  Users GetUsers(1: list<UserID> ids) throws (1: NotFound e)
+  oneway void Ping()
+}
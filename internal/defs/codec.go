@@ -0,0 +1,97 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package defs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/frugal/schema"
+)
+
+var (
+	codecsLock = new(sync.RWMutex)
+	codecs     = make(map[reflect.Type]Tag)
+)
+
+// RegisterCodec declares vt as a type with a custom codec, which is encoded as
+// the Thrift type wt. wt must be a scalar type other than bool, like T_i64 or T_binary.
+// Pointers to vt are resolved to pointers to T_codec types.
+//
+// It must be called before vt is used.
+func RegisterCodec(vt reflect.Type, wt Tag) error {
+	switch wt {
+	case T_i8, T_i16, T_i32, T_i64, T_double, T_string, T_binary:
+		break
+	default:
+		return EType(vt, "invalid wire type for custom codec: "+schema.Kind(wt).String())
+	}
+	if vt.Kind() == reflect.Ptr {
+		return EType(vt, "custom codec of pointer type is not allowed")
+	}
+	codecsLock.Lock()
+	defer codecsLock.Unlock()
+	if _, ok := codecs[vt]; ok {
+		return fmt.Errorf("custom codec of %s is already registered", vt)
+	}
+	codecs[vt] = wt
+	return nil
+}
+
+// codecTag returns the wire type of vt if it has a custom codec.
+func codecTag(vt reflect.Type) (Tag, bool) {
+	codecsLock.RLock()
+	defer codecsLock.RUnlock()
+	tag, ok := codecs[vt]
+	return tag, ok
+}
+
+// IsCodec returns true if the type has a custom codec, or it's a pointer to such a type.
+func (t *Type) IsCodec() bool {
+	switch t.T {
+	case T_codec:
+		return true
+	case T_pointer:
+		return t.V.IsCodec()
+	default:
+		return false
+	}
+}
+
+// codecWireTag returns the Thrift type of the custom codec, T_binary is kept as is.
+func (t *Type) codecWireTag() Tag {
+	tag, _ := codecTag(t.S)
+	return tag
+}
+
+func doParseCodec(vt reflect.Type, wt Tag, def string, i *int, ret *Type) (*Type, error) {
+	/* match the type if any */
+	if def != "" {
+		if tv, et := readToken(def, i, false); et != nil {
+			return nil, et
+		} else if !strings.Contains(keywordTab[wt], tv) {
+			return nil, mkMistyped(*i-len(tv), def, tv, wt, vt)
+		}
+	}
+
+	/* custom codecs are always scalars */
+	ret.S = vt
+	ret.T = T_codec
+	return ret, nil
+}
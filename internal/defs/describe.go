@@ -0,0 +1,270 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package defs
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/cloudwego/frugal/schema"
+)
+
+// Describe returns the schema of the struct type vt, which can also be a pointer to a struct.
+//
+// Field names are taken from "thrift" tags if any, otherwise they're names of the Go fields.
+// Default values are taken from the InitDefault method if it's implemented.
+func Describe(vt reflect.Type) (*schema.Struct, error) {
+	ss, err := DescribeTypes([]reflect.Type{vt})
+	if err != nil {
+		return nil, err
+	}
+	return ss[0], nil
+}
+
+// DescribeTypes is like Describe, but describes multiple types,
+// and the schemas of the same Go struct types are shared.
+func DescribeTypes(vts []reflect.Type) ([]*schema.Struct, error) {
+	d := &describer{
+		structs: make(map[reflect.Type]*schema.Struct),
+		enums:   make(map[reflect.Type]*schema.Enum),
+	}
+	ret := make([]*schema.Struct, 0, len(vts))
+	for _, vt := range vts {
+		if vt.Kind() == reflect.Ptr {
+			vt = vt.Elem()
+		}
+		if vt.Kind() != reflect.Struct {
+			return nil, EType(vt, "not a struct")
+		}
+		s, err := d.describe(vt)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, s)
+	}
+
+	// default values are converted after all structs are described,
+	// since values of struct types are converted by the fields of the structs
+	for vt, s := range d.structs {
+		fv, _ := ResolveFields(vt)
+		for i, f := range fv {
+			if !f.Default.IsValid() || !f.Defined {
+				continue
+			}
+			s.Fields[i].Default = d.value(f.Type, f.Default)
+		}
+	}
+	return ret, nil
+}
+
+type describer struct {
+	structs map[reflect.Type]*schema.Struct
+	enums   map[reflect.Type]*schema.Enum
+}
+
+func (d *describer) describe(vt reflect.Type) (*schema.Struct, error) {
+	if s := d.structs[vt]; s != nil {
+		return s, nil
+	}
+	fv, err := ResolveFields(vt)
+	if err != nil {
+		return nil, err
+	}
+	s := &schema.Struct{Name: vt.Name(), Union: IsUnion(vt), Fields: make([]*schema.Field, 0, len(fv))}
+	d.structs[vt] = s
+	for _, f := range fv {
+		t, err := d.describeType(f.Type)
+		if err != nil {
+			return nil, err
+		}
+		sf := vt.FieldByIndex(f.Index)
+		s.Fields = append(s.Fields, &schema.Field{
+			ID:           int16(f.ID),
+			Name:         thriftFieldName(sf),
+			Type:         t,
+			Requiredness: describeRequiredness(f.Spec),
+			GoName:       f.Name,
+			NoCopy:       f.Opts&NoCopy != 0,
+		})
+	}
+	return s, nil
+}
+
+func (d *describer) describeType(t *Type) (*schema.Type, error) {
+	var err error
+	switch t.T {
+	case T_pointer:
+		return d.describeType(t.V)
+	case T_struct:
+		ret := &schema.Type{Kind: schema.STRUCT}
+		ret.Struct, err = d.describe(t.S)
+		return ret, err
+	case T_enum:
+		e := d.enums[t.S]
+		if e == nil {
+			e = &schema.Enum{Name: t.S.Name()}
+			d.enums[t.S] = e
+		}
+		return &schema.Type{Kind: schema.ENUM, Enum: e}, nil
+	case T_map:
+		ret := &schema.Type{Kind: schema.MAP}
+		if ret.Key, err = d.describeType(t.K); err != nil {
+			return nil, err
+		}
+		ret.Elem, err = d.describeType(t.V)
+		return ret, err
+	case T_set, T_list:
+		ret := &schema.Type{Kind: schema.Kind(t.T)}
+		ret.Elem, err = d.describeType(t.V)
+		return ret, err
+	case T_codec:
+		return &schema.Type{Kind: schema.Kind(t.codecWireTag())}, nil
+	}
+	return &schema.Type{Kind: schema.Kind(t.T)}, nil
+}
+
+// value converts rv to the dynamic value model, it returns nil if it's not representable.
+func (d *describer) value(t *Type, rv reflect.Value) interface{} {
+	switch t.T {
+	case T_pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return d.value(t.V, rv.Elem())
+	case T_bool:
+		return rv.Bool()
+	case T_i8:
+		return int8(intValue(rv))
+	case T_i16:
+		return int16(intValue(rv))
+	case T_i32, T_enum:
+		return int32(intValue(rv))
+	case T_i64:
+		return intValue(rv)
+	case T_double:
+		return rv.Float()
+	case T_string:
+		return rv.String()
+	case T_uuid:
+		return rv.Convert(uuidtype).Interface()
+	case T_binary:
+		if rv.Kind() == reflect.Array {
+			ret := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(ret), rv)
+			return ret
+		}
+		return append([]byte{}, rv.Bytes()...)
+
+	case T_set, T_list:
+		if rv.Kind() == reflect.Map {
+			return d.mapSetValue(t, rv)
+		}
+		ret := make([]interface{}, rv.Len())
+		for i := range ret {
+			if ret[i] = d.value(t.V, rv.Index(i)); ret[i] == nil {
+				return nil
+			}
+		}
+		return ret
+
+	case T_map:
+		ret := make(map[interface{}]interface{}, rv.Len())
+		it := rv.MapRange()
+		for it.Next() {
+			k, v := d.value(t.K, it.Key()), d.value(t.V, it.Value())
+			if k == nil || v == nil {
+				return nil
+			}
+			if _, ok := k.(map[string]interface{}); ok { // structs are not hashable
+				return nil
+			}
+			ret[k] = v
+		}
+		return ret
+
+	case T_struct:
+		fv, _ := ResolveFields(t.S)
+		ret := make(map[string]interface{}, len(fv))
+		for i, f := range d.structs[t.S].Fields {
+			x, err := rv.FieldByIndexErr(fv[i].Index)
+			if err != nil || isNil(x) && (f.Requiredness == schema.Optional || x.Kind() == reflect.Ptr) {
+				continue // unset, or the embedded struct pointer is nil
+			}
+			v := d.value(fv[i].Type, x)
+			if v == nil {
+				return nil
+			}
+			ret[f.Name] = v
+		}
+		return ret
+	}
+	return nil
+}
+
+// mapSetValue converts keys of map[K]struct{} or map[K]bool to elements of the set,
+// keys of false values are not in the set.
+func (d *describer) mapSetValue(t *Type, rv reflect.Value) interface{} {
+	ret := make([]interface{}, 0, rv.Len())
+	it := rv.MapRange()
+	for it.Next() {
+		if v := it.Value(); v.Kind() == reflect.Bool && !v.Bool() {
+			continue
+		}
+		k := d.value(t.V, it.Key())
+		if k == nil {
+			return nil
+		}
+		ret = append(ret, k)
+	}
+	return ret
+}
+
+// intValue returns the integer, unsigned integers are converted bit by bit.
+func intValue(rv reflect.Value) int64 {
+	if isUnsignedKind(rv.Kind()) {
+		return int64(rv.Uint())
+	}
+	return rv.Int()
+}
+
+func isNil(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// thriftFieldName returns the field name in the "thrift" tag, or the name of the Go field.
+func thriftFieldName(sf reflect.StructField) string {
+	if s, ok := sf.Tag.Lookup("thrift"); ok {
+		if name := strings.TrimSpace(strings.Split(s, ",")[0]); name != "" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+func describeRequiredness(r Requiredness) schema.Requiredness {
+	switch r {
+	case Required:
+		return schema.Required
+	case Optional:
+		return schema.Optional
+	}
+	return schema.Default
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package defs
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/schema"
+)
+
+type describeEnum int64
+
+type DescribeNode struct {
+	Name     string                  `thrift:"name,1,required" frugal:"1,required,string,nocopy"`
+	Kind     describeEnum            `frugal:"2,default,describeEnum"`
+	Children []*DescribeNode         `frugal:"3,default,list<DescribeNode>"`
+	Attrs    map[string][]byte       `frugal:"4,optional,map<string:binary>"`
+	Parent   *DescribeNode           `frugal:"5,optional,DescribeNode"`
+	Tags     []int32                 `frugal:"6,optional,set<i32>"`
+	Count    *int16                  `frugal:"7,optional,i16"`
+	Extra    map[int64]*DescribeNode `frugal:"8,optional,map<i64:DescribeNode>"`
+}
+
+func (p *DescribeNode) InitDefault() {
+	p.Name = "root"
+	p.Kind = 2
+	p.Children = []*DescribeNode{{Name: "child"}}
+	p.Tags = []int32{1}
+}
+
+func TestDescribe(t *testing.T) {
+	s, err := Describe(reflect.TypeOf(&DescribeNode{}))
+	assert.Nil(t, err)
+	assert.Equal(t, "DescribeNode", s.Name)
+
+	type field struct {
+		ID     int16
+		Name   string
+		Type   string
+		Req    schema.Requiredness
+		GoName string
+		NoCopy bool
+	}
+	var fields []field
+	for _, f := range s.Fields {
+		fields = append(fields, field{f.ID, f.Name, f.Type.String(), f.Requiredness, f.GoName, f.NoCopy})
+	}
+	assert.DeepEqual(t, []field{
+		{1, "name", "string", schema.Required, "Name", true},
+		{2, "Kind", "describeEnum", schema.Default, "Kind", false},
+		{3, "Children", "list<DescribeNode>", schema.Default, "Children", false},
+		{4, "Attrs", "map<string,binary>", schema.Optional, "Attrs", false},
+		{5, "Parent", "DescribeNode", schema.Optional, "Parent", false},
+		{6, "Tags", "set<i32>", schema.Optional, "Tags", false},
+		{7, "Count", "i16", schema.Optional, "Count", false},
+		{8, "Extra", "map<i64,DescribeNode>", schema.Optional, "Extra", false},
+	}, fields)
+	assert.True(t, s.Fields[4].Type.Struct == s)
+	assert.Equal(t, schema.ENUM, s.Fields[1].Type.Kind)
+
+	assert.DeepEqual(t, "root", s.Fields[0].Default)
+	assert.DeepEqual(t, int32(2), s.Fields[1].Default)
+	assert.DeepEqual(t, []interface{}{map[string]interface{}{
+		"name": "child", "Kind": int32(0), "Children": []interface{}{},
+	}}, s.Fields[2].Default)
+	assert.DeepEqual(t, []interface{}{int32(1)}, s.Fields[5].Default)
+	assert.True(t, s.Fields[6].Default == nil)
+	assert.Nil(t, s.Validate())
+
+	// it's a new schema every call
+	s2, err := Describe(reflect.TypeOf(DescribeNode{}))
+	assert.Nil(t, err)
+	assert.True(t, s != s2)
+	assert.DeepEqual(t, s.Fields[2].Default, s2.Fields[2].Default)
+}
+
+type describeMapSet struct {
+	Tags map[string]bool `frugal:"1,default,set<string>"`
+}
+
+func (p *describeMapSet) InitDefault() {
+	p.Tags = map[string]bool{"a": true, "b": false}
+}
+
+func TestDescribe_MapSet(t *testing.T) {
+	s, err := Describe(reflect.TypeOf(describeMapSet{}))
+	assert.Nil(t, err)
+	assert.Equal(t, "set<string>", s.Fields[0].Type.String())
+	assert.DeepEqual(t, []interface{}{"a"}, s.Fields[0].Default) // keys of false values are skipped
+	assert.Nil(t, s.Validate())
+}
+
+type describeZeroDefaults struct {
+	I32  int32   `frugal:"1,default,i32"`
+	Bool bool    `frugal:"2,default,bool"`
+	Str  string  `frugal:"3,default,string"`
+	F64  float64 `frugal:"4,default,double"`
+	None int64   `frugal:"5,default,i64"`
+}
+
+func (p *describeZeroDefaults) InitDefault() {
+	p.I32 = 0
+	p.Bool = false
+	p.Str = ""
+	p.F64 = 0
+}
+
+func TestDescribe_ZeroDefaults(t *testing.T) {
+	s, err := Describe(reflect.TypeOf(describeZeroDefaults{}))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int32(0), s.Fields[0].Default)
+	assert.DeepEqual(t, false, s.Fields[1].Default)
+	assert.DeepEqual(t, "", s.Fields[2].Default)
+	assert.DeepEqual(t, float64(0), s.Fields[3].Default)
+	assert.True(t, s.Fields[4].Default == nil)
+	assert.Nil(t, s.Validate())
+}
+
+func TestDescribeErrors(t *testing.T) {
+	_, err := Describe(reflect.TypeOf(1))
+	assert.DeepEqual(t, EType(reflect.TypeOf(1), "not a struct"), err)
+
+	type Invalid struct {
+		A uint32 `frugal:"1,default"`
+	}
+	type Outer struct {
+		X *Invalid `frugal:"1,optional,Invalid"`
+	}
+	_, expect := ResolveFields(reflect.TypeOf(Invalid{}))
+	assert.True(t, expect != nil)
+	_, err = Describe(reflect.TypeOf(Outer{}))
+	assert.DeepEqual(t, expect, err)
+	_, err = Describe(reflect.TypeOf(DuplicateIDFields{}))
+	_, expect = ResolveFields(reflect.TypeOf(DuplicateIDFields{}))
+	assert.DeepEqual(t, expect, err)
+}
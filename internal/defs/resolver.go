@@ -33,6 +33,9 @@ type (
 
 const (
 	NoCopy Options = 1 << iota
+	Unsigned
+	RangeCheck
+	PrecisionCheck
 )
 
 const (
@@ -50,6 +53,19 @@ func (o Options) String() string {
 		ret = append(ret, "nocopy")
 	}
 
+	// check for "unsigned" and "rangecheck" options
+	if o&Unsigned != 0 {
+		ret = append(ret, "unsigned")
+	}
+	if o&RangeCheck != 0 {
+		ret = append(ret, "rangecheck")
+	}
+
+	// check for "precisioncheck" option
+	if o&PrecisionCheck != 0 {
+		ret = append(ret, "precisioncheck")
+	}
+
 	// join them together
 	return fmt.Sprintf(
 		"{%s}",
@@ -71,12 +87,22 @@ func (r Requiredness) String() string {
 }
 
 type Field struct {
-	F       int
+	F       int    // offset of the field, relative to the struct of the last Embeds if any
+	Name    string // name of the Go struct field
+	Index   []int  // index sequence for reflect.Type.FieldByIndex, longer than 1 for promoted fields
 	ID      uint16
 	Type    *Type
 	Opts    Options
 	Spec    Requiredness
 	Default reflect.Value
+	Defined bool    // Default is set by InitDefault, which may be a zero value
+	Embeds  []Embed // embedded struct pointers which promoted fields are accessed through
+}
+
+// Embed is an embedded pointer to struct.
+type Embed struct {
+	F int          // offset of the pointer, relative to the struct of the previous Embed if any
+	S reflect.Type // type of the struct
 }
 
 var (
@@ -112,6 +138,9 @@ func ResolveFields(vt reflect.Type) ([]Field, error) {
 	if fv, ex = DoResolveFields(vt); ex != nil {
 		return nil, ex
 	}
+	if len(fv) == 0 && IsUnion(vt) {
+		return nil, fmt.Errorf("union %s has no fields", vt)
+	}
 
 	// update cache
 	fieldsCache[vt] = fv
@@ -142,24 +171,122 @@ func trimSpaces(ss []string) []string {
 	return ss
 }
 
+var (
+	registryLock = new(sync.RWMutex)
+	registry     = make(map[reflect.Type]map[string]string)
+)
+
+// RegisterFields declares fields of vt with tags instead of struct tags,
+// tags maps names of the fields to values in the same syntax as "frugal" tags.
+// Fields of vt not in tags are ignored, and fields of embedded structs are promoted.
+//
+// It must be called before vt is used.
+func RegisterFields(vt reflect.Type, tags map[string]string) error {
+	if vt.Kind() != reflect.Struct {
+		return EType(vt, "not a struct")
+	}
+	for name := range tags {
+		sf, ok := vt.FieldByName(name)
+		if !ok || len(sf.Index) != 1 {
+			return fmt.Errorf("no field %s in %s", name, vt)
+		}
+		if sf.Anonymous || sf.PkgPath != "" {
+			return fmt.Errorf("field %s.%s is embedded or not exported", vt, name)
+		}
+	}
+
+	fieldsLock.RLock()
+	_, used := fieldsCache[vt]
+	fieldsLock.RUnlock()
+	if used {
+		return fmt.Errorf("%s is already in use", vt)
+	}
+
+	registryLock.Lock()
+	if _, ok := registry[vt]; ok {
+		registryLock.Unlock()
+		return fmt.Errorf("%s is already registered", vt)
+	}
+	registry[vt] = tags
+	registryLock.Unlock()
+
+	// validate the fields like tags
+	if _, err := DoResolveFields(vt); err != nil {
+		registryLock.Lock()
+		delete(registry, vt)
+		registryLock.Unlock()
+		return err
+	}
+	return nil
+}
+
+func registeredFields(vt reflect.Type) map[string]string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	return registry[vt]
+}
+
 // DoResolveFields ... no cache, use ResolveFields instead.
 // it's only used by reflect pkg for less objects,
 // coz reflect pkg has its own cache.
+//
+// Fields of embedded structs without tags are promoted like encoding/json,
+// including embedded pointers to structs.
 func DoResolveFields(vt reflect.Type) ([]Field, error) {
-	var err error
-	var ret []Field
-	var mem reflect.Value
-
-	// field ID map and default values
-	val := reflect.New(vt)
-	ids := make(map[uint64]struct{}, vt.NumField())
+	var mem, set reflect.Value
 
 	// check for default values
+	val := reflect.New(vt)
 	if def, ok := val.Interface().(DefaultInitializer); ok {
 		mem = val.Elem()
 		def.InitDefault()
+
+		// zero default values like `= 0` are found by
+		// calling InitDefault with the fields set to non-zero values
+		set = reflect.New(vt)
+		setNonZero(set.Elem())
+		set.Interface().(DefaultInitializer).InitDefault()
+		set = set.Elem()
+	}
+
+	// traverse all the fields, including the promoted ones
+	r := &fieldResolver{
+		mem:  mem,
+		set:  set,
+		ids:  make(map[uint64]string, vt.NumField()),
+		path: map[reflect.Type]bool{vt: true},
+	}
+	if err := r.resolve(vt, nil, 0, nil); err != nil {
+		return nil, err
 	}
 
+	// sort the field by ID
+	ret := r.ret
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+	if IsUnion(vt) {
+		if err := checkUnionFields(vt, ret); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+type fieldResolver struct {
+	mem  reflect.Value         // for default values if valid
+	set  reflect.Value         // like mem, but the fields are set to non-zero values before InitDefault
+	ids  map[uint64]string     // field IDs to names of the fields for checking duplicates
+	path map[reflect.Type]bool // types of embedded structs being resolved for breaking cycles
+	ret  []Field
+}
+
+// resolve resolves fields of vt, which is embedded in the outermost struct with index and offset,
+// through the embedded pointers if any.
+func (r *fieldResolver) resolve(vt reflect.Type, index []int, offset int, embeds []Embed) error {
+	var err error
+
+	// fields of vt
+	tags := registeredFields(vt)
+
 	// traverse all the fields
 	for i := 0; i < vt.NumField(); i++ {
 		var ok bool
@@ -172,33 +299,50 @@ func DoResolveFields(vt reflect.Type) ([]Field, error) {
 		var rv reflect.Value
 		var sf reflect.StructField
 
-		// extract the field, ignore anonymous or private fields
-		if sf = vt.Field(i); sf.Anonymous || sf.PkgPath != "" {
+		// extract the field, promote fields of embedded structs without tags,
+		// embedded fields of registered types are always promoted since they can't be registered
+		sf = vt.Field(i)
+		sf.Index = append(index[:len(index):len(index)], i)
+		if sf.Anonymous && (tags != nil || !hasStructTag(sf.Tag)) {
+			if err = r.resolveEmbedded(sf, offset, embeds); err != nil {
+				return err
+			}
 			continue
 		}
 
-		// ignore fields that does not declare the "frugal" tag
-		if ft, ok = lookupStructTag(sf.Tag); !ok {
+		// ignore private fields
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		// ignore fields that does not declare the "frugal" tag,
+		// or are not registered if vt is registered by RegisterFields
+		if tags != nil {
+			var tag string
+			if tag, ok = tags[sf.Name]; !ok {
+				continue
+			}
+			ft = trimSpaces(strings.Split(tag, ","))
+		} else if ft, ok = lookupStructTag(sf.Tag); !ok {
 			continue
 		}
 
 		// must have at least 1 field: ID
 		if len(ft) == 0 {
-			return nil, fmt.Errorf("invalid tag for field %s.%s", vt, sf.Name)
+			return fmt.Errorf("invalid tag for field %s.%s", vt, sf.Name)
 		}
 
 		// parse the field ID
 		if id, err = strconv.ParseUint(ft[0], 10, 16); err != nil {
-			return nil, fmt.Errorf("invalid field number for field %s.%s: %w", vt, sf.Name, err)
+			return fmt.Errorf("invalid field number for field %s.%s: %w", vt, sf.Name, err)
 		}
 		ft = ft[1:]
 
-		// check for duplicates
-		if _, ok = ids[id]; !ok {
-			ids[id] = struct{}{}
-		} else {
-			return nil, fmt.Errorf("duplicated field ID %d for field %s.%s", id, vt, sf.Name)
+		// check for duplicates, including fields of embedded structs
+		if name, dup := r.ids[id]; dup {
+			return fmt.Errorf("duplicated field ID %d for field %s.%s, already used by %s", id, vt, sf.Name, name)
 		}
+		r.ids[id] = vt.String() + "." + sf.Name
 
 		// parse requiredness which is optional if only one field
 		if len(ft) == 0 {
@@ -214,7 +358,7 @@ func DoResolveFields(vt reflect.Type) ([]Field, error) {
 		case "optional":
 			rx = Optional
 		default:
-			return nil, fmt.Errorf("invalid requiredness for field %s.%s", vt, sf.Name)
+			return fmt.Errorf("invalid requiredness for field %s.%s", vt, sf.Name)
 		}
 
 		// types and other options are optional
@@ -225,13 +369,20 @@ func DoResolveFields(vt reflect.Type) ([]Field, error) {
 		} else {
 			tv, ft = ft[0], ft[1:]
 		}
-		if pt, err = ParseType(sf.Type, tv); err != nil {
-			return nil, fmt.Errorf("cannot parse type descriptor: %w", err)
+		// "unsigned" option changes how the type is parsed
+		parse := ParseType
+		for _, opt := range ft {
+			if opt == "unsigned" {
+				parse = ParseUnsignedType
+			}
+		}
+		if pt, err = parse(sf.Type, tv); err != nil {
+			return fmt.Errorf("cannot parse type descriptor: %w", err)
 		}
 
 		// only optional fields or structs can be pointers
 		if rx != Optional && pt.T == T_pointer && pt.V.T != T_struct {
-			return nil, fmt.Errorf("only optional fields or structs can be pointers, not %s: %s.%s", sf.Type, vt, sf.Name)
+			return fmt.Errorf("only optional fields or structs can be pointers, not %s: %s.%s", sf.Type, vt, sf.Name)
 		}
 
 		// scan for the options
@@ -239,40 +390,148 @@ func DoResolveFields(vt reflect.Type) ([]Field, error) {
 			switch opt {
 			default:
 				{
-					return nil, fmt.Errorf("invalid option: %s", opt)
+					return fmt.Errorf("invalid option: %s", opt)
 				}
 
 			// "nocopy" option enables zero-copy string decoding
 			case "nocopy":
 				{
-					if pt.Tag() != T_string {
-						return nil, fmt.Errorf(`"nocopy" is only applicable to "string" and "binary" types, not %s`, pt)
+					if pt.Tag() != T_string || pt.IsArray() || pt.IsCodec() {
+						return fmt.Errorf(`"nocopy" is only applicable to "string" and "binary" types, not %s`, pt)
 					} else if fv&NoCopy != 0 {
-						return nil, fmt.Errorf(`duplicated option "nocopy" for field %s.%s`, vt, sf.Name)
+						return fmt.Errorf(`duplicated option "nocopy" for field %s.%s`, vt, sf.Name)
 					} else {
 						fv |= NoCopy
 					}
 				}
+
+			// "unsigned" option maps unsigned integers onto signed Thrift types bit by bit
+			case "unsigned":
+				{
+					if !pt.IsUnsigned() {
+						return fmt.Errorf(`"unsigned" is only applicable to types with unsigned integers, not %s: %s.%s`, sf.Type, vt, sf.Name)
+					} else if fv&Unsigned != 0 {
+						return fmt.Errorf(`duplicated option "unsigned" for field %s.%s`, vt, sf.Name)
+					} else {
+						fv |= Unsigned
+					}
+				}
+
+			// "rangecheck" option rejects unsigned values which don't fit in the signed types when encoding
+			case "rangecheck":
+				{
+					if fv&RangeCheck != 0 {
+						return fmt.Errorf(`duplicated option "rangecheck" for field %s.%s`, vt, sf.Name)
+					} else {
+						fv |= RangeCheck
+					}
+				}
+
+			// "precisioncheck" option rejects doubles which can't be narrowed to float32 exactly when decoding
+			case "precisioncheck":
+				{
+					if !pt.IsFloat32() {
+						return fmt.Errorf(`"precisioncheck" is only applicable to types with float32, not %s: %s.%s`, sf.Type, vt, sf.Name)
+					} else if fv&PrecisionCheck != 0 {
+						return fmt.Errorf(`duplicated option "precisioncheck" for field %s.%s`, vt, sf.Name)
+					} else {
+						fv |= PrecisionCheck
+					}
+				}
 			}
 		}
 
-		// get the default value if any
-		if mem.IsValid() {
-			rv = mem.FieldByIndex(sf.Index)
+		// "rangecheck" only works with "unsigned"
+		if fv&RangeCheck != 0 && fv&Unsigned == 0 {
+			return fmt.Errorf(`"rangecheck" requires "unsigned" option for field %s.%s`, vt, sf.Name)
+		}
+
+		// get the default value if any, embedded pointers may be nil
+		defined := false
+		if r.mem.IsValid() {
+			rv, _ = r.mem.FieldByIndexErr(sf.Index)
+			defined = rv.IsValid() && !rv.IsZero()
+			if sv, _ := r.set.FieldByIndexErr(sf.Index); len(embeds) == 0 && canSetNonZero(sv) && sv.IsZero() {
+				defined = true // set to a zero value by InitDefault
+			}
 		}
 
 		// add to result
-		ret = append(ret, Field{
-			F:       int(sf.Offset),
+		r.ret = append(r.ret, Field{
+			F:       offset + int(sf.Offset),
+			Name:    sf.Name,
+			Index:   sf.Index,
 			ID:      uint16(id),
 			Type:    pt,
 			Opts:    fv,
 			Spec:    rx,
 			Default: rv,
+			Defined: defined,
+			Embeds:  embeds,
 		})
 	}
+	return nil
+}
 
-	// sort the field by ID
-	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
-	return ret, nil
+// setNonZero sets the scalar fields of the struct rv to non-zero values,
+// including the fields of embedded structs but not embedded pointers.
+func setNonZero(rv reflect.Value) {
+	for i := 0; i < rv.NumField(); i++ {
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Struct && rv.Type().Field(i).Anonymous {
+			setNonZero(fv)
+			continue
+		}
+		if !canSetNonZero(fv) {
+			continue
+		}
+		switch fv.Kind() {
+		case reflect.Bool:
+			fv.SetBool(true)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fv.SetInt(1)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fv.SetUint(1)
+		case reflect.Float32, reflect.Float64:
+			fv.SetFloat(1)
+		case reflect.String:
+			fv.SetString("1")
+		}
+	}
+}
+
+// canSetNonZero returns true if v is a scalar field which setNonZero sets.
+func canSetNonZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.CanSet()
+	}
+	return false
+}
+
+// resolveEmbedded promotes fields of the embedded struct or pointer to struct,
+// other embedded types are ignored.
+func (r *fieldResolver) resolveEmbedded(sf reflect.StructField, offset int, embeds []Embed) error {
+	et := sf.Type
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if et.Kind() != reflect.Struct || r.path[et] {
+		return nil // not a struct, or a struct embeds itself
+	}
+	offset += int(sf.Offset)
+	if sf.Type.Kind() == reflect.Ptr {
+		embeds = append(embeds[:len(embeds):len(embeds)], Embed{F: offset, S: et})
+		offset = 0
+	}
+	r.path[et] = true
+	defer delete(r.path, et)
+	return r.resolve(et, sf.Index, offset, embeds)
+}
+
+func hasStructTag(tag reflect.StructTag) bool {
+	_, ok := lookupStructTag(tag)
+	return ok
 }
@@ -115,3 +115,291 @@ func TestResolveFields_DuplicateID(t *testing.T) {
 	assert.True(t, err != nil)
 	assert.True(t, strings.Contains(err.Error(), "duplicated field ID 1"))
 }
+
+type RegisteredFields struct {
+	Name  string `frugal:"9,default,i32"` // ignored for registered types
+	Tags  []int32
+	Other int
+	priv  int //nolint:unused // for testing unexported fields
+}
+
+func TestRegisterFields(t *testing.T) {
+	vt := reflect.TypeOf(RegisteredFields{})
+	assert.True(t, RegisterFields(reflect.TypeOf(0), nil) != nil)
+	assert.True(t, RegisterFields(vt, map[string]string{"X": "1"}) != nil)
+	assert.True(t, RegisterFields(vt, map[string]string{"priv": "1"}) != nil)
+	err := RegisterFields(vt, map[string]string{"Tags": "1,default"})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "ambiguous type"), err)
+
+	// failed registrations can be retried
+	assert.Nil(t, RegisterFields(vt, map[string]string{"Name": "1,required,string,nocopy", "Tags": "2,optional,set<i32>"}))
+	err = RegisterFields(vt, nil)
+	assert.True(t, err != nil && strings.Contains(err.Error(), "already registered"), err)
+
+	ret, err := ResolveFields(vt)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ret))
+	assert.Equal(t, "Name", ret[0].Name)
+	assert.Equal(t, uint16(1), ret[0].ID)
+	assert.Equal(t, Required, ret[0].Spec)
+	assert.Equal(t, NoCopy, ret[0].Opts)
+	assert.Equal(t, "Tags", ret[1].Name)
+	assert.Equal(t, T_set, ret[1].Type.T)
+
+	// tags of types in use can't be changed
+	_, _ = ResolveFields(reflect.TypeOf(NoCopyStringFields{}))
+	err = RegisterFields(reflect.TypeOf(NoCopyStringFields{}), nil)
+	assert.True(t, err != nil && strings.Contains(err.Error(), "already in use"), err)
+}
+
+func TestResolveFields_Unsigned(t *testing.T) {
+	type UnsignedFields struct {
+		A uint8             `frugal:"1,default,byte,unsigned"`
+		B *uint64           `frugal:"2,optional,i64,unsigned,rangecheck"`
+		C map[uint32]uint16 `frugal:"3,default,map<i32:i16>,unsigned"`
+		D []uint            `frugal:"4,default,list<i64>,unsigned"`
+	}
+	ret, err := ResolveFields(reflect.TypeOf(UnsignedFields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(ret))
+	assert.Equal(t, T_i8, ret[0].Type.T)
+	assert.Equal(t, Unsigned, ret[0].Opts)
+	assert.Equal(t, Unsigned|RangeCheck, ret[1].Opts)
+	assert.Equal(t, "{unsigned,rangecheck}", ret[1].Opts.String())
+	assert.Equal(t, "map<i32:i16>", ret[2].Type.String())
+
+	for _, tc := range []struct {
+		v   interface{}
+		err string
+	}{
+		{struct {
+			A uint32 `frugal:"1,default,i32"`
+		}{}, "Thrift does not support uint32, use int32 instead"},
+		{struct {
+			A uint32 `frugal:"1,default,i64,unsigned"`
+		}{}, "type mismatch"},
+		{struct {
+			A int32 `frugal:"1,default,i32,unsigned"`
+		}{}, `"unsigned" is only applicable to types with unsigned integers`},
+		{struct {
+			A uint32 `frugal:"1,default,i32,rangecheck"`
+		}{}, `Thrift does not support uint32`},
+		{struct {
+			A uint32 `frugal:"1,default,i32,unsigned,unsigned"`
+		}{}, `duplicated option "unsigned"`},
+		{struct {
+			A int32 `frugal:"1,default,i32,rangecheck"`
+		}{}, `"rangecheck" requires "unsigned" option`},
+	} {
+		_, err := DoResolveFields(reflect.TypeOf(tc.v))
+		assert.True(t, err != nil && strings.Contains(err.Error(), tc.err), err)
+	}
+}
+
+func TestResolveFields_Float32(t *testing.T) {
+	type Float32Fields struct {
+		A float32            `frugal:"1,default,double"`
+		B *float32           `frugal:"2,optional,double,precisioncheck"`
+		C map[string]float32 `frugal:"3,default,map<string:double>,precisioncheck"`
+		D []float32          `frugal:"4,default,list<double>"`
+	}
+	ret, err := ResolveFields(reflect.TypeOf(Float32Fields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(ret))
+	assert.Equal(t, T_double, ret[0].Type.T)
+	assert.Equal(t, PrecisionCheck, ret[1].Opts)
+	assert.Equal(t, "{precisioncheck}", ret[1].Opts.String())
+	assert.True(t, ret[2].Type.IsFloat32())
+	assert.Equal(t, "list<double>", ret[3].Type.String())
+
+	for _, tc := range []struct {
+		v   interface{}
+		err string
+	}{
+		{struct {
+			A float32 `frugal:"1,default,i32"`
+		}{}, "type mismatch"},
+		{struct {
+			A float64 `frugal:"1,default,double,precisioncheck"`
+		}{}, `"precisioncheck" is only applicable to types with float32`},
+		{struct {
+			A float32 `frugal:"1,default,double,precisioncheck,precisioncheck"`
+		}{}, `duplicated option "precisioncheck"`},
+	} {
+		_, err := DoResolveFields(reflect.TypeOf(tc.v))
+		assert.True(t, err != nil && strings.Contains(err.Error(), tc.err), err)
+	}
+}
+
+func TestResolveFields_Array(t *testing.T) {
+	type ArrayFields struct {
+		A [16]byte             `frugal:"1,default"`
+		B *[4]byte             `frugal:"2,optional,binary"`
+		C [3]float64           `frugal:"3,default"`
+		D [2]int32             `frugal:"4,default,set<i32>"`
+		E map[[2]int64]float64 `frugal:"5,default"`
+		F [2]uint8             `frugal:"6,default,list<byte>,unsigned"`
+	}
+	ret, err := ResolveFields(reflect.TypeOf(ArrayFields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(ret))
+	assert.Equal(t, T_binary, ret[0].Type.T)
+	assert.True(t, ret[1].Type.IsArray())
+	assert.Equal(t, "list<double>", ret[2].Type.String())
+	assert.Equal(t, "set<i32>", ret[3].Type.String())
+	assert.Equal(t, "map<list<i64>:double>", ret[4].Type.String())
+	assert.Equal(t, "list<i8>", ret[5].Type.String())
+
+	for _, tc := range []struct {
+		v   interface{}
+		err string
+	}{
+		{struct {
+			A [4]byte `frugal:"1,default,binary,nocopy"`
+		}{}, `"nocopy" is only applicable`},
+		{struct {
+			A [4]int32 `frugal:"1,default,list<i64>"`
+		}{}, "type mismatch"},
+		{struct {
+			A map[[2]string]bool `frugal:"1,default,map<binary:bool>"`
+		}{}, `"set" or "list" expected`},
+	} {
+		_, err := DoResolveFields(reflect.TypeOf(tc.v))
+		assert.True(t, err != nil && strings.Contains(err.Error(), tc.err), err)
+	}
+}
+
+func TestResolveFields_MapSet(t *testing.T) {
+	type MapSetElem struct {
+		X int32 `frugal:"1,default,i32"`
+	}
+	type MapSetFields struct {
+		A map[int32]struct{}       `frugal:"1,default,set<i32>"`
+		B map[string]bool          `frugal:"2,optional,set<string>"`
+		C map[*MapSetElem]struct{} `frugal:"3,default,set<MapSetElem>"`
+		D map[string]bool          `frugal:"4,default,map<string:bool>"`
+		E map[uint16]struct{}      `frugal:"5,default,set<i16>,unsigned"`
+	}
+	ret, err := ResolveFields(reflect.TypeOf(MapSetFields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(ret))
+	assert.Equal(t, "set<i32>", ret[0].Type.String())
+	assert.True(t, ret[0].Type.IsMapSet())
+	assert.True(t, ret[1].Type.IsMapSet())
+	assert.Equal(t, T_struct, ret[2].Type.V.V.T)
+	assert.Equal(t, "map<string:bool>", ret[3].Type.String())
+	assert.True(t, !ret[3].Type.IsMapSet())
+	assert.True(t, ret[4].Type.IsUnsigned())
+
+	for _, tc := range []struct {
+		v   interface{}
+		err string
+	}{
+		{struct {
+			A map[int32]int32 `frugal:"1,default,set<i32>"`
+		}{}, "only maps of struct{} or bool values can be sets"},
+		{struct {
+			A map[int32]struct{} `frugal:"1,default,list<i32>"`
+		}{}, "Syntax error"},
+		{struct {
+			A map[int32]struct{} `frugal:"1,default,set<i64>"`
+		}{}, "type mismatch"},
+		{struct {
+			A map[MapSetElem]struct{} `frugal:"1,default,set<MapSetElem>"`
+		}{}, "not a valid set element type"},
+	} {
+		_, err := DoResolveFields(reflect.TypeOf(tc.v))
+		assert.True(t, err != nil && strings.Contains(err.Error(), tc.err), err)
+	}
+}
+
+type EmbeddedBase struct {
+	LogID string `frugal:"1,default,string"`
+}
+
+type EmbeddedPage struct {
+	EmbeddedBase
+	Limit int32 `frugal:"2,default,i32"`
+}
+
+type EmbeddedCycle struct {
+	*EmbeddedCycle
+	X int32 `frugal:"1,default,i32"`
+}
+
+type EmbeddedFields struct {
+	*EmbeddedPage
+	EmbeddedCycle
+	Name  string `frugal:"3,default,string"`
+	Other EmbeddedBase
+}
+
+func TestResolveFields_Embedded(t *testing.T) {
+	type CycleFields struct {
+		EmbeddedCycle
+		Y int32 `frugal:"2,default,i32"`
+	}
+	ret, err := ResolveFields(reflect.TypeOf(CycleFields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(ret))
+	assert.Equal(t, "X", ret[0].Name)
+	assert.DeepEqual(t, []int{0, 1}, ret[0].Index)
+
+	_, err = ResolveFields(reflect.TypeOf(EmbeddedFields{}))
+	assert.True(t, err != nil && strings.Contains(err.Error(), "duplicated field ID 1"), err)
+
+	type TaggedFields struct {
+		*EmbeddedPage `frugal:"1,optional,EmbeddedPage"`
+		EmbeddedBase  `frugal:"2,default,EmbeddedBase"`
+		Name          string `frugal:"3,default,string"`
+	}
+	ret, err = ResolveFields(reflect.TypeOf(TaggedFields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ret))
+	assert.Equal(t, T_pointer, ret[0].Type.T)
+	assert.Equal(t, T_struct, ret[1].Type.T)
+
+	type PageFields struct {
+		Name string `frugal:"3,default,string"`
+		*EmbeddedPage
+	}
+	vt := reflect.TypeOf(PageFields{})
+	ret, err = ResolveFields(vt)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ret))
+	assert.Equal(t, "LogID", ret[0].Name)
+	assert.DeepEqual(t, []int{1, 0, 0}, ret[0].Index)
+	assert.Equal(t, 1, len(ret[0].Embeds))
+	assert.Equal(t, int(vt.Field(1).Offset), ret[0].Embeds[0].F)
+	assert.Equal(t, reflect.TypeOf(EmbeddedPage{}), ret[0].Embeds[0].S)
+	assert.Equal(t, 0, ret[0].F)
+	assert.Equal(t, "Limit", ret[1].Name)
+	assert.Equal(t, int(reflect.TypeOf(EmbeddedPage{}).Field(1).Offset), ret[1].F)
+	assert.Equal(t, 0, len(ret[2].Embeds))
+
+	s, err := Describe(vt)
+	assert.Nil(t, err)
+	assert.Equal(t, "LogID", s.Fields[0].GoName)
+	assert.Equal(t, "Limit", s.Fields[1].GoName)
+}
+
+type EmbeddedDefaults struct {
+	*EmbeddedPage
+	Name string `frugal:"3,default,string"`
+}
+
+func (p *EmbeddedDefaults) InitDefault() {
+	p.Name = "name"
+	p.EmbeddedPage = &EmbeddedPage{Limit: 10}
+}
+
+func TestResolveFields_EmbeddedDefaults(t *testing.T) {
+	ret, err := ResolveFields(reflect.TypeOf(EmbeddedDefaults{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(ret))
+	assert.Equal(t, int64(10), ret[1].Default.Int())
+
+	s, err := Describe(reflect.TypeOf(EmbeddedDefaults{}))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, int32(10), s.Fields[1].Default)
+}
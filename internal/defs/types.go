@@ -38,9 +38,11 @@ const (
 	T_map     Tag = 13
 	T_set     Tag = 14
 	T_list    Tag = 15
+	T_uuid    Tag = 16
 	T_enum    Tag = 0x80
 	T_binary  Tag = 0x81
 	T_pointer Tag = 0x82
+	T_codec   Tag = 0x83
 )
 
 var wireTags = [256]bool{
@@ -55,6 +57,7 @@ var wireTags = [256]bool{
 	T_map:    true,
 	T_set:    true,
 	T_list:   true,
+	T_uuid:   true,
 }
 
 var keywordTab = [256]string{
@@ -68,11 +71,13 @@ var keywordTab = [256]string{
 	T_binary: "binary",
 	T_struct: "struct",
 	T_map:    "map",
+	T_uuid:   "uuid",
 }
 
 var (
 	i64type  = reflect.TypeOf(int64(0))
 	bytetype = reflect.TypeOf(byte(0))
+	uuidtype = reflect.TypeOf([16]byte{})
 )
 
 func T_int() Tag {
@@ -122,6 +127,8 @@ func (t *Type) Tag() Tag {
 		return T_string
 	case T_pointer:
 		return t.V.Tag()
+	case T_codec:
+		return (&Type{T: t.codecWireTag()}).Tag()
 	default:
 		return t.T
 	}
@@ -166,12 +173,16 @@ func (t *Type) String() string {
 		return fmt.Sprintf("set<%s>", t.V.String())
 	case T_list:
 		return fmt.Sprintf("list<%s>", t.V.String())
+	case T_uuid:
+		return "uuid"
 	case T_enum:
 		return "enum"
 	case T_binary:
 		return "binary"
 	case T_pointer:
 		return "*" + t.V.String()
+	case T_codec:
+		return (&Type{T: t.codecWireTag()}).String()
 	default:
 		return fmt.Sprintf("Type(Tag(%d))", t.T)
 	}
@@ -193,10 +204,41 @@ func (t *Type) IsKeyType() bool {
 		return true
 	case T_string:
 		return true
+	case T_uuid:
+		return true
 	case T_enum:
 		return true
+	case T_codec:
+		return true // custom codecs are scalars, Go checks if they're comparable
 	case T_pointer:
 		return t.V.T == T_struct
+	case T_binary, T_set, T_list:
+		return t.IsArray() // arrays are comparable
+	default:
+		return false
+	}
+}
+
+// IsArray returns true if the type is a Go array or a pointer to array, which is binary for byte arrays, or list otherwise.
+func (t *Type) IsArray() bool {
+	switch t.T {
+	case T_binary, T_set, T_list:
+		return t.S.Kind() == reflect.Array
+	case T_pointer:
+		return t.V.IsArray()
+	default:
+		return false
+	}
+}
+
+// IsMapSet returns true if the type is a set of Go map like map[K]struct{} or map[K]bool,
+// keys of false values are not in the set.
+func (t *Type) IsMapSet() bool {
+	switch t.T {
+	case T_set:
+		return t.S.Kind() == reflect.Map
+	case T_pointer:
+		return t.V.IsMapSet()
 	default:
 		return false
 	}
@@ -231,7 +273,52 @@ func (t *Type) IsSimpleType() bool {
 
 func ParseType(vt reflect.Type, def string) (*Type, error) {
 	var i int
-	return doParseType(vt, def, &i, true)
+	return doParseType(vt, def, &i, true, false)
+}
+
+// ParseUnsignedType is like ParseType, but unsigned integers are mapped onto
+// the signed Thrift types of the same sizes, like uint32 -> i32.
+func ParseUnsignedType(vt reflect.Type, def string) (*Type, error) {
+	var i int
+	return doParseType(vt, def, &i, true, true)
+}
+
+// IsUnsigned returns true if the type or any of its elements is an unsigned integer.
+func (t *Type) IsUnsigned() bool {
+	switch t.T {
+	case T_pointer, T_set, T_list:
+		return t.V.IsUnsigned()
+	case T_map:
+		return t.K.IsUnsigned() || t.V.IsUnsigned()
+	case T_i8, T_i16, T_i32, T_i64, T_enum:
+		return isUnsignedKind(t.S.Kind())
+	default:
+		return false
+	}
+}
+
+// IsFloat32 returns true if the type or any of its elements is a float32,
+// which is widened to double when encoding, and narrowed when decoding.
+func (t *Type) IsFloat32() bool {
+	switch t.T {
+	case T_pointer, T_set, T_list:
+		return t.V.IsFloat32()
+	case T_map:
+		return t.K.IsFloat32() || t.V.IsFloat32()
+	case T_double:
+		return t.S.Kind() == reflect.Float32
+	default:
+		return false
+	}
+}
+
+func isUnsignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
 }
 
 func isident(c byte) bool {
@@ -284,7 +371,7 @@ func mkMistyped(pos int, src string, tv string, tag Tag, vt reflect.Type) Syntax
 	}
 }
 
-func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool) (*Type, error) {
+func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool, unsigned bool) (*Type, error) {
 	var tag Tag
 	var err error
 	var ret *Type
@@ -296,17 +383,22 @@ func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool) (*Type, er
 
 		/* prohibit nested pointers */
 		if !allowPtrs {
-			return nil, EType(ret.V.S, "nested pointer is not allowed")
+			return nil, EType(vt, "nested pointer is not allowed")
 		}
 
 		/* parse the pointer element recursively */
-		if ret.V, err = doParseType(vt.Elem(), def, i, false); err != nil {
+		if ret.V, err = doParseType(vt.Elem(), def, i, false, unsigned); err != nil {
 			return nil, err
 		} else {
 			return ret, nil
 		}
 	}
 
+	/* types with custom codecs */
+	if wt, ok := codecTag(vt); ok {
+		return doParseCodec(vt, wt, def, i, ret)
+	}
+
 	/* check for value kind */
 	switch vt.Kind() {
 	case reflect.Bool:
@@ -322,21 +414,34 @@ func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool) (*Type, er
 	case reflect.Int64:
 		tag = T_i64
 	case reflect.Uint:
-		return nil, EUseOther(vt, "int")
+		if !unsigned {
+			return nil, EUseOther(vt, "int")
+		}
+		tag = T_int()
 	case reflect.Uint8:
-		return nil, EUseOther(vt, "int8")
+		if !unsigned {
+			return nil, EUseOther(vt, "int8")
+		}
+		tag = T_i8
 	case reflect.Uint16:
-		return nil, EUseOther(vt, "int16")
+		if !unsigned {
+			return nil, EUseOther(vt, "int16")
+		}
+		tag = T_i16
 	case reflect.Uint32:
-		return nil, EUseOther(vt, "int32")
+		if !unsigned {
+			return nil, EUseOther(vt, "int32")
+		}
+		tag = T_i32
 	case reflect.Uint64:
-		return nil, EUseOther(vt, "int64")
-	case reflect.Float32:
-		return nil, EUseOther(vt, "float64")
-	case reflect.Float64:
+		if !unsigned {
+			return nil, EUseOther(vt, "int64")
+		}
+		tag = T_i64
+	case reflect.Float32, reflect.Float64:
 		tag = T_double
 	case reflect.Array:
-		return nil, EUseOther(vt, "[]"+vt.Elem().String())
+		break
 	case reflect.Map:
 		tag = T_map
 	case reflect.Slice:
@@ -349,17 +454,26 @@ func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool) (*Type, er
 		return nil, EType(vt, "unsupported type")
 	}
 
-	/* it's a slice, check for byte slice */
+	/* it's a slice or an array, check for byte slice or byte array */
 	if tag == 0 {
-		if et := vt.Elem(); et == bytetype {
+		if isUUID(vt, def, *i) {
+			tag = T_uuid
+		} else if et := vt.Elem(); et == bytetype && !(unsigned && isSetOrList(def, *i)) {
 			tag = T_binary
-		} else if def == "" {
+		} else if def != "" {
+			return doParseSlice(vt, et, def, i, ret, unsigned)
+		} else if vt.Kind() != reflect.Array {
 			return nil, ESetList(*i, def, et)
 		} else {
-			return doParseSlice(vt, et, def, i, ret)
+			return doParseArray(vt, et, ret, unsigned)
 		}
 	}
 
+	/* it's a map as a set, like map[K]struct{} or map[K]bool */
+	if tag == T_map && isSet(def, *i) {
+		return doParseMapSet(vt, def, i, ret, unsigned)
+	}
+
 	/* match the type if any */
 	if def != "" {
 		if tv, et := readToken(def, i, false); et != nil {
@@ -394,7 +508,7 @@ func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool) (*Type, er
 	}
 
 	/* parse the key type */
-	if ret.K, err = doParseType(vt.Key(), def, i, true); err != nil {
+	if ret.K, err = doParseType(vt.Key(), def, i, true, unsigned); err != nil {
 		return nil, err
 	}
 
@@ -413,7 +527,7 @@ func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool) (*Type, er
 	}
 
 	/* parse the value type */
-	if ret.V, err = doParseType(vt.Elem(), def, i, true); err != nil {
+	if ret.V, err = doParseType(vt.Elem(), def, i, true, unsigned); err != nil {
 		return nil, err
 	}
 
@@ -437,7 +551,43 @@ func doParseType(vt reflect.Type, def string, i *int, allowPtrs bool) (*Type, er
 	return ret, nil
 }
 
-func doParseSlice(vt reflect.Type, et reflect.Type, def string, i *int, rt *Type) (*Type, error) {
+// isSetOrList returns true if the next token is "set" or "list",
+// it's for []uint8 with the "unsigned" option, which is binary by default.
+func isSetOrList(def string, i int) bool {
+	tok, err := readToken(def, &i, true)
+	return err == nil && (tok == "set" || tok == "list")
+}
+
+// isUUID returns true if vt is [16]byte and the next token is "uuid", [16]byte is binary by default.
+func isUUID(vt reflect.Type, def string, i int) bool {
+	if vt.Kind() != reflect.Array || vt.Len() != 16 || vt.Elem() != bytetype {
+		return false
+	}
+	tok, err := readToken(def, &i, true)
+	return err == nil && tok == "uuid"
+}
+
+// isSet returns true if the next token is "set".
+func isSet(def string, i int) bool {
+	tok, err := readToken(def, &i, true)
+	return err == nil && tok == "set"
+}
+
+// doParseMapSet parses maps of which the values are struct{} or bool as sets of the keys.
+func doParseMapSet(vt reflect.Type, def string, i *int, rt *Type, unsigned bool) (*Type, error) {
+	if et := vt.Elem(); et.Kind() != reflect.Bool && (et.Kind() != reflect.Struct || et.Size() != 0) {
+		return nil, EType(vt, "only maps of struct{} or bool values can be sets")
+	}
+	if _, err := doParseSlice(vt, vt.Key(), def, i, rt, unsigned); err != nil {
+		return nil, err
+	}
+	if !rt.V.IsKeyType() {
+		return nil, EType(rt.V.S, "not a valid set element type of maps")
+	}
+	return rt, nil
+}
+
+func doParseSlice(vt reflect.Type, et reflect.Type, def string, i *int, rt *Type, unsigned bool) (*Type, error) {
 	var err error
 	var tok string
 
@@ -464,7 +614,7 @@ func doParseSlice(vt reflect.Type, et reflect.Type, def string, i *int, rt *Type
 	}
 
 	/* set or list element */
-	if rt.V, err = doParseType(et, def, i, true); err != nil {
+	if rt.V, err = doParseType(et, def, i, true, unsigned); err != nil {
 		return nil, err
 	}
 
@@ -485,6 +635,27 @@ func doParseSlice(vt reflect.Type, et reflect.Type, def string, i *int, rt *Type
 	return rt, nil
 }
 
+// doParseArray parses arrays without type descriptors, which are lists.
+func doParseArray(vt reflect.Type, et reflect.Type, rt *Type, unsigned bool) (*Type, error) {
+	var i int
+	var err error
+
+	/* array element */
+	if rt.V, err = doParseType(et, "", &i, true, unsigned); err != nil {
+		return nil, err
+	}
+
+	/* check for list elements */
+	if !rt.V.IsValueType() {
+		return nil, EType(rt.V.S, "non-struct pointers are not valid list/set elements")
+	}
+
+	/* set the type */
+	rt.S = vt
+	rt.T = T_list
+	return rt, nil
+}
+
 func doMatchStruct(vt reflect.Type, def string, i *int, tv *string) (bool, error) {
 	var err error
 	var tok string
@@ -31,6 +31,53 @@ func TestTypes_Parsing(t *testing.T) {
 	fmt.Println(tt)
 }
 
+func TestTypes_Pointer(t *testing.T) {
+	var v *[]int32
+	tt, err := ParseType(reflect.TypeOf(v), "list<i32>")
+	assert.Nil(t, err)
+	assert.Equal(t, T_pointer, tt.T)
+	assert.Equal(t, "*list<i32>", tt.String())
+
+	var m *map[string]bool
+	tt, err = ParseType(reflect.TypeOf(m), "set<string>")
+	assert.Nil(t, err)
+	assert.True(t, tt.IsMapSet())
+
+	var pp **int32
+	_, err = ParseType(reflect.TypeOf(pp), "i32")
+	assert.DeepEqual(t, EType(reflect.TypeOf(pp).Elem(), "nested pointer is not allowed"), err)
+}
+
+func TestTypes_UUID(t *testing.T) {
+	type UUID [16]byte
+	type UUIDFields struct {
+		A [16]byte            `frugal:"1,default,uuid"`
+		B *UUID               `frugal:"2,optional,uuid"`
+		C []UUID              `frugal:"3,default,list<uuid>"`
+		D map[[16]byte]string `frugal:"4,default,map<uuid:string>"`
+		E map[UUID]struct{}   `frugal:"5,default,set<uuid>"`
+		F [16]byte            `frugal:"6,default,binary"`
+	}
+	ff, err := DoResolveFields(reflect.TypeOf(UUIDFields{}))
+	assert.Nil(t, err)
+	assert.Equal(t, T_uuid, ff[0].Type.T)
+	assert.Equal(t, T_uuid, ff[1].Type.V.T)
+	assert.Equal(t, "list<uuid>", ff[2].Type.String())
+	assert.Equal(t, "map<uuid:string>", ff[3].Type.String())
+	assert.True(t, ff[4].Type.IsMapSet())
+	assert.Equal(t, T_binary, ff[5].Type.T)
+
+	for _, v := range []interface{}{
+		[]byte{},
+		[8]byte{},
+		[16]int8{},
+		"",
+	} {
+		_, err = ParseType(reflect.TypeOf(v), "uuid")
+		assert.True(t, err != nil, v)
+	}
+}
+
 func TestTypes_MapKeyType(t *testing.T) {
 	var v map[*reflect.SliceHeader]int
 	tt, err := ParseType(reflect.TypeOf(v), "map<foo.SliceHeader:i64>")
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package defs
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	unionsLock = new(sync.RWMutex)
+	unions     = make(map[reflect.Type]bool)
+)
+
+// RegisterUnion declares vt as a Thrift union, which has exactly one field set.
+// All fields of vt must be optional, and of types which can be nil,
+// like pointers, slices and maps, then a field is set if it's not nil.
+//
+// It must be called before vt is used, and it can be called before or after RegisterFields.
+func RegisterUnion(vt reflect.Type) error {
+	if vt.Kind() != reflect.Struct {
+		return EType(vt, "not a struct")
+	}
+	unionsLock.Lock()
+	if unions[vt] {
+		unionsLock.Unlock()
+		return fmt.Errorf("union %s is already registered", vt)
+	}
+	unions[vt] = true
+	unionsLock.Unlock()
+
+	// validate the fields without caching them like RegisterFields,
+	// the fields of vt can still be declared by RegisterFields.
+	if _, err := DoResolveFields(vt); err != nil {
+		unionsLock.Lock()
+		delete(unions, vt)
+		unionsLock.Unlock()
+		return err
+	}
+	return nil
+}
+
+// checkUnionFields checks the fields fv of the union vt, it's called by DoResolveFields.
+// Unions without fields are checked by ResolveFields when used,
+// for the fields may be declared by RegisterFields after RegisterUnion.
+func checkUnionFields(vt reflect.Type, fv []Field) error {
+	for _, f := range fv {
+		if f.Spec != Optional || !f.Type.isNilable() {
+			return fmt.Errorf("field %s.%s of union must be optional pointer, slice or map, not %s %s", vt, f.Name, f.Spec, f.Type)
+		}
+	}
+	return nil
+}
+
+// IsUnion returns true if vt is registered by RegisterUnion.
+func IsUnion(vt reflect.Type) bool {
+	unionsLock.RLock()
+	defer unionsLock.RUnlock()
+	return unions[vt]
+}
+
+// isNilable returns true if values of the type can be nil, arrays are never nil.
+func (t *Type) isNilable() bool {
+	switch t.T {
+	case T_pointer, T_map:
+		return true
+	case T_binary, T_set, T_list:
+		return !t.IsArray()
+	default:
+		return false
+	}
+}
@@ -24,11 +24,22 @@ import (
 const (
 	_DefaultMaxInlineDepth  = 2     // cutoff at 2 levels of inlining
 	_DefaultMaxInlineILSize = 50000 // cutoff at 50k of IL instructions
+
+	_DefaultNocopyWriteThreshold = 4096 // same as cloudwego/gopkg
 )
 
 var (
 	MaxInlineDepth  = parseOrDefault("FRUGAL_MAX_INLINE_DEPTH", _DefaultMaxInlineDepth, 1)
 	MaxInlineILSize = parseOrDefault("FRUGAL_MAX_INLINE_IL_SIZE", _DefaultMaxInlineILSize, 256)
+
+	// NocopyWriteThreshold is the min size of strings or binaries written by thrift.NocopyWriter, it's always positive
+	NocopyWriteThreshold = parseOrDefault("FRUGAL_NOCOPY_WRITE_THRESHOLD", _DefaultNocopyWriteThreshold, 0)
+
+	// CheckSetUniqueness enables the uniqueness check of set elements when encoding and decoding
+	CheckSetUniqueness = os.Getenv("FRUGAL_CHECK_SET_UNIQUENESS") == "1"
+
+	// CheckRequired enables the check of required fields when encoding
+	CheckRequired = os.Getenv("FRUGAL_CHECK_REQUIRED") == "1"
 )
 
 func parseOrDefault(key string, def int, min int) int {
@@ -16,9 +16,20 @@
 
 package opts
 
+// Options are the options of decoding, zero values mean no limit and no strictness.
 type Options struct {
+	MaxStringSize    int // max length of a string or binary
+	MaxContainerSize int // max number of elements of a list, set or map
+	MaxDepth         int // max nesting depth of structs and containers, the default 1023 if zero
+	MaxAllocSize     int // max total bytes allocated for strings, binaries and containers of a call
+
+	DisallowUnknownFields        bool // fails on fields of unknown IDs instead of skipping them
+	DisallowMismatchedFieldTypes bool // fails on fields of known IDs with unexpected wire types instead of skipping them
 }
 
+// DefaultOptions are used by decoding funcs without options.
+var DefaultOptions Options
+
 func GetDefaultOptions() Options {
-	return Options{}
+	return DefaultOptions
 }
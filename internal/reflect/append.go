@@ -16,23 +16,44 @@
 
 package reflect
 
-import "unsafe"
+import (
+	"math"
+	"unsafe"
 
-func appendStruct(t *tType, b []byte, base unsafe.Pointer) ([]byte, error) {
+	"github.com/cloudwego/frugal/internal/opts"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+// appendStringNocopy appends the string or binary s without the length header,
+// or writes it by w.WriteDirect if it's not smaller than opts.NocopyWriteThreshold.
+// The threshold is always positive, so empty strings are never written by w.
+// Callers append s directly if w is nil, which is the fast path.
+func appendStringNocopy(b []byte, s string, w thrift.NocopyWriter) ([]byte, error) {
+	if len(s) < opts.NocopyWriteThreshold {
+		return append(b, s...), nil
+	}
+	// the remaining cap of b is the part of the buffer after s, see EncodeObject
+	return b, w.WriteDirect(unsafe.Slice(unsafe.StringData(s), len(s)), cap(b)-len(b))
+}
+
+func appendStruct(t *tType, b []byte, base unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	sd := t.Sd
+	if err := sd.checkEncode(base); err != nil {
+		return b, err
+	}
 	if base == nil {
 		return append(b, byte(tSTOP)), nil
 	}
 	var err error
 	for _, f := range sd.fields {
-		t := f.Type
-		p := unsafe.Add(base, f.Offset)
-		if f.CanSkipEncodeIfNil && *(*unsafe.Pointer)(p) == nil {
+		p := f.encodedPointer(base)
+		if p == nil {
 			continue
 		}
-		if f.CanSkipIfDefault && t.Equal(f.Default, p) {
-			continue
+		if err := sd.checkEncodedField(f, p); err != nil {
+			return b, err
 		}
+		t := f.Type
 
 		// field header
 		b = append(b, byte(t.WT), byte(f.ID>>8), byte(f.ID))
@@ -56,28 +77,31 @@ func appendStruct(t *tType, b []byte, base unsafe.Pointer) ([]byte, error) {
 				b = appendUint32(b, uint32(*((*int64)(p))))
 			case tI64, tDOUBLE:
 				b = appendUint64(b, *((*uint64)(p)))
+			case tFLOAT:
+				b = appendUint64(b, math.Float64bits(float64(*((*float32)(p)))))
+			case tUUID:
+				b = append(b, (*[16]byte)(p)[:]...)
 			case tSTRING:
 				s := *((*string)(p))
 				b = appendUint32(b, uint32(len(s)))
-				b = append(b, s...)
+				if w == nil {
+					b = append(b, s...)
+				} else if b, err = appendStringNocopy(b, s, w); err != nil {
+					return b, err
+				}
 			}
 		} else {
-			b, err = t.AppendFunc(t, b, p)
+			b, err = t.AppendFunc(t, b, p, w)
 			if err != nil {
 				return b, withFieldErr(err, sd, f)
 			}
 		}
 	}
-	if sd.hasUnknownFields {
-		xb := *(*[]byte)(unsafe.Add(base, sd.unknownFieldsOffset))
-		if len(xb) > 0 {
-			b = append(b, xb...)
-		}
-	}
+	b = append(b, sd.getUnknownFields(base)...)
 	return append(b, byte(tSTOP)), nil
 }
 
-func appendAny(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendAny(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	if t.IsPointer {
 		p = *(*unsafe.Pointer)(p)
 	}
@@ -93,13 +117,20 @@ func appendAny(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 			b = appendUint32(b, uint32(*((*int64)(p))))
 		case tI64, tDOUBLE:
 			b = appendUint64(b, *((*uint64)(p)))
+		case tFLOAT:
+			b = appendUint64(b, math.Float64bits(float64(*((*float32)(p)))))
+		case tUUID:
+			b = append(b, (*[16]byte)(p)[:]...)
 		case tSTRING:
 			s := *((*string)(p))
 			b = appendUint32(b, uint32(len(s)))
+			if w != nil {
+				return appendStringNocopy(b, s, w)
+			}
 			b = append(b, s...)
 		}
 		return b, nil
 	} else {
-		return t.AppendFunc(t, b, p)
+		return t.AppendFunc(t, b, p, w)
 	}
 }
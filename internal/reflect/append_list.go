@@ -16,7 +16,11 @@
 
 package reflect
 
-import "unsafe"
+import (
+	"unsafe"
+
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
 
 var listAppendFuncs = map[ttype]appendFuncType{}
 
@@ -25,11 +29,19 @@ func updateListAppendFunc(t *tType) {
 		panic("[bug] type mismatch, got: " + ttype2str(t.T))
 	}
 	f, ok := listAppendFuncs[t.V.T]
-	if ok {
-		t.AppendFunc = f
+	if !ok {
+		f = appendListAny
+	}
+	if t.T == tSET {
+		t.AppendFunc = func(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
+			if err := t.checkSetElems(p); err != nil {
+				return b, err
+			}
+			return f(t, b, p, w)
+		}
 		return
 	}
-	t.AppendFunc = appendListAny
+	t.AppendFunc = f
 }
 
 func registerListAppendFunc(t ttype, f appendFuncType) {
@@ -45,7 +57,7 @@ func appendListHeader(t *tType, b []byte, p unsafe.Pointer) ([]byte, uint32, uns
 	return append(b, byte(t.WT), byte(n>>24), byte(n>>16), byte(n>>8), byte(n)), n, h.Data
 }
 
-func appendListAny(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendListAny(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
@@ -56,7 +68,7 @@ func appendListAny(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 		if i != 0 {
 			vp = unsafe.Add(vp, t.Size) // move to next element
 		}
-		b, err = appendAny(t, b, vp)
+		b, err = appendAny(t, b, vp, w)
 		if err != nil {
 			return b, err
 		}
@@ -16,7 +16,12 @@
 
 package reflect
 
-import "unsafe"
+import (
+	"math"
+	"unsafe"
+
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
 
 // Predefined fast paths for common list element types.
 
@@ -27,6 +32,7 @@ func init() {
 	registerListAppendFunc(tI64, appendList_I64)
 	registerListAppendFunc(tDOUBLE, appendList_I64)
 	registerListAppendFunc(tENUM, appendList_ENUM)
+	registerListAppendFunc(tFLOAT, appendList_FLOAT)
 	registerListAppendFunc(tSTRING, appendList_STRING)
 	registerListAppendFunc(tSTRUCT, appendList_Other)
 	registerListAppendFunc(tMAP, appendList_Other)
@@ -34,7 +40,7 @@ func init() {
 	registerListAppendFunc(tLIST, appendList_Other)
 }
 
-func appendList_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendList_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
@@ -49,7 +55,7 @@ func appendList_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, nil
 }
 
-func appendList_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendList_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
@@ -64,7 +70,7 @@ func appendList_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, nil
 }
 
-func appendList_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendList_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
@@ -79,7 +85,7 @@ func appendList_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, nil
 }
 
-func appendList_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendList_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
@@ -94,7 +100,7 @@ func appendList_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, nil
 }
 
-func appendList_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendList_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
@@ -109,12 +115,28 @@ func appendList_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, nil
 }
 
-func appendList_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendList_FLOAT(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	for i := uint32(0); i < n; i++ {
+		if i != 0 {
+			vp = unsafe.Add(vp, t.Size)
+		}
+		b = appendUint64(b, math.Float64bits(float64(*((*float32)(vp)))))
+	}
+	return b, nil
+}
+
+func appendList_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
+	t = t.V
+	b, n, vp := appendListHeader(t, b, p)
+	if n == 0 {
+		return b, nil
+	}
+	var err error
 	var s string
 	for i := uint32(0); i < n; i++ {
 		if i != 0 {
@@ -122,12 +144,16 @@ func appendList_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 		}
 		s = *((*string)(vp))
 		b = appendUint32(b, uint32(len(s)))
-		b = append(b, s...)
+		if w == nil {
+			b = append(b, s...)
+		} else if b, err = appendStringNocopy(b, s, w); err != nil {
+			return b, err
+		}
 	}
 	return b, nil
 }
 
-func appendList_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendList_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	t = t.V
 	b, n, vp := appendListHeader(t, b, p)
 	if n == 0 {
@@ -139,9 +165,9 @@ func appendList_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 			vp = unsafe.Add(vp, t.Size)
 		}
 		if t.IsPointer {
-			b, err = t.AppendFunc(t, b, *(*unsafe.Pointer)(vp))
+			b, err = t.AppendFunc(t, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.AppendFunc(t, b, vp)
+			b, err = t.AppendFunc(t, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -28,7 +28,7 @@ func TestAppendListAny(t *testing.T) {
 	typ := &tType{T: tLIST}
 	typ.V = &tType{T: tI64, WT: tI64, Size: 8, SimpleType: true}
 	v := []int64{1, 2}
-	b, err := appendListAny(typ, nil, unsafe.Pointer(&v))
+	b, err := appendListAny(typ, nil, unsafe.Pointer(&v), nil)
 	assert.Nil(t, err)
 
 	x := thrift.BinaryProtocol{}
@@ -39,7 +39,7 @@ func TestAppendListAny(t *testing.T) {
 
 	// empty case
 	v = nil
-	b, err = appendListAny(typ, nil, unsafe.Pointer(&v))
+	b, err = appendListAny(typ, nil, unsafe.Pointer(&v), nil)
 	assert.Nil(t, err)
 	expectb = x.AppendListBegin(nil, thrift.I64, 0)
 	assert.BytesEqual(t, expectb, b)
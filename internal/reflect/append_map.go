@@ -19,6 +19,8 @@ package reflect
 import (
 	"errors"
 	"unsafe"
+
+	"github.com/cloudwego/gopkg/protocol/thrift"
 )
 
 var mapAppendFuncs = map[struct{ k, v ttype }]appendFuncType{}
@@ -58,7 +60,7 @@ func appendMapHeader(t *tType, b []byte, p unsafe.Pointer) ([]byte, uint32) {
 
 // Fast-path registrations in append_map_fast.go replace this fallback for
 // supported key/value scalar combinations.
-func appendMapAnyAny(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMapAnyAny(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -68,11 +70,11 @@ func appendMapAnyAny(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	it := newMapIter(rvWithPtr(t.RV, p))
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
-		b, err = appendAny(t.K, b, kp)
+		b, err = appendAny(t.K, b, kp, w)
 		if err != nil {
 			return b, err
 		}
-		b, err = appendAny(t.V, b, vp)
+		b, err = appendAny(t.V, b, vp, w)
 		if err != nil {
 			return b, err
 		}
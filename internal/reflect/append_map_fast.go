@@ -16,7 +16,11 @@
 
 package reflect
 
-import "unsafe"
+import (
+	"unsafe"
+
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
 
 // Predefined fast paths for common map key/value combinations.
 
@@ -174,7 +178,7 @@ func init() {
 	registerMapAppendFunc(tLIST, tLIST, appendMap_Other_Other)
 }
 
-func appendMap_BOOL_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -187,7 +191,7 @@ func appendMap_BOOL_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_BOOL_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -200,7 +204,7 @@ func appendMap_BOOL_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_BOOL_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -213,7 +217,7 @@ func appendMap_BOOL_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_BOOL_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -226,7 +230,7 @@ func appendMap_BOOL_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_BOOL_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -239,7 +243,7 @@ func appendMap_BOOL_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_BOOL_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -252,21 +256,26 @@ func appendMap_BOOL_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_BOOL_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[bool]string)(p) {
 		n--
 		b = appendMapBool(b, k)
 		b = appendUint32(b, uint32(len(v)))
-		b = append(b, v...)
+		if w == nil {
+			b = append(b, v...)
+		} else if b, err = appendStringNocopy(b, v, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_BOOL_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_BOOL_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -277,9 +286,9 @@ func appendMap_BOOL_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 		n--
 		b = append(b, *((*byte)(kp)))
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -288,7 +297,7 @@ func appendMap_BOOL_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -301,7 +310,7 @@ func appendMap_I08_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -314,7 +323,7 @@ func appendMap_I08_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -327,7 +336,7 @@ func appendMap_I08_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -340,7 +349,7 @@ func appendMap_I08_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -353,7 +362,7 @@ func appendMap_I08_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -366,21 +375,26 @@ func appendMap_I08_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[byte]string)(p) {
 		n--
 		b = append(b, k)
 		b = appendUint32(b, uint32(len(v)))
-		b = append(b, v...)
+		if w == nil {
+			b = append(b, v...)
+		} else if b, err = appendStringNocopy(b, v, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_I08_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I08_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -391,9 +405,9 @@ func appendMap_I08_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 		n--
 		b = append(b, *((*byte)(kp)))
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -402,7 +416,7 @@ func appendMap_I08_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -415,7 +429,7 @@ func appendMap_I16_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -428,7 +442,7 @@ func appendMap_I16_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -441,7 +455,7 @@ func appendMap_I16_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -454,7 +468,7 @@ func appendMap_I16_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -467,7 +481,7 @@ func appendMap_I16_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -480,21 +494,26 @@ func appendMap_I16_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[uint16]string)(p) {
 		n--
 		b = appendUint16(b, k)
 		b = appendUint32(b, uint32(len(v)))
-		b = append(b, v...)
+		if w == nil {
+			b = append(b, v...)
+		} else if b, err = appendStringNocopy(b, v, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_I16_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I16_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -505,9 +524,9 @@ func appendMap_I16_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 		n--
 		b = appendUint16(b, *((*uint16)(kp)))
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -516,7 +535,7 @@ func appendMap_I16_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -529,7 +548,7 @@ func appendMap_I32_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -542,7 +561,7 @@ func appendMap_I32_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -555,7 +574,7 @@ func appendMap_I32_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -568,7 +587,7 @@ func appendMap_I32_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -581,7 +600,7 @@ func appendMap_I32_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -594,21 +613,26 @@ func appendMap_I32_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[uint32]string)(p) {
 		n--
 		b = appendUint32(b, k)
 		b = appendUint32(b, uint32(len(v)))
-		b = append(b, v...)
+		if w == nil {
+			b = append(b, v...)
+		} else if b, err = appendStringNocopy(b, v, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_I32_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I32_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -619,9 +643,9 @@ func appendMap_I32_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 		n--
 		b = appendUint32(b, *((*uint32)(kp)))
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -630,7 +654,7 @@ func appendMap_I32_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -643,7 +667,7 @@ func appendMap_I64_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -656,7 +680,7 @@ func appendMap_I64_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -669,7 +693,7 @@ func appendMap_I64_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -682,7 +706,7 @@ func appendMap_I64_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -695,7 +719,7 @@ func appendMap_I64_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -708,21 +732,26 @@ func appendMap_I64_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[uint64]string)(p) {
 		n--
 		b = appendUint64(b, k)
 		b = appendUint32(b, uint32(len(v)))
-		b = append(b, v...)
+		if w == nil {
+			b = append(b, v...)
+		} else if b, err = appendStringNocopy(b, v, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_I64_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_I64_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -733,9 +762,9 @@ func appendMap_I64_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 		n--
 		b = appendUint64(b, *((*uint64)(kp)))
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -744,7 +773,7 @@ func appendMap_I64_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -757,7 +786,7 @@ func appendMap_ENUM_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -770,7 +799,7 @@ func appendMap_ENUM_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -783,7 +812,7 @@ func appendMap_ENUM_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -796,7 +825,7 @@ func appendMap_ENUM_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -809,7 +838,7 @@ func appendMap_ENUM_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -822,21 +851,26 @@ func appendMap_ENUM_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[int64]string)(p) {
 		n--
 		b = appendUint32(b, uint32(k))
 		b = appendUint32(b, uint32(len(v)))
-		b = append(b, v...)
+		if w == nil {
+			b = append(b, v...)
+		} else if b, err = appendStringNocopy(b, v, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_ENUM_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_ENUM_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -847,9 +881,9 @@ func appendMap_ENUM_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 		n--
 		b = appendUint32(b, uint32(*((*int64)(kp))))
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -858,106 +892,145 @@ func appendMap_ENUM_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[string]byte)(p) {
 		n--
 		b = appendUint32(b, uint32(len(k)))
-		b = append(b, k...)
+		if w == nil {
+			b = append(b, k...)
+		} else if b, err = appendStringNocopy(b, k, w); err != nil {
+			return b, err
+		}
 		b = append(b, v)
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[string]bool)(p) {
 		n--
 		b = appendUint32(b, uint32(len(k)))
-		b = append(b, k...)
+		if w == nil {
+			b = append(b, k...)
+		} else if b, err = appendStringNocopy(b, k, w); err != nil {
+			return b, err
+		}
 		b = appendMapBool(b, v)
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[string]uint16)(p) {
 		n--
 		b = appendUint32(b, uint32(len(k)))
-		b = append(b, k...)
+		if w == nil {
+			b = append(b, k...)
+		} else if b, err = appendStringNocopy(b, k, w); err != nil {
+			return b, err
+		}
 		b = appendUint16(b, v)
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[string]uint32)(p) {
 		n--
 		b = appendUint32(b, uint32(len(k)))
-		b = append(b, k...)
+		if w == nil {
+			b = append(b, k...)
+		} else if b, err = appendStringNocopy(b, k, w); err != nil {
+			return b, err
+		}
 		b = appendUint32(b, v)
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[string]uint64)(p) {
 		n--
 		b = appendUint32(b, uint32(len(k)))
-		b = append(b, k...)
+		if w == nil {
+			b = append(b, k...)
+		} else if b, err = appendStringNocopy(b, k, w); err != nil {
+			return b, err
+		}
 		b = appendUint64(b, v)
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[string]int64)(p) {
 		n--
 		b = appendUint32(b, uint32(len(k)))
-		b = append(b, k...)
+		if w == nil {
+			b = append(b, k...)
+		} else if b, err = appendStringNocopy(b, k, w); err != nil {
+			return b, err
+		}
 		b = appendUint32(b, uint32(v))
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
 	}
+	var err error
 	for k, v := range *(*map[string]string)(p) {
 		n--
 		b = appendUint32(b, uint32(len(k)))
-		b = append(b, k...)
+		if w == nil {
+			b = append(b, k...)
+		} else if b, err = appendStringNocopy(b, k, w); err != nil {
+			return b, err
+		}
 		b = appendUint32(b, uint32(len(v)))
-		b = append(b, v...)
+		if w == nil {
+			b = append(b, v...)
+		} else if b, err = appendStringNocopy(b, v, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_STRING_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_STRING_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -969,11 +1042,15 @@ func appendMap_STRING_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error
 		n--
 		s = *((*string)(kp))
 		b = appendUint32(b, uint32(len(s)))
-		b = append(b, s...)
+		if w == nil {
+			b = append(b, s...)
+		} else if b, err = appendStringNocopy(b, s, w); err != nil {
+			return b, err
+		}
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
@@ -982,7 +1059,7 @@ func appendMap_STRING_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_I08(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -992,9 +1069,9 @@ func appendMap_Other_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
@@ -1004,7 +1081,7 @@ func appendMap_Other_I08(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_BOOL(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -1014,9 +1091,9 @@ func appendMap_Other_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
@@ -1026,7 +1103,7 @@ func appendMap_Other_BOOL(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_I16(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -1036,9 +1113,9 @@ func appendMap_Other_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
@@ -1048,7 +1125,7 @@ func appendMap_Other_I16(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_I32(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -1058,9 +1135,9 @@ func appendMap_Other_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
@@ -1070,7 +1147,7 @@ func appendMap_Other_I32(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_I64(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -1080,9 +1157,9 @@ func appendMap_Other_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
@@ -1092,7 +1169,7 @@ func appendMap_Other_I64(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_ENUM(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -1102,9 +1179,9 @@ func appendMap_Other_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
@@ -1114,7 +1191,7 @@ func appendMap_Other_ENUM(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_STRING(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -1125,21 +1202,25 @@ func appendMap_Other_STRING(t *tType, b []byte, p unsafe.Pointer) ([]byte, error
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
 		}
 		s = *((*string)(vp))
 		b = appendUint32(b, uint32(len(s)))
-		b = append(b, s...)
+		if w == nil {
+			b = append(b, s...)
+		} else if b, err = appendStringNocopy(b, s, w); err != nil {
+			return b, err
+		}
 	}
 	return b, checkMapN(n)
 }
 
-func appendMap_Other_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+func appendMap_Other_Other(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
 	b, n := appendMapHeader(t, b, p)
 	if n == 0 {
 		return b, nil
@@ -1149,17 +1230,17 @@ func appendMap_Other_Other(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		n--
 		if t.K.IsPointer {
-			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp))
+			b, err = t.K.AppendFunc(t.K, b, *(*unsafe.Pointer)(kp), w)
 		} else {
-			b, err = t.K.AppendFunc(t.K, b, kp)
+			b, err = t.K.AppendFunc(t.K, b, kp, w)
 		}
 		if err != nil {
 			return b, err
 		}
 		if t.V.IsPointer {
-			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp))
+			b, err = t.V.AppendFunc(t.V, b, *(*unsafe.Pointer)(vp), w)
 		} else {
-			b, err = t.V.AppendFunc(t.V, b, vp)
+			b, err = t.V.AppendFunc(t.V, b, vp, w)
 		}
 		if err != nil {
 			return b, err
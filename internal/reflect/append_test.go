@@ -17,9 +17,11 @@
 package reflect
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/internal/opts"
 )
 
 func TestAppendStruct(t *testing.T) {
@@ -52,3 +54,90 @@ func TestAppendStruct(t *testing.T) {
 	assert.Nil(t, err)
 	assert.DeepEqual(t, p0, p1)
 }
+
+// nocopyWriter mocks netpoll, which inserts b before the last remainCap bytes of the buffer.
+type nocopyWriter struct {
+	offs []int
+	bufs [][]byte
+	buf  []byte
+}
+
+func (w *nocopyWriter) WriteDirect(b []byte, remainCap int) error {
+	w.offs = append(w.offs, len(w.buf)-remainCap)
+	w.bufs = append(w.bufs, b)
+	return nil
+}
+
+// Bytes returns the bytes as if they're written to the same buffer
+func (w *nocopyWriter) Bytes(n int) []byte {
+	var ret []byte
+	off := 0
+	for i, x := range w.bufs {
+		ret = append(ret, w.buf[off:w.offs[i]]...)
+		ret = append(ret, x...)
+		off = w.offs[i]
+	}
+	return append(ret, w.buf[off:n]...)
+}
+
+func TestAppendNocopy(t *testing.T) {
+	large := strings.Repeat("x", opts.NocopyWriteThreshold)
+
+	p := NewTestTypes()
+	p.String_ = large
+	p.Binary = []byte(large)
+	p.S = &Msg{Message: "small", Type: 1}
+	p.M1 = map[int32]string{1: large}
+	p.L0 = []int32{1, 2, 3}
+	p.L1 = []string{"a", large, "b"}
+	p.L2 = []*Msg{{Message: large}}
+	b, err := Append(nil, p)
+	assert.Nil(t, err)
+
+	sz := EncodedSize(p)
+	w := &nocopyWriter{buf: make([]byte, sz)}
+	ret, err := AppendNocopy(w.buf[:0:len(w.buf)], w, p)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(w.bufs))
+	assert.Equal(t, sz, len(ret)+5*len(large))
+	assert.BytesEqual(t, b, w.Bytes(len(ret)))
+
+	// smaller than the threshold
+	p.String_ = large[1:]
+	p.Binary = nil
+	p.M1 = nil
+	p.L1 = nil
+	p.L2 = nil
+	b, err = Append(nil, p)
+	assert.Nil(t, err)
+	w = &nocopyWriter{buf: make([]byte, EncodedSize(p))}
+	ret, err = AppendNocopy(w.buf[:0:len(w.buf)], w, p)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(w.bufs))
+	assert.BytesEqual(t, b, ret)
+}
+
+func TestAppendNocopyThreshold(t *testing.T) {
+	type UnknownFieldsMsg struct {
+		S string            `frugal:"1,default,string"`
+		M map[string]string `frugal:"2,default,map<string:string>"`
+
+		_unknownFields []byte
+	}
+
+	old := opts.NocopyWriteThreshold
+	defer func() { opts.NocopyWriteThreshold = old }()
+	opts.NocopyWriteThreshold = 1
+
+	// empty strings are never written by w, and unknown fields are always copied
+	p := &UnknownFieldsMsg{M: map[string]string{"k": ""}}
+	p._unknownFields = appendStringField(nil, 3, "unknown")
+	b, err := Append(nil, p)
+	assert.Nil(t, err)
+	w := &nocopyWriter{buf: make([]byte, EncodedSize(p))}
+	ret, err := AppendNocopy(w.buf[:0:len(w.buf)], w, p)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(w.bufs))
+	assert.BytesEqual(t, []byte("k"), w.bufs[0])
+	assert.BytesEqual(t, b, w.Bytes(len(ret)))
+}
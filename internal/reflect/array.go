@@ -0,0 +1,251 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"encoding/binary"
+	"io"
+	"unsafe"
+
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+// Go arrays are tBYTEARRAY for [N]byte, which are encoded as binary,
+// or tARRAY for others, which are encoded as list or set.
+// Unlike slices, the elements are stored inline, and the lengths are fixed,
+// decoding fails if the length on wire doesn't equal to the length of the array.
+
+// array returns the array type of t, t may be a pointer to array.
+func (t *tType) array() *tType {
+	if t.IsPointer {
+		return t.V
+	}
+	return t
+}
+
+func (t *tType) encodedArraySize(p unsafe.Pointer) (int, error) {
+	if t.IsPointer {
+		p = *(*unsafe.Pointer)(p)
+		t = t.V
+	}
+	if t.T == tBYTEARRAY {
+		return strHeaderLen + t.Len, nil
+	}
+	return encodedListElemsSize(t.V, p, t.Len)
+}
+
+func appendArray(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
+	t = t.array()
+	if t.T == tBYTEARRAY {
+		b = appendUint32(b, uint32(t.Len))
+		return append(b, unsafe.Slice((*byte)(p), t.Len)...), nil
+	}
+	if err := t.checkSetElems(p); err != nil {
+		return b, err
+	}
+	et := t.V
+	n := uint32(t.Len)
+	b = append(b, byte(et.WT), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	var err error
+	for i := 0; i < t.Len; i++ {
+		if b, err = appendAny(et, b, unsafe.Add(p, i*et.Size), w); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+func (d *tDecoder) decodeArray(t *tType, b []byte, p unsafe.Pointer, maxdepth int) (int, error) {
+	t = t.array()
+	if t.T == tBYTEARRAY {
+		if len(b) < strHeaderLen {
+			return 0, io.ErrShortBuffer
+		}
+		l := int(int32(binary.BigEndian.Uint32(b)))
+		if l != t.Len {
+			return 0, newArrayLenMismatch(t, l)
+		}
+		if l > len(b)-strHeaderLen {
+			return strHeaderLen, newSizeExceedsBufferException(l, len(b)-strHeaderLen)
+		}
+		copy(unsafe.Slice((*byte)(p), l), b[strHeaderLen:])
+		return strHeaderLen + l, nil
+	}
+
+	// list header
+	if len(b) < listHeaderLen {
+		return 0, io.ErrShortBuffer
+	}
+	tp, l := ttype(b[0]), int(int32(binary.BigEndian.Uint32(b[1:])))
+	i := listHeaderLen
+	if err := checkArrayHeader(t, tp, l, b[i:], &minWireSize); err != nil {
+		return i, err
+	}
+	et := t.V
+	for j := 0; j < l; j++ {
+		vp := d.mallocIfPointer(et, unsafe.Add(p, j*et.Size))
+		if et.decodeFast() {
+			i += decodeFixedSizeTypes(et.T, b[i:], vp)
+		} else {
+			n, err := d.decodeType(et, b[i:], vp, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+	}
+	return i, t.checkSetElems(p)
+}
+
+func appendCompactArray(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+	t = t.array()
+	if t.T == tBYTEARRAY {
+		b = appendVarint(b, uint64(t.Len))
+		return append(b, unsafe.Slice((*byte)(p), t.Len)...), nil
+	}
+	if err := t.checkSetElems(p); err != nil {
+		return b, err
+	}
+	et := t.V
+	b = appendCompactListHeader(b, ttype2ctype[et.WT], t.Len)
+	var err error
+	for i := 0; i < t.Len; i++ {
+		if b, err = appendCompactAny(et, b, unsafe.Add(p, i*et.Size)); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+func compactArraySize(t *tType, p unsafe.Pointer) (int, error) {
+	t = t.array()
+	if t.T == tBYTEARRAY {
+		return varintSize(uint64(t.Len)) + t.Len, nil
+	}
+	et := t.V
+	ret := compactListHeaderSize(t.Len)
+	for i := 0; i < t.Len; i++ {
+		n, err := compactSizeAny(et, unsafe.Add(p, i*et.Size))
+		if err != nil {
+			return ret, err
+		}
+		ret += n
+	}
+	return ret, nil
+}
+
+func (d *tDecoder) decodeCompactArray(t *tType, b []byte, p unsafe.Pointer, maxdepth int) (int, error) {
+	t = t.array()
+	if t.T == tBYTEARRAY {
+		l, i, err := readCompactSize(b)
+		if err != nil {
+			return i, err
+		}
+		if l != t.Len {
+			return i, newArrayLenMismatch(t, l)
+		}
+		if l > len(b)-i {
+			return i, newSizeExceedsBufferException(l, len(b)-i)
+		}
+		copy(unsafe.Slice((*byte)(p), l), b[i:])
+		return i + l, nil
+	}
+	tp, l, i, err := readCompactListHeader(b)
+	if err != nil {
+		return i, err
+	}
+	if err := checkArrayHeader(t, tp, l, b[i:], &compactMinWireSize); err != nil {
+		return i, err
+	}
+	et := t.V
+	for j := 0; j < l; j++ {
+		vp := d.mallocIfPointer(et, unsafe.Add(p, j*et.Size))
+		n, err := d.decodeCompactType(et, b[i:], vp, maxdepth-1)
+		if err != nil {
+			return i, err
+		}
+		i += n
+	}
+	return i, t.checkSetElems(p)
+}
+
+func (e *streamEncoder) encodeArray(t *tType, p unsafe.Pointer) error {
+	t = t.array()
+	if t.T == tBYTEARRAY {
+		if err := e.ensure(strHeaderLen); err != nil {
+			return err
+		}
+		e.b = appendUint32(e.b, uint32(t.Len))
+		if t.Len == 0 {
+			return nil
+		}
+		return e.writeString(unsafe.String((*byte)(p), t.Len))
+	}
+	if err := t.checkSetElems(p); err != nil {
+		return err
+	}
+	et := t.V
+	if err := e.ensure(listHeaderLen); err != nil {
+		return err
+	}
+	n := uint32(t.Len)
+	e.b = append(e.b, byte(et.WT), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	for i := 0; i < t.Len; i++ {
+		if err := e.encodeAny(et, unsafe.Add(p, i*et.Size)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *streamDecoder) decodeArray(t *tType, p unsafe.Pointer, maxdepth int) error {
+	t = t.array()
+	if t.T == tBYTEARRAY {
+		x, err := d.next(strHeaderLen)
+		if err != nil {
+			return err
+		}
+		if l := int(int32(binary.BigEndian.Uint32(x))); l != t.Len {
+			return newArrayLenMismatch(t, l)
+		}
+		if t.Len == 0 {
+			return nil
+		}
+		x, err = d.readBytes(t.Len)
+		if err != nil {
+			return err
+		}
+		copy(unsafe.Slice((*byte)(p), t.Len), x)
+		return nil
+	}
+	x, err := d.next(listHeaderLen)
+	if err != nil {
+		return err
+	}
+	tp, l := ttype(x[0]), int(int32(binary.BigEndian.Uint32(x[1:])))
+	if err := checkArrayHeader(t, tp, l, nil, nil); err != nil {
+		return err
+	}
+	et := t.V
+	for j := 0; j < l; j++ {
+		vp := d.mallocIfPointer(et, unsafe.Add(p, j*et.Size))
+		if err = d.decodeType(et, vp, maxdepth-1); err != nil {
+			return err
+		}
+	}
+	return t.checkSetElems(p)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+type ArrayElem struct {
+	X int32 `frugal:"1,default,i32"`
+}
+
+type ArrayTypes struct {
+	UUID  [16]byte             `frugal:"1,default,binary"`
+	Opt   *[4]byte             `frugal:"2,optional,binary"`
+	Coord [3]float64           `frugal:"3,default"`
+	Set   [2]string            `frugal:"4,default,set<string>"`
+	Keys  map[[2]int32]string  `frugal:"5,optional,map<list<i32>:string>"`
+	Elems [2]*ArrayElem        `frugal:"6,default,list<ArrayElem>"`
+	Grid  [2][2]int16          `frugal:"7,default,list<list<i16>>"`
+	Bins  [][2]byte            `frugal:"8,default,list<binary>"`
+	Empty [0]int64             `frugal:"9,default"`
+	Opt2  *[2]float32          `frugal:"10,optional,list<double>"`
+	Vals  map[string][3]uint16 `frugal:"11,default,map<string:list<i16>>,unsigned"`
+}
+
+type SliceTypes struct {
+	UUID  []byte              `frugal:"1,default,binary"`
+	Opt   []byte              `frugal:"2,optional,binary"`
+	Coord []float64           `frugal:"3,default,list<double>"`
+	Set   []string            `frugal:"4,default,set<string>"`
+	Elems []*ArrayElem        `frugal:"6,default,list<ArrayElem>"`
+	Grid  [][]int16           `frugal:"7,default,list<list<i16>>"`
+	Bins  [][]byte            `frugal:"8,default,list<binary>"`
+	Empty []int64             `frugal:"9,default,list<i64>"`
+	Opt2  []float64           `frugal:"10,optional,list<double>"`
+	Vals  map[string][]uint16 `frugal:"11,default,map<string:list<i16>>,unsigned"`
+}
+
+func TestArray(t *testing.T) {
+	p0 := &ArrayTypes{
+		UUID:  [16]byte{1, 2, 3, 15: 16},
+		Opt:   &[4]byte{4, 3, 2, 1},
+		Coord: [3]float64{1.5, -2, 3},
+		Set:   [2]string{"a", "b"},
+		Elems: [2]*ArrayElem{{X: 1}, {X: 2}},
+		Grid:  [2][2]int16{{1, 2}, {3, 4}},
+		Bins:  [][2]byte{{1, 2}},
+		Opt2:  &[2]float32{0.5, 1},
+		Vals:  map[string][3]uint16{"x": {1, 2, 65535}},
+	}
+	s0 := &SliceTypes{
+		UUID:  []byte{1, 2, 3, 15: 16},
+		Opt:   []byte{4, 3, 2, 1},
+		Coord: []float64{1.5, -2, 3},
+		Set:   []string{"a", "b"},
+		Elems: []*ArrayElem{{X: 1}, {X: 2}},
+		Grid:  [][]int16{{1, 2}, {3, 4}},
+		Bins:  [][]byte{{1, 2}},
+		Empty: []int64{},
+		Opt2:  []float64{0.5, 1},
+		Vals:  map[string][]uint16{"x": {1, 2, 65535}},
+	}
+
+	// the same as slices on wire
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	expect, err := Append(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	p1 := &ArrayTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	buf := &bytes.Buffer{}
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, b, buf.Bytes())
+	p1 = &ArrayTypes{}
+	_, err = DecodeFrom(buf, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	p1 = &ArrayTypes{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	// arrays as map keys
+	p0 = &ArrayTypes{Keys: map[[2]int32]string{{1, 2}: "a", {3, 4}: "b"}}
+	b, err = Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	p1 = &ArrayTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0.Keys, p1.Keys)
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	p1 = &ArrayTypes{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0.Keys, p1.Keys)
+}
+
+func TestArrayLenMismatch(t *testing.T) {
+	for _, s := range []*SliceTypes{
+		{UUID: make([]byte, 15)},
+		{Opt: make([]byte, 5)},
+		{Coord: []float64{1, 2}},
+		{Grid: [][]int16{{1, 2}, {3}}},
+		{Bins: [][]byte{{1}}},
+		{Opt2: []float64{1, 2, 3}},
+	} {
+		if s.UUID == nil {
+			s.UUID = make([]byte, 16)
+		}
+		if s.Coord == nil {
+			s.Coord = make([]float64, 3)
+		}
+		if s.Grid == nil {
+			s.Grid = [][]int16{{1, 2}, {3, 4}}
+		}
+		s.Set = make([]string, 2)
+		s.Elems = []*ArrayElem{{}, {}}
+
+		b, err := Append(nil, s)
+		assert.Nil(t, err)
+		_, err = Decode(b, &ArrayTypes{})
+		assert.True(t, err != nil && strings.Contains(err.Error(), "array length mismatch"), err)
+		_, err = DecodeFrom(bytes.NewReader(b), &ArrayTypes{})
+		assert.True(t, err != nil && strings.Contains(err.Error(), "array length mismatch"), err)
+
+		b, err = AppendCompact(nil, s)
+		assert.Nil(t, err)
+		_, err = DecodeCompact(b, &ArrayTypes{})
+		assert.True(t, err != nil && strings.Contains(err.Error(), "array length mismatch"), err)
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/defs"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+// Codec is a custom codec of a Go type, the value is encoded as a Thrift scalar type.
+// The funcs work with Thrift binary protocol, values are converted for compact protocol.
+type Codec struct {
+	// Append appends the value p points to, including the length header for strings.
+	Append func(b []byte, p unsafe.Pointer) ([]byte, error)
+
+	// Decode decodes the value from b to the value p points to, and returns the number of bytes read.
+	Decode func(b []byte, p unsafe.Pointer) (int, error)
+
+	// Size returns the encoded size of the value p points to.
+	Size func(p unsafe.Pointer) int
+}
+
+var codecs = map[reflect.Type]*Codec{} // protected by sdsmu
+
+// RegisterCodec registers a custom codec of rt which is encoded as the Thrift type wt, see defs.RegisterCodec.
+func RegisterCodec(rt reflect.Type, wt defs.Tag, c *Codec) error {
+	sdsmu.Lock()
+	defer sdsmu.Unlock()
+	if err := defs.RegisterCodec(rt, wt); err != nil {
+		return err
+	}
+	codecs[rt] = c
+	return nil
+}
+
+func (t *tType) encodedCodecSize(p unsafe.Pointer) (int, error) {
+	if t.IsPointer {
+		p = *(*unsafe.Pointer)(p)
+	}
+	return t.Codec.Size(p), nil
+}
+
+func decodeCodec(t *tType, b []byte, p unsafe.Pointer) (int, error) {
+	if len(b) < int(minWireSize[t.WT]) {
+		return 0, io.ErrShortBuffer
+	}
+	n := int(typeToSize[t.WT])
+	if t.WT == tSTRING {
+		l := int(int32(binary.BigEndian.Uint32(b)))
+		if l < 0 {
+			return 0, errNegativeSize
+		}
+		if l > len(b)-strHeaderLen {
+			return 0, newSizeExceedsBufferException(l, len(b)-strHeaderLen)
+		}
+		n = strHeaderLen + l
+	}
+	return n, decodeCodecValue(t, b[:n], p)
+}
+
+// decodeCodecValue decodes the value x in binary protocol, including the length header for strings,
+// and checks if all bytes of x are read like appendCodec checks the bytes appended.
+func decodeCodecValue(t *tType, x []byte, p unsafe.Pointer) error {
+	n, err := t.Codec.Decode(x, p)
+	if err != nil {
+		return err
+	}
+	if n != len(x) {
+		return fmt.Errorf("custom codec of %s decoded %d bytes, not a valid %s of %d bytes", t.RT, n, ttype2str(t.WT), len(x))
+	}
+	return nil
+}
+
+// appendCodec appends the value in binary protocol, and checks if it's a valid value of the wire type.
+func appendCodec(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
+	x, err := t.Codec.Append(b, p)
+	if err != nil {
+		return x, err
+	}
+	n := len(x) - len(b)
+	if n < int(minWireSize[t.WT]) || t.WT != tSTRING && n != int(typeToSize[t.WT]) ||
+		t.WT == tSTRING && n != strHeaderLen+int(binary.BigEndian.Uint32(x[len(b):])) {
+		return x, fmt.Errorf("custom codec of %s appended %d bytes, not a valid %s", t.RT, n, ttype2str(t.WT))
+	}
+	return x, nil
+}
+
+func appendCompactCodec(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+	var buf [8]byte
+	x, err := appendCodec(t, buf[:0], p, nil)
+	if err != nil {
+		return b, err
+	}
+	switch t.WT {
+	case tBYTE:
+		return append(b, x[0]), nil
+	case tI16:
+		return appendVarint(b, uint64(zigzag32(int32(int16(binary.BigEndian.Uint16(x)))))), nil
+	case tI32:
+		return appendVarint(b, uint64(zigzag32(int32(binary.BigEndian.Uint32(x))))), nil
+	case tI64:
+		return appendVarint(b, zigzag64(int64(binary.BigEndian.Uint64(x)))), nil
+	case tDOUBLE:
+		return binary.LittleEndian.AppendUint64(b, binary.BigEndian.Uint64(x)), nil
+	default: // tSTRING
+		b = appendVarint(b, uint64(len(x)-strHeaderLen))
+		return append(b, x[strHeaderLen:]...), nil
+	}
+}
+
+func compactCodecSize(t *tType, p unsafe.Pointer) (int, error) {
+	switch t.WT {
+	case tBYTE, tDOUBLE:
+		return int(typeToSize[t.WT]), nil
+	case tSTRING:
+		n := t.Codec.Size(p) - strHeaderLen
+		return varintSize(uint64(n)) + n, nil
+	}
+	var buf [10]byte
+	x, err := appendCompactCodec(t, buf[:0], p)
+	return len(x), err
+}
+
+func decodeCompactCodec(t *tType, b []byte, p unsafe.Pointer) (int, error) {
+	var buf [8]byte
+	var x []byte
+	var n int
+	switch t.WT {
+	case tBYTE:
+		if len(b) < 1 {
+			return 0, io.ErrShortBuffer
+		}
+		x, n = b[:1], 1
+	case tI16, tI32:
+		v, m, err := readZigzag32(b, t.WT)
+		if err != nil {
+			return m, err
+		}
+		if t.WT == tI16 {
+			x = appendUint16(buf[:0], uint16(v))
+		} else {
+			x = appendUint32(buf[:0], uint32(v))
+		}
+		n = m
+	case tI64:
+		v, m, err := readVarint(b)
+		if err != nil {
+			return m, err
+		}
+		x, n = appendUint64(buf[:0], uint64(unzigzag64(v))), m
+	case tDOUBLE:
+		if len(b) < 8 {
+			return 0, io.ErrShortBuffer
+		}
+		x, n = appendUint64(buf[:0], binary.LittleEndian.Uint64(b)), 8
+	default: // tSTRING
+		l, i, err := readCompactSize(b)
+		if err != nil {
+			return i, err
+		}
+		if l > len(b)-i {
+			return i, newSizeExceedsBufferException(l, len(b)-i)
+		}
+		x = appendUint32(make([]byte, 0, strHeaderLen+l), uint32(l))
+		x, n = append(x, b[i:i+l]...), i+l
+	}
+	return n, decodeCodecValue(t, x, p)
+}
+
+func (e *streamEncoder) encodeCodec(t *tType, p unsafe.Pointer) error {
+	n := t.Codec.Size(p)
+	ok, err := e.fits(n)
+	if err != nil {
+		return err
+	}
+	if ok {
+		e.b, err = appendCodec(t, e.b, p, nil)
+		return err
+	}
+	b, err := appendCodec(t, make([]byte, 0, n), p, nil)
+	if err != nil {
+		return err
+	}
+	return e.writeString(unsafe.String(&b[0], len(b)))
+}
+
+func (d *streamDecoder) decodeCodec(t *tType, p unsafe.Pointer) error {
+	var x []byte
+	var err error
+	if t.WT != tSTRING {
+		if x, err = d.next(int(typeToSize[t.WT])); err != nil {
+			return err
+		}
+	} else {
+		if x, err = d.next(strHeaderLen); err != nil {
+			return err
+		}
+		l := int(int32(binary.BigEndian.Uint32(x)))
+		if l < 0 {
+			return errNegativeSize
+		}
+		s, err := d.readBytes(l)
+		if err != nil {
+			return err
+		}
+		x = appendUint32(make([]byte, 0, strHeaderLen+l), uint32(l))
+		x = append(x, s...)
+	}
+	return decodeCodecValue(t, x, p)
+}
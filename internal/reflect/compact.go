@@ -0,0 +1,420 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// Thrift Compact Protocol, see:
+// https://github.com/apache/thrift/blob/master/doc/specs/thrift-compact-protocol.md
+//
+// It shares structDesc and tType with the Binary Protocol,
+// only the way of reading and writing values is different.
+
+type ctype uint8
+
+const (
+	ctSTOP          ctype = 0x00
+	ctBOOLEAN_TRUE  ctype = 0x01
+	ctBOOLEAN_FALSE ctype = 0x02
+	ctBYTE          ctype = 0x03
+	ctI16           ctype = 0x04
+	ctI32           ctype = 0x05
+	ctI64           ctype = 0x06
+	ctDOUBLE        ctype = 0x07
+	ctBINARY        ctype = 0x08
+	ctLIST          ctype = 0x09
+	ctSET           ctype = 0x0a
+	ctMAP           ctype = 0x0b
+	ctSTRUCT        ctype = 0x0c
+	ctUUID          ctype = 0x0d
+)
+
+// ttype2ctype maps wire types to compact types.
+// for tBOOL, the element type of containers is always ctBOOLEAN_TRUE,
+// the type of a field header depends on the value.
+var ttype2ctype = [256]ctype{
+	tBOOL:   ctBOOLEAN_TRUE,
+	tBYTE:   ctBYTE,
+	tI16:    ctI16,
+	tI32:    ctI32,
+	tI64:    ctI64,
+	tDOUBLE: ctDOUBLE,
+	tSTRING: ctBINARY,
+	tSTRUCT: ctSTRUCT,
+	tMAP:    ctMAP,
+	tSET:    ctSET,
+	tLIST:   ctLIST,
+	wUUID:   ctUUID,
+}
+
+// ctype2ttype maps compact types to wire types, tSTOP for invalid types.
+var ctype2ttype = [16]ttype{
+	ctBOOLEAN_TRUE:  tBOOL,
+	ctBOOLEAN_FALSE: tBOOL,
+	ctBYTE:          tBYTE,
+	ctI16:           tI16,
+	ctI32:           tI32,
+	ctI64:           tI64,
+	ctDOUBLE:        tDOUBLE,
+	ctBINARY:        tSTRING,
+	ctLIST:          tLIST,
+	ctSET:           tSET,
+	ctMAP:           tMAP,
+	ctSTRUCT:        tSTRUCT,
+	ctUUID:          wUUID,
+}
+
+func zigzag32(v int32) uint32 { return uint32(v<<1) ^ uint32(v>>31) }
+func zigzag64(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+
+func unzigzag32(v uint32) int32 { return int32(v>>1) ^ -int32(v&1) }
+func unzigzag64(v uint64) int64 { return int64(v>>1) ^ -int64(v&1) }
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func varintSize(v uint64) int {
+	n := 1
+	for v >= 0x80 {
+		v >>= 7
+		n++
+	}
+	return n
+}
+
+func appendCompactFieldHeader(b []byte, ct ctype, id, lastID int16) []byte {
+	if id > lastID && id-lastID <= 15 {
+		return append(b, byte(id-lastID)<<4|byte(ct))
+	}
+	b = append(b, byte(ct))
+	return appendVarint(b, uint64(zigzag32(int32(id))))
+}
+
+func compactFieldHeaderSize(id, lastID int16) int {
+	if id > lastID && id-lastID <= 15 {
+		return 1
+	}
+	return 1 + varintSize(uint64(zigzag32(int32(id))))
+}
+
+func appendCompactListHeader(b []byte, et ctype, n int) []byte {
+	if n <= 14 {
+		return append(b, byte(n)<<4|byte(et))
+	}
+	b = append(b, 0xf0|byte(et))
+	return appendVarint(b, uint64(n))
+}
+
+func compactListHeaderSize(n int) int {
+	if n <= 14 {
+		return 1
+	}
+	return 1 + varintSize(uint64(n))
+}
+
+func appendCompactMapHeader(b []byte, kt, vt ctype, n int) []byte {
+	if n == 0 {
+		return append(b, 0)
+	}
+	b = appendVarint(b, uint64(n))
+	return append(b, byte(kt)<<4|byte(vt))
+}
+
+func compactMapHeaderSize(n int) int {
+	if n == 0 {
+		return 1
+	}
+	return varintSize(uint64(n)) + 1
+}
+
+func appendCompactStruct(t *tType, b []byte, base unsafe.Pointer) ([]byte, error) {
+	sd := t.Sd
+	if err := sd.checkEncode(base); err != nil {
+		return b, err
+	}
+	if base == nil {
+		return append(b, byte(ctSTOP)), nil
+	}
+	var err error
+	lastID := int16(0)
+	for _, f := range sd.fields {
+		p := f.encodedPointer(base)
+		if p == nil {
+			continue
+		}
+		if err := sd.checkEncodedField(f, p); err != nil {
+			return b, err
+		}
+		t := f.Type
+		id := int16(f.ID)
+		if t.WT == tBOOL { // bool values are encoded in the field header
+			if t.IsPointer {
+				p = *(*unsafe.Pointer)(p)
+			}
+			ct := ctBOOLEAN_FALSE
+			if *(*bool)(p) {
+				ct = ctBOOLEAN_TRUE
+			}
+			b = appendCompactFieldHeader(b, ct, id, lastID)
+			lastID = id
+			continue
+		}
+		b = appendCompactFieldHeader(b, ttype2ctype[t.WT], id, lastID)
+		lastID = id
+		b, err = appendCompactAny(t, b, p)
+		if err != nil {
+			return b, withFieldErr(err, sd, f)
+		}
+	}
+	// unknown fields are always saved with long form field headers by the decoder,
+	// it's safe to append them as is no matter what lastID is.
+	b = append(b, sd.getUnknownFields(base)...)
+	return append(b, byte(ctSTOP)), nil
+}
+
+// appendCompactAny is the compact version of appendAny,
+// p points to the value, or a pointer to the value if t.IsPointer.
+func appendCompactAny(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+	if t.IsPointer {
+		p = *(*unsafe.Pointer)(p)
+	}
+	switch t.T {
+	case tBOOL:
+		if *(*bool)(p) {
+			return append(b, byte(ctBOOLEAN_TRUE)), nil
+		}
+		return append(b, byte(ctBOOLEAN_FALSE)), nil
+	case tBYTE:
+		return append(b, *(*byte)(p)), nil
+	case tI16:
+		return appendVarint(b, uint64(zigzag32(int32(*(*int16)(p))))), nil
+	case tI32:
+		return appendVarint(b, uint64(zigzag32(*(*int32)(p)))), nil
+	case tENUM:
+		return appendVarint(b, uint64(zigzag32(int32(*(*int64)(p))))), nil
+	case tI64:
+		return appendVarint(b, zigzag64(*(*int64)(p))), nil
+	case tDOUBLE:
+		v := math.Float64bits(*(*float64)(p))
+		return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+			byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56)), nil
+	case tFLOAT:
+		v := math.Float64bits(float64(*(*float32)(p)))
+		return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+			byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56)), nil
+	case tSTRING:
+		s := *(*string)(p) // also works for []byte
+		b = appendVarint(b, uint64(len(s)))
+		return append(b, s...), nil
+	case tUUID:
+		return append(b, (*[16]byte)(p)[:]...), nil
+	case tSTRUCT:
+		return appendCompactStruct(t, b, p)
+	case tMAP:
+		return appendCompactMap(t, b, p)
+	case tLIST, tSET:
+		return appendCompactList(t, b, p)
+	case tARRAY, tBYTEARRAY:
+		return appendCompactArray(t, b, p)
+	case tCODEC:
+		return appendCompactCodec(t, b, p)
+	case tMAPSET:
+		return appendCompactMapSet(t, b, p)
+	case tPOINTER:
+		return appendCompactAny(t.V, b, p)
+	}
+	return b, fmt.Errorf("unknown type: %d", t.T)
+}
+
+func appendCompactList(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+	if err := t.checkSetElems(p); err != nil {
+		return b, err
+	}
+	et := t.V
+	h := (*sliceHeader)(p)
+	if h.Data == nil {
+		return appendCompactListHeader(b, ttype2ctype[et.WT], 0), nil
+	}
+	b = appendCompactListHeader(b, ttype2ctype[et.WT], h.Len)
+	var err error
+	vp := h.Data
+	for i := 0; i < h.Len; i++ {
+		if i != 0 {
+			vp = unsafe.Add(vp, et.Size) // move to next element
+		}
+		if b, err = appendCompactAny(et, b, vp); err != nil {
+			return b, err
+		}
+	}
+	return b, nil
+}
+
+func appendCompactMap(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+	var n int
+	if *(*unsafe.Pointer)(p) != nil {
+		n = maplen(*(*unsafe.Pointer)(p))
+	}
+	b = appendCompactMapHeader(b, ttype2ctype[t.K.WT], ttype2ctype[t.V.WT], n)
+	if n == 0 {
+		return b, nil
+	}
+	var err error
+	it := newMapIter(rvWithPtr(t.RV, p))
+	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
+		n--
+		if b, err = appendCompactAny(t.K, b, kp); err != nil {
+			return b, err
+		}
+		if b, err = appendCompactAny(t.V, b, vp); err != nil {
+			return b, err
+		}
+	}
+	return b, checkMapN(uint32(n))
+}
+
+// compactEncodedSize returns the compact encoded size of a struct.
+func compactEncodedSize(t *tType, base unsafe.Pointer) (int, error) {
+	sd := t.Sd
+	if base == nil {
+		return 1, nil // ctSTOP
+	}
+	ret := 0
+	lastID := int16(0)
+	for _, f := range sd.fields {
+		p := f.encodedPointer(base)
+		if p == nil {
+			continue
+		}
+		t := f.Type
+		id := int16(f.ID)
+		ret += compactFieldHeaderSize(id, lastID)
+		lastID = id
+		if t.WT == tBOOL {
+			continue // encoded in the field header
+		}
+		n, err := compactSizeAny(t, p)
+		if err != nil {
+			return ret, err
+		}
+		ret += n
+	}
+	ret += len(sd.getUnknownFields(base))
+	return ret + 1, nil // ctSTOP
+}
+
+// compactSizeAny returns the compact encoded size of the value p points to.
+// it follows the same convention of appendCompactAny.
+func compactSizeAny(t *tType, p unsafe.Pointer) (int, error) {
+	if t.IsPointer {
+		p = *(*unsafe.Pointer)(p)
+	}
+	switch t.T {
+	case tBOOL, tBYTE:
+		return 1, nil
+	case tI16:
+		return varintSize(uint64(zigzag32(int32(*(*int16)(p))))), nil
+	case tI32:
+		return varintSize(uint64(zigzag32(*(*int32)(p)))), nil
+	case tENUM:
+		return varintSize(uint64(zigzag32(int32(*(*int64)(p))))), nil
+	case tI64:
+		return varintSize(zigzag64(*(*int64)(p))), nil
+	case tDOUBLE, tFLOAT:
+		return 8, nil
+	case tUUID:
+		return 16, nil
+	case tSTRING:
+		n := len(*(*string)(p))
+		return varintSize(uint64(n)) + n, nil
+	case tSTRUCT:
+		return compactEncodedSize(t, p)
+	case tMAP:
+		return compactMapSize(t, p)
+	case tLIST, tSET:
+		return compactListSize(t, p)
+	case tARRAY, tBYTEARRAY:
+		return compactArraySize(t, p)
+	case tCODEC:
+		return compactCodecSize(t, p)
+	case tMAPSET:
+		return compactMapSetSize(t, p)
+	case tPOINTER:
+		return compactSizeAny(t.V, p)
+	}
+	return 0, fmt.Errorf("unknown type: %d", t.T)
+}
+
+func compactListSize(t *tType, p unsafe.Pointer) (int, error) {
+	et := t.V
+	h := (*sliceHeader)(p)
+	if h.Data == nil || h.Len == 0 {
+		return compactListHeaderSize(0), nil
+	}
+	ret := compactListHeaderSize(h.Len)
+	switch et.T {
+	case tBOOL, tBYTE:
+		return ret + h.Len, nil // fast path
+	case tDOUBLE, tFLOAT:
+		return ret + h.Len*8, nil // fast path
+	}
+	vp := h.Data
+	for i := 0; i < h.Len; i++ {
+		if i != 0 {
+			vp = unsafe.Add(vp, et.Size) // move to next element
+		}
+		n, err := compactSizeAny(et, vp)
+		if err != nil {
+			return ret, err
+		}
+		ret += n
+	}
+	return ret, nil
+}
+
+func compactMapSize(t *tType, p unsafe.Pointer) (int, error) {
+	if *(*unsafe.Pointer)(p) == nil {
+		return compactMapHeaderSize(0), nil
+	}
+	l := maplen(*(*unsafe.Pointer)(p))
+	ret := compactMapHeaderSize(l)
+	if l == 0 {
+		return ret, nil
+	}
+	it := newMapIter(rvWithPtr(t.RV, p))
+	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
+		n, err := compactSizeAny(t.K, kp)
+		if err != nil {
+			return ret, err
+		}
+		ret += n
+		n, err = compactSizeAny(t.V, vp)
+		if err != nil {
+			return ret, err
+		}
+		ret += n
+	}
+	return ret, nil
+}
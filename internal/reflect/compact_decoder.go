@@ -0,0 +1,578 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// compactMinWireSize is the compact version of minWireSize.
+// varints take at least one byte.
+var compactMinWireSize = [256]int8{
+	tBOOL:   1,
+	tBYTE:   1,
+	tI16:    1,
+	tI32:    1,
+	tI64:    1,
+	tDOUBLE: 8,
+	tSTRING: 1, // length header only, content may be empty
+	tSTRUCT: 1, // ctSTOP only
+	tMAP:    1, // zero-len map has no types
+	tSET:    1, // size and type in one byte
+	tLIST:   1, // size and type in one byte
+	wUUID:   16,
+}
+
+func readVarint(b []byte) (uint64, int, error) {
+	v, n := binary.Uvarint(b)
+	if n == 0 {
+		return 0, 0, io.ErrShortBuffer
+	}
+	if n < 0 {
+		return 0, 0, errVarintOverflow
+	}
+	return v, n, nil
+}
+
+// readZigzag32 reads a zigzag varint of type t, which is tI16 or tI32,
+// values out of the range of t are rejected instead of being truncated.
+func readZigzag32(b []byte, t ttype) (int32, int, error) {
+	v, n, err := readVarint(b)
+	if err != nil {
+		return 0, n, err
+	}
+	if v > math.MaxUint32 || t == tI16 && v > math.MaxUint16 {
+		return 0, n, newVarintOverflowException(t, v)
+	}
+	return unzigzag32(uint32(v)), n, nil
+}
+
+// readCompactSize reads a varint which is used as a string length or a container size.
+func readCompactSize(b []byte) (int, int, error) {
+	v, n, err := readVarint(b)
+	if err != nil {
+		return 0, n, err
+	}
+	if v > math.MaxInt32 {
+		return 0, n, errNegativeSize
+	}
+	return int(v), n, nil
+}
+
+// readCompactListHeader reads the element type and the size of a list or set,
+// it returns the number of bytes read.
+func readCompactListHeader(b []byte) (ttype, int, int, error) {
+	if len(b) < 1 {
+		return 0, 0, 0, io.ErrShortBuffer
+	}
+	tp := ctype2ttype[b[0]&0x0f]
+	l := int(b[0] >> 4)
+	i := 1
+	if l == 15 { // long form, the size follows as a varint
+		n, m, err := readCompactSize(b[i:])
+		if err != nil {
+			return tp, 0, i, err
+		}
+		l = n
+		i += m
+	}
+	return tp, l, i, nil
+}
+
+func (d *tDecoder) DecodeCompact(b []byte, base unsafe.Pointer, sd *structDesc, maxdepth int) (int, error) {
+	if maxdepth == 0 {
+		return 0, errDepthLimitExceeded
+	}
+	bs := sd.newRequiredBitset()
+	if bs != nil {
+		defer bitsetPool.Put(bs)
+	}
+
+	// unknown fields with long form field headers,
+	// coz short form headers depend on the previous field id.
+	var ufs []byte
+
+	i := 0
+	lastID := int16(0)
+	nfields := 0 // declared fields decoded, for unions
+	for {
+		if i >= len(b) {
+			return i, io.ErrShortBuffer
+		}
+		start := i
+		ct := ctype(b[i] & 0x0f)
+		delta := int16(b[i] >> 4)
+		i++
+		if ct == ctSTOP {
+			break
+		}
+		tp := ctype2ttype[ct]
+		if tp == tSTOP {
+			return i, newUnknownCompactType(ct)
+		}
+		if delta != 0 {
+			lastID += delta
+		} else {
+			v, n, err := readZigzag32(b[i:], tI16)
+			if err != nil {
+				return i, err
+			}
+			i += n
+			lastID = int16(v)
+		}
+		fid := uint16(lastID)
+
+		f := sd.GetField(fid)
+		if f == nil || f.Type.WT != tp {
+			if err := d.checkSkipped(sd, f, fid, tp, d.offsetOf(b[start:])); err != nil {
+				return i, err
+			}
+			n := 0 // bool fields have no payload
+			if tp != tBOOL {
+				var err error
+				n, err = skipCompact(b[i:], ct, maxdepth-1)
+				if err != nil {
+					return i, sd.skipFieldErr(fid, err)
+				}
+			}
+			if sd.hasUnknownFields {
+				ufs = append(ufs, byte(ct))
+				ufs = appendVarint(ufs, uint64(zigzag32(int32(lastID))))
+				ufs = append(ufs, b[i:i+n]...)
+			}
+			i += n
+			continue
+		}
+		nfields++
+		t := f.Type
+		p := d.fieldPointer(f, base)
+		if tp == tBOOL {
+			*(*bool)(p) = ct == ctBOOLEAN_TRUE
+		} else {
+			var n int
+			var err error
+			if f.NoCopy {
+				n, err = d.decodeCompactStringNoCopy(t, b[i:], p)
+			} else {
+				n, err = d.decodeCompactType(t, b[i:], p, maxdepth-1)
+			}
+			if err != nil {
+				return i, sd.decodeFieldErr(fid, err)
+			}
+			i += n
+		}
+		if bs != nil {
+			bs.set(f.ID)
+		}
+	}
+	if err := sd.checkDecoded(bs, nfields); err != nil {
+		return i, err
+	}
+	if len(ufs) > 0 {
+		sd.setUnknownFields(base, ufs)
+	}
+	return i, nil
+}
+
+func (d *tDecoder) decodeCompactStringNoCopy(t *tType, b []byte, p unsafe.Pointer) (int, error) {
+	l, i, err := readCompactSize(b)
+	if err != nil {
+		return i, err
+	}
+	if l == 0 {
+		setString(t, p, nil, 0)
+		return i, nil
+	}
+	if l > len(b)-i {
+		return i, newSizeExceedsBufferException(l, len(b)-i)
+	}
+	if err := d.checkStringSize(l); err != nil {
+		return i, err
+	}
+	setString(t, p, &b[i], l)
+	return i + l, nil
+}
+
+func (d *tDecoder) decodeCompactType(t *tType, b []byte, p unsafe.Pointer, maxdepth int) (int, error) {
+	if maxdepth == 0 {
+		return 0, errDepthLimitExceeded
+	}
+	switch t.T {
+	case tBOOL, tBYTE:
+		if len(b) < 1 {
+			return 0, io.ErrShortBuffer
+		}
+		if t.T == tBOOL {
+			*(*bool)(p) = ctype(b[0]) == ctBOOLEAN_TRUE
+		} else {
+			*(*byte)(p) = b[0]
+		}
+		return 1, nil
+
+	case tI16, tI32, tENUM:
+		x, n, err := readZigzag32(b, t.WT)
+		if err != nil {
+			return 0, err
+		}
+		switch t.T {
+		case tI16:
+			*(*int16)(p) = int16(x)
+		case tI32:
+			*(*int32)(p) = x
+		default: // tENUM
+			if t.Exact {
+				if err := t.checkEnum(int64(x)); err != nil {
+					return 0, err
+				}
+			}
+			*(*int64)(p) = int64(x)
+		}
+		return n, nil
+
+	case tI64:
+		v, n, err := readVarint(b)
+		if err != nil {
+			return 0, err
+		}
+		*(*int64)(p) = unzigzag64(v)
+		return n, nil
+
+	case tDOUBLE:
+		if len(b) < 8 {
+			return 0, io.ErrShortBuffer
+		}
+		*(*uint64)(p) = binary.LittleEndian.Uint64(b)
+		return 8, nil
+
+	case tFLOAT:
+		if len(b) < 8 {
+			return 0, io.ErrShortBuffer
+		}
+		v, err := narrowFloat(binary.LittleEndian.Uint64(b), t.Exact)
+		if err != nil {
+			return 0, err
+		}
+		*(*float32)(p) = v
+		return 8, nil
+
+	case tUUID:
+		if len(b) < 16 {
+			return 0, io.ErrShortBuffer
+		}
+		*(*[16]byte)(p) = *(*[16]byte)(b)
+		return 16, nil
+
+	case tSTRING:
+		l, i, err := readCompactSize(b)
+		if err != nil {
+			return i, err
+		}
+		if l == 0 {
+			setString(t, p, nil, 0)
+			return i, nil
+		}
+		if l > len(b)-i {
+			return i, newSizeExceedsBufferException(l, len(b)-i)
+		}
+		if err := d.checkString(l); err != nil {
+			return i, err
+		}
+		x := (*byte)(d.Malloc(l, 1, 0))
+		copy(unsafe.Slice(x, l), b[i:])
+		setString(t, p, x, l)
+		return i + l, nil
+
+	case tMAP:
+		// map header
+		l, i, err := readCompactSize(b)
+		if err != nil {
+			return i, err
+		}
+		kt := t.K
+		vt := t.V
+		if l == 0 {
+			*(*unsafe.Pointer)(p) = reflect.MakeMapWithSize(t.RT, 0).UnsafePointer()
+			return i, nil
+		}
+		if i >= len(b) {
+			return i, io.ErrShortBuffer
+		}
+		t0, t1 := ctype2ttype[b[i]>>4], ctype2ttype[b[i]&0x0f]
+		i++
+		elemSize := kt.allocSize() + vt.allocSize()
+		if err := d.checkMapHeader(kt.WT, vt.WT, t0, t1, l, elemSize, b[i:], &compactMinWireSize); err != nil {
+			return i, err
+		}
+
+		// see comments of decodeType for the details of tmp vars and pre-allocation
+		tmp := t.MapTmpVarsPool.Get().(*tmpMapVars)
+		k := tmp.k
+		v := tmp.v
+		kp := tmp.kp
+		vp := tmp.vp
+		m := reflect.MakeMapWithSize(t.RT, l)
+
+		var sliceK unsafe.Pointer
+		if kt.IsPointer {
+			sliceK = d.Malloc(l*kt.V.Size, kt.V.Align, kt.V.MallocAbiType)
+		}
+		var sliceV unsafe.Pointer
+		if vt.IsPointer {
+			sliceV = d.Malloc(l*vt.V.Size, vt.V.Align, vt.V.MallocAbiType)
+		}
+
+		var n int
+		for j := 0; j < l; j++ {
+			tmp := kp
+			if kt.IsPointer { // tmp = &sliceK[j]
+				if j != 0 {
+					sliceK = unsafe.Add(sliceK, kt.V.Size) // next
+				}
+				*(*unsafe.Pointer)(tmp) = sliceK
+				tmp = sliceK
+			}
+			if n, err = d.decodeCompactType(kt, b[i:], tmp, maxdepth-1); err != nil {
+				break
+			}
+			i += n
+			tmp = vp
+			if vt.IsPointer { // tmp = &sliceV[j]
+				if j != 0 {
+					sliceV = unsafe.Add(sliceV, vt.V.Size) // next
+				}
+				*(*unsafe.Pointer)(tmp) = sliceV
+				tmp = sliceV
+			}
+			if n, err = d.decodeCompactType(vt, b[i:], tmp, maxdepth-1); err != nil {
+				break
+			}
+			i += n
+			m.SetMapIndex(k, v)
+		}
+		if err == nil {
+			*(*unsafe.Pointer)(p) = m.UnsafePointer()
+		}
+		t.MapTmpVarsPool.Put(tmp)
+		return i, err
+
+	case tLIST, tSET:
+		// list header
+		tp, l, i, err := readCompactListHeader(b)
+		if err != nil {
+			return i, err
+		}
+		et := t.V
+		if err := d.checkListHeader(et.WT, tp, l, et.allocSize(), b[i:], &compactMinWireSize); err != nil {
+			return i, err
+		}
+
+		h := (*sliceHeader)(p)
+		if l == 0 {
+			h.Zero()
+			return i, nil
+		}
+
+		x := d.Malloc(l*et.Size, et.Align, et.MallocAbiType) // make([]Type, l, l)
+		h.Data = x
+		h.Len = l
+		h.Cap = l
+
+		var sliceData unsafe.Pointer
+		if et.IsPointer {
+			sliceData = d.Malloc(l*et.V.Size, et.V.Align, et.V.MallocAbiType)
+		}
+
+		p = x // point to the 1st element, and then decode one by one
+		for j := 0; j < l; j++ {
+			if j != 0 {
+				p = unsafe.Add(p, et.Size) // next element
+			}
+			vp := p // v[j]
+			if et.IsPointer {
+				if j != 0 {
+					sliceData = unsafe.Add(sliceData, et.V.Size) // next
+				}
+				*(*unsafe.Pointer)(p) = sliceData // v[j] = &sliceData[i]
+				vp = sliceData                    // &v[j]
+			}
+			n, err := d.decodeCompactType(et, b[i:], vp, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+		return i, t.checkSetElems(unsafe.Pointer(h))
+
+	case tSTRUCT:
+		if t.Sd.hasInitFunc {
+			f := t.Sd.initFunc // copy on write, reuse itab of iface
+			updateIface(unsafe.Pointer(&f), p)
+			f.InitDefault()
+		}
+		return d.DecodeCompact(b, p, t.Sd, maxdepth-1)
+
+	case tARRAY, tBYTEARRAY:
+		return d.decodeCompactArray(t, b, p, maxdepth)
+
+	case tCODEC:
+		return decodeCompactCodec(t, b, p)
+
+	case tMAPSET:
+		return d.decodeCompactMapSet(t, b, p, maxdepth)
+
+	case tPOINTER:
+		return d.decodeCompactType(t.V, b, p, maxdepth)
+	}
+	return 0, fmt.Errorf("unknown type: %d", t.T)
+}
+
+// skipCompact returns the size of the value with the given compact type.
+// for ctBOOLEAN_TRUE and ctBOOLEAN_FALSE, it's the size of a bool in containers,
+// bool fields have no payload and must be handled by the caller.
+func skipCompact(b []byte, ct ctype, maxdepth int) (int, error) {
+	if maxdepth == 0 {
+		return 0, errDepthLimitExceeded
+	}
+	switch ct {
+	case ctBOOLEAN_TRUE, ctBOOLEAN_FALSE, ctBYTE:
+		if len(b) < 1 {
+			return 0, io.ErrShortBuffer
+		}
+		return 1, nil
+
+	case ctI16, ctI32, ctI64:
+		_, n, err := readVarint(b)
+		return n, err
+
+	case ctDOUBLE:
+		if len(b) < 8 {
+			return 0, io.ErrShortBuffer
+		}
+		return 8, nil
+
+	case ctUUID:
+		if len(b) < 16 {
+			return 0, io.ErrShortBuffer
+		}
+		return 16, nil
+
+	case ctBINARY:
+		l, i, err := readCompactSize(b)
+		if err != nil {
+			return i, err
+		}
+		if l > len(b)-i {
+			return i, newSizeExceedsBufferException(l, len(b)-i)
+		}
+		return i + l, nil
+
+	case ctLIST, ctSET:
+		if len(b) < 1 {
+			return 0, io.ErrShortBuffer
+		}
+		et := ctype(b[0] & 0x0f)
+		l := int(b[0] >> 4)
+		i := 1
+		if l == 15 { // long form, the size follows as a varint
+			var n int
+			var err error
+			if l, n, err = readCompactSize(b[i:]); err != nil {
+				return i, err
+			}
+			i += n
+		}
+		if l > 0 && ctype2ttype[et] == tSTOP {
+			return i, newUnknownCompactType(et)
+		}
+		for j := 0; j < l; j++ {
+			n, err := skipCompact(b[i:], et, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+		return i, nil
+
+	case ctMAP:
+		l, i, err := readCompactSize(b)
+		if err != nil || l == 0 {
+			return i, err
+		}
+		if i >= len(b) {
+			return i, io.ErrShortBuffer
+		}
+		kt, vt := ctype(b[i]>>4), ctype(b[i]&0x0f)
+		i++
+		if ctype2ttype[kt] == tSTOP {
+			return i, newUnknownCompactType(kt)
+		}
+		if ctype2ttype[vt] == tSTOP {
+			return i, newUnknownCompactType(vt)
+		}
+		for j := 0; j < l; j++ {
+			n, err := skipCompact(b[i:], kt, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+			n, err = skipCompact(b[i:], vt, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+		return i, nil
+
+	case ctSTRUCT:
+		i := 0
+		for {
+			if i >= len(b) {
+				return i, io.ErrShortBuffer
+			}
+			ft := ctype(b[i] & 0x0f)
+			delta := b[i] >> 4
+			i++
+			if ft == ctSTOP {
+				return i, nil
+			}
+			if delta == 0 { // long form field id
+				_, n, err := readVarint(b[i:])
+				if err != nil {
+					return i, err
+				}
+				i += n
+			}
+			if ft == ctBOOLEAN_TRUE || ft == ctBOOLEAN_FALSE {
+				continue // bool fields have no payload
+			}
+			if ctype2ttype[ft] == tSTOP {
+				return i, newUnknownCompactType(ft)
+			}
+			n, err := skipCompact(b[i:], ft, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+	}
+	return 0, newUnknownCompactType(ct)
+}
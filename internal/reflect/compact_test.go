@@ -0,0 +1,248 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+func TestCompactZigzagVarint(t *testing.T) {
+	for _, v := range []int32{0, -1, 1, -64, 64, math.MinInt32, math.MaxInt32} {
+		assert.Equal(t, v, unzigzag32(zigzag32(v)))
+	}
+	for _, v := range []int64{0, -1, 1, math.MinInt64, math.MaxInt64} {
+		assert.Equal(t, v, unzigzag64(zigzag64(v)))
+	}
+	assert.Equal(t, uint32(1), zigzag32(-1))
+	assert.Equal(t, uint32(2), zigzag32(1))
+
+	for _, v := range []uint64{0, 1, 127, 128, 300, math.MaxUint32, math.MaxUint64} {
+		b := appendVarint(nil, v)
+		assert.Equal(t, varintSize(v), len(b))
+		x, n, err := readVarint(b)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), n)
+		assert.Equal(t, v, x)
+	}
+	assert.BytesEqual(t, []byte{0xac, 0x02}, appendVarint(nil, 300))
+}
+
+func TestCompactWireFormat(t *testing.T) {
+	type Msg struct {
+		A bool             `frugal:"1,default,bool"`
+		B bool             `frugal:"2,default,bool"`
+		C int16            `frugal:"3,default,i16"`
+		D []int32          `frugal:"20,default,list<i32>"`
+		E map[string]int64 `frugal:"21,default,map<string:i64>"`
+		F float64          `frugal:"22,default,double"`
+	}
+	p := &Msg{A: true, C: -2, D: []int32{1}, E: map[string]int64{"k": 1}, F: 1}
+	b, err := AppendCompact(nil, p)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, []byte{
+		0x11,       // delta 1, BOOLEAN_TRUE
+		0x12,       // delta 1, BOOLEAN_FALSE
+		0x14, 0x03, // delta 1, I16, zigzag(-2)
+		0x09, 0x28, // long form LIST, zigzag(20)
+		0x15, 0x02, // 1 x I32, zigzag(1)
+		0x1b,       // delta 1, MAP
+		0x01, 0x86, // 1 entry, BINARY:I64
+		0x01, 'k', 0x02, // "k": zigzag(1)
+		0x17, 0, 0, 0, 0, 0, 0, 0xf0, 0x3f, // delta 1, DOUBLE, little endian
+		0x00, // STOP
+	}, b)
+	assert.Equal(t, len(b), CompactEncodedSize(p))
+
+	p1 := &Msg{}
+	n, err := DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+	assert.DeepEqual(t, p, p1)
+
+	// empty containers
+	p = &Msg{}
+	b, err = AppendCompact(nil, p)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, []byte{0x12, 0x12, 0x14, 0x00, 0x09, 0x28, 0x05, 0x1b, 0x00,
+		0x17, 0, 0, 0, 0, 0, 0, 0, 0, 0x00}, b)
+	assert.Equal(t, len(b), CompactEncodedSize(p))
+}
+
+func TestCompactLongList(t *testing.T) {
+	type Msg struct {
+		L []int8 `frugal:"1,default,list<i8>"`
+	}
+	p := &Msg{L: make([]int8, 15)}
+	b, err := AppendCompact(nil, p)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, []byte{0x19, 0xf3, 0x0f}, b[:3]) // LIST, long form size
+	assert.Equal(t, len(b), CompactEncodedSize(p))
+
+	p1 := &Msg{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p, p1)
+
+	// corrupted size
+	_, err = DecodeCompact([]byte{0x19, 0xf3, 0xff, 0xff, 0x0f, 0x00}, p1)
+	assertSizeLimit(t, err)
+}
+
+func TestCompactRoundTrip(t *testing.T) {
+	p0 := NewTestTypes()
+	p0.FBool = true
+	p0.FByte = -1
+	p0.I8 = 8
+	p0.I16 = math.MinInt16
+	p0.I32 = math.MaxInt32
+	p0.I64 = math.MinInt64
+	p0.Double = -1.5
+	p0.String_ = "hello"
+	p0.Binary = []byte("world")
+	p0.Enum = Numberz(-10)
+	p0.UID = 11
+	p0.S = &Msg{Message: "msg", Type: 12}
+	p0.M0 = map[int32]int32{1: 2, -3: 4}
+	p0.M1 = map[int32]string{1: "2"}
+	p0.M2 = map[int32]*Msg{1: {Type: 2}}
+	p0.M3 = map[string]*Msg{"k": {Message: "v"}}
+	p0.L0 = []int32{1, 2, 3}
+	p0.L1 = []string{"a", "b"}
+	p0.L2 = []*Msg{{Type: 1}, {Type: 2}}
+	p0.S0 = []int32{4, 5}
+	p0.S1 = []string{"c"}
+	p0.LM = []map[int32]int32{{1: 1}}
+	p0.ML = map[int32][]int32{1: {1}}
+	p0.MS = map[int32][]int32{2: {2}}
+
+	n := CompactEncodedSize(p0)
+	b, err := AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, n, len(b))
+	assert.True(t, n < EncodedSize(p0))
+
+	i, err := skipCompact(b, ctSTRUCT, maxDepthLimit)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), i)
+
+	p1 := &TestTypes{}
+	i, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), i)
+	assert.DeepEqual(t, p0, p1)
+
+	// optional fields
+	p2 := NewTestTypesOptional()
+	p2.FBool = P(false)
+	p2.I64 = P(int64(-1))
+	p2.Enum = P(Numberz_TEN)
+	p2.String_ = P("")
+	b, err = AppendCompact(nil, p2)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p2), len(b))
+	p3 := &TestTypesOptional{}
+	_, err = DecodeCompact(b, p3)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p2, p3)
+}
+
+func TestCompactUnknownFields(t *testing.T) {
+	type Msg0 struct {
+		A bool   `frugal:"1,default,bool"`
+		B int32  `frugal:"2,default,i32"`
+		C string `frugal:"3,default,string"`
+		D int64  `frugal:"100,default,i64"`
+	}
+	type Msg1 struct { // without A and C
+		B int32 `frugal:"2,default,i32"`
+		D int64 `frugal:"100,default,i64"`
+
+		_unknownFields []byte
+	}
+
+	p0 := &Msg0{A: true, B: 2, C: "c", D: 100}
+	b, err := AppendCompact(nil, p0)
+	assert.Nil(t, err)
+
+	p1 := &Msg1{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), p1.B)
+	assert.Equal(t, int64(100), p1.D)
+	assert.BytesEqual(t, []byte{
+		0x01, 0x02, // long form BOOLEAN_TRUE, zigzag(1)
+		0x08, 0x06, 0x01, 'c', // long form BINARY, zigzag(3), "c"
+	}, p1._unknownFields)
+
+	b, err = AppendCompact(nil, p1)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p1), len(b))
+
+	p2 := &Msg0{}
+	_, err = DecodeCompact(b, p2)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p2)
+}
+
+func TestCompactDecodeErrors(t *testing.T) {
+	type Msg struct {
+		S string `frugal:"1,required,string"`
+	}
+	p := &Msg{}
+
+	_, err := DecodeCompact([]byte{0x00}, p)
+	assert.DeepEqual(t, newRequiredFieldNotSetException("S"), err)
+
+	_, err = DecodeCompact([]byte{0x18, 0x05, 'a'}, p) // length=5, only 1 byte
+	assertSizeLimit(t, err)
+
+	_, err = DecodeCompact([]byte{0x1e, 0x00}, p) // unknown type 14
+	assert.True(t, err != nil)
+
+	_, err = DecodeCompact([]byte{0x18, 0x01, 'a'}, p) // without STOP
+	assert.True(t, err != nil)
+
+	// depth limit
+	b := make([]byte, 0, 2*maxDepthLimit)
+	for i := 0; i < maxDepthLimit; i++ {
+		b = append(b, 0x2c) // delta 2, STRUCT
+	}
+	_, err = DecodeCompact(b, p)
+	assert.True(t, err != nil)
+}
+
+func TestCompactIntOverflow(t *testing.T) {
+	type Msg struct {
+		I16 int16 `frugal:"1,default,i16"`
+		I32 int32 `frugal:"2,default,i32"`
+	}
+	p := &Msg{}
+	_, err := DecodeCompact([]byte{0x14, 0xff, 0xff, 0x03, 0x00}, p) // zigzag 65535
+	assert.Nil(t, err)
+	assert.Equal(t, int16(-32768), p.I16)
+
+	_, err = DecodeCompact([]byte{0x14, 0x80, 0x80, 0x04, 0x00}, p) // zigzag 65536
+	assert.True(t, err != nil && strings.Contains(err.Error(), "overflows I16"), err)
+	_, err = DecodeCompact([]byte{0x25, 0x80, 0x80, 0x80, 0x80, 0x10, 0x00}, p) // zigzag 1<<32
+	assert.True(t, err != nil && strings.Contains(err.Error(), "overflows I32"), err)
+	_, err = DecodeCompact([]byte{0x04, 0x80, 0x80, 0x04, 0x00, 0x00}, p) // field id 65536
+	assert.True(t, err != nil && strings.Contains(err.Error(), "overflows I16"), err)
+}
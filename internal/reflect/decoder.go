@@ -25,7 +25,6 @@ import (
 	"unsafe"
 
 	"github.com/cloudwego/frugal/internal/defs"
-	"github.com/cloudwego/gopkg/protocol/thrift"
 )
 
 const maxDepthLimit = 1023
@@ -43,6 +42,9 @@ type tDecoder struct {
 	// for bool, int8, int16, int32, int64, float64
 	// for string, we only use it for (*sliceHeader).Data, not for []string, coz it contains pointer
 	s span
+
+	decodeLimits
+	decodeStrict
 }
 
 func (d *tDecoder) Malloc(n, align int, abiType uintptr) unsafe.Pointer {
@@ -67,13 +69,9 @@ func (d *tDecoder) Decode(b []byte, base unsafe.Pointer, sd *structDesc, maxdept
 	if maxdepth == 0 {
 		return 0, errDepthLimitExceeded
 	}
-	var bs *bitset
-	if len(sd.requiredFieldIDs) > 0 {
-		bs = bitsetPool.Get().(*bitset)
+	bs := sd.newRequiredBitset()
+	if bs != nil {
 		defer bitsetPool.Put(bs)
-		for _, f := range sd.requiredFieldIDs {
-			bs.unset(f)
-		}
 	}
 
 	var ufs *unknownFields
@@ -84,6 +82,7 @@ func (d *tDecoder) Decode(b []byte, base unsafe.Pointer, sd *structDesc, maxdept
 	}
 
 	i := 0
+	nfields := 0 // declared fields decoded, for unions
 	for {
 		tp := ttype(b[i])
 		i++
@@ -95,9 +94,12 @@ func (d *tDecoder) Decode(b []byte, base unsafe.Pointer, sd *structDesc, maxdept
 
 		f := sd.GetField(fid)
 		if f == nil || f.Type.WT != tp {
-			n, err := thrift.Binary.Skip(b[i:], thrift.TType(tp))
+			if err := d.checkSkipped(sd, f, fid, tp, d.offsetOf(b[i-fieldHeaderLen:])); err != nil {
+				return i, err
+			}
+			n, err := skipBinary(b[i:], tp, maxdepth-1)
 			if err != nil {
-				return i, fmt.Errorf("skip unknown field %d of struct %s err: %w", fid, sd.rt.String(), err)
+				return i, sd.skipFieldErr(fid, err)
 			}
 			if ufs != nil {
 				ufs.Add(i-fieldHeaderLen, n+fieldHeaderLen) // save off and sz, and copy later
@@ -105,22 +107,24 @@ func (d *tDecoder) Decode(b []byte, base unsafe.Pointer, sd *structDesc, maxdept
 			i += n
 			continue
 		}
-		p := unsafe.Add(base, f.Offset) // pointer to the field
-
+		nfields++
 		t := f.Type
-		p = d.mallocIfPointer(t, p)
-		if t.FixedSize > 0 {
+		p := d.fieldPointer(f, base)
+		if t.decodeFast() {
 			i += decodeFixedSizeTypes(t.T, b[i:], p)
 		} else {
 			var n int
 			var err error
 			if f.NoCopy {
 				n, err = decodeStringNoCopy(t, b[i:], p)
+				if err == nil { // the string refers to b, only the size is checked
+					err = d.checkStringSize(n - strHeaderLen)
+				}
 			} else {
 				n, err = d.decodeType(t, b[i:], p, maxdepth-1)
 			}
 			if err != nil {
-				return i, fmt.Errorf("decode field %d of struct %s err: %w", fid, sd.rt.String(), err)
+				return i, sd.decodeFieldErr(fid, err)
 			}
 			i += n
 		}
@@ -128,21 +132,22 @@ func (d *tDecoder) Decode(b []byte, base unsafe.Pointer, sd *structDesc, maxdept
 			bs.set(f.ID)
 		}
 	}
-	for _, fid := range sd.requiredFieldIDs {
-		if !bs.test(fid) {
-			return i, newRequiredFieldNotSetException(lookupFieldName(sd.rt, sd.GetField(fid).Offset))
-		}
+	if err := sd.checkDecoded(bs, nfields); err != nil {
+		return i, err
 	}
 	if ufs != nil && ufs.Size() > 0 {
-		*(*[]byte)(unsafe.Add(base, sd.unknownFieldsOffset)) = ufs.Copy(b)
+		sd.setUnknownFields(base, ufs.Copy(b))
 	}
 	return i, nil
 }
 
 func decodeFixedSizeTypes(t ttype, b []byte, p unsafe.Pointer) int {
 	switch t {
-	case tBOOL, tBYTE:
-		*(*byte)(p) = b[0] // XXX: for tBOOL 1->true, 2->true/false
+	case tBOOL:
+		*(*bool)(p) = b[0] != 0 // any non-zero byte is true, a Go bool must be 0 or 1
+		return 1
+	case tBYTE:
+		*(*byte)(p) = b[0]
 		return 1
 	case tDOUBLE, tI64:
 		*(*uint64)(p) = binary.BigEndian.Uint64(b)
@@ -156,6 +161,12 @@ func decodeFixedSizeTypes(t ttype, b []byte, p unsafe.Pointer) int {
 	case tENUM:
 		*(*int64)(p) = int64(int32(binary.BigEndian.Uint32(b)))
 		return 4
+	case tFLOAT:
+		*(*float32)(p), _ = narrowFloat(binary.BigEndian.Uint64(b), false)
+		return 8
+	case tUUID:
+		*(*[16]byte)(p) = *(*[16]byte)(b)
+		return 16
 	default:
 		panic("bug")
 	}
@@ -177,6 +188,123 @@ var minWireSize = [256]int8{
 	tMAP:    6, // header only, may hold zero entries
 	tSET:    5, // header only, may hold zero elements
 	tLIST:   5, // header only, may hold zero elements
+	wUUID:   16,
+}
+
+// skipBinary returns the size of the value with the given wire type.
+// It's like Skip of gopkg thrift.Binary, but also knows types like wUUID.
+func skipBinary(b []byte, t ttype, maxdepth int) (int, error) {
+	if maxdepth == 0 {
+		return 0, errDepthLimitExceeded
+	}
+	switch t {
+	case tBOOL, tBYTE, tI16, tI32, tI64, tDOUBLE, wUUID:
+		if n := int(typeToSize[t]); len(b) >= n {
+			return n, nil
+		}
+		return 0, io.ErrShortBuffer
+
+	case tSTRING:
+		if len(b) < strHeaderLen {
+			return 0, io.ErrShortBuffer
+		}
+		l := int(int32(binary.BigEndian.Uint32(b)))
+		if l < 0 {
+			return 0, errNegativeSize
+		}
+		if l > len(b)-strHeaderLen {
+			return strHeaderLen, newSizeExceedsBufferException(l, len(b)-strHeaderLen)
+		}
+		return strHeaderLen + l, nil
+
+	case tSTRUCT:
+		i := 0
+		for {
+			if i >= len(b) {
+				return i, io.ErrShortBuffer
+			}
+			tp := ttype(b[i])
+			i++
+			if tp == tSTOP {
+				return i, nil
+			}
+			if i+2 > len(b) {
+				return i, io.ErrShortBuffer
+			}
+			i += 2 // field id
+			n, err := skipBinary(b[i:], tp, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+
+	case tMAP:
+		if len(b) < mapHeaderLen {
+			return 0, io.ErrShortBuffer
+		}
+		kt, vt, l := ttype(b[0]), ttype(b[1]), int(int32(binary.BigEndian.Uint32(b[2:])))
+		if l < 0 {
+			return 0, errNegativeSize
+		}
+		i := mapHeaderLen
+		if l == 0 {
+			return i, nil
+		}
+		if minWireSize[kt] == 0 {
+			return i, newUnknownType(kt)
+		}
+		if minWireSize[vt] == 0 {
+			return i, newUnknownType(vt)
+		}
+		if remain := len(b) - i; l > remain/(int(minWireSize[kt])+int(minWireSize[vt])) {
+			return i, newSizeExceedsBufferException(l, remain)
+		}
+		for j := 0; j < l; j++ {
+			n, err := skipBinary(b[i:], kt, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+			n, err = skipBinary(b[i:], vt, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+		return i, nil
+
+	case tSET, tLIST:
+		if len(b) < listHeaderLen {
+			return 0, io.ErrShortBuffer
+		}
+		et, l := ttype(b[0]), int(int32(binary.BigEndian.Uint32(b[1:])))
+		if l < 0 {
+			return 0, errNegativeSize
+		}
+		i := listHeaderLen
+		if l == 0 {
+			return i, nil
+		}
+		if minWireSize[et] == 0 {
+			return i, newUnknownType(et)
+		}
+		if remain := len(b) - i; l > remain/int(minWireSize[et]) {
+			return i, newSizeExceedsBufferException(l, remain)
+		}
+		if sz := int(typeToSize[et]); sz > 0 {
+			return i + l*sz, nil // fast path, the size is checked above
+		}
+		for j := 0; j < l; j++ {
+			n, err := skipBinary(b[i:], et, maxdepth-1)
+			if err != nil {
+				return i, err
+			}
+			i += n
+		}
+		return i, nil
+	}
+	return 0, newUnknownType(t)
 }
 
 func decodeStringNoCopy(t *tType, b []byte, p unsafe.Pointer) (i int, err error) {
@@ -216,6 +344,11 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 		return 0, errDepthLimitExceeded
 	}
 	if t.FixedSize > 0 {
+		if t.Exact {
+			if err := checkExact(t, b); err != nil {
+				return 0, err
+			}
+		}
 		return decodeFixedSizeTypes(t.T, b, p), nil
 	}
 	switch t.T {
@@ -229,25 +362,20 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 		}
 		i := 4
 		if l == 0 {
-			if t.Tag == defs.T_binary {
-				*(*[]byte)(p) = []byte{}
-			} else {
-				*(*string)(p) = ""
-			}
+			setString(t, p, nil, 0)
 			return i, nil
 		}
 
 		if l > len(b)-i {
 			return i, newSizeExceedsBufferException(l, len(b)-i)
 		}
-
-		x := d.Malloc(l, 1, 0)
-		if t.Tag == defs.T_binary {
-			*(*[]byte)(p) = unsafe.Slice((*byte)(x), l)
-		} else {
-			*(*string)(p) = unsafe.String((*byte)(x), l)
+		if err := d.checkString(l); err != nil {
+			return i, err
 		}
-		copy(unsafe.Slice((*byte)(x), l), b[i:])
+
+		x := (*byte)(d.Malloc(l, 1, 0))
+		copy(unsafe.Slice(x, l), b[i:])
+		setString(t, p, x, l)
 		i += l
 		return i, nil
 
@@ -257,22 +385,15 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 			return 0, io.ErrShortBuffer
 		}
 		t0, t1, l := ttype(b[0]), ttype(b[1]), int(int32(binary.BigEndian.Uint32(b[2:])))
-		if l < 0 {
-			return 0, errNegativeSize
-		}
-
-		// check types
 		kt := t.K
 		vt := t.V
-		if t0 != kt.WT || t1 != vt.WT {
-			return 0, newTypeMismatchKV(kt.WT, vt.WT, t0, t1)
-		}
 
-		// reject corrupted lengths before allocating the map: every entry needs
+		// check types, and reject corrupted lengths before allocating the map: every entry needs
 		// at least minWireSize[key]+minWireSize[value] bytes, so l entries can
 		// not fit if they exceed the remaining buffer. likely data is broken.
-		if remain := len(b) - mapHeaderLen; l > remain/(int(minWireSize[kt.WT])+int(minWireSize[vt.WT])) {
-			return mapHeaderLen, newSizeExceedsBufferException(l, remain)
+		elemSize := kt.allocSize() + vt.allocSize()
+		if err := d.checkMapHeader(kt.WT, vt.WT, t0, t1, l, elemSize, b[mapHeaderLen:], &minWireSize); err != nil {
+			return mapHeaderLen, err
 		}
 
 		// decode map
@@ -314,7 +435,7 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 				*(*unsafe.Pointer)(tmp) = sliceK
 				tmp = sliceK
 			}
-			if kt.FixedSize > 0 {
+			if kt.decodeFast() {
 				i += decodeFixedSizeTypes(kt.T, b[i:], tmp)
 			} else {
 				if n, err = d.decodeType(kt, b[i:], tmp, maxdepth-1); err != nil {
@@ -331,7 +452,7 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 				*(*unsafe.Pointer)(tmp) = sliceV
 				tmp = sliceV
 			}
-			if vt.FixedSize > 0 {
+			if vt.decodeFast() {
 				i += decodeFixedSizeTypes(vt.T, b[i:], tmp)
 			} else {
 				if n, err = d.decodeType(vt, b[i:], tmp, maxdepth-1); err != nil {
@@ -356,31 +477,23 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 			return 0, io.ErrShortBuffer
 		}
 		tp, l := ttype(b[0]), int(int32(binary.BigEndian.Uint32(b[1:])))
-		if l < 0 {
-			return 0, errNegativeSize
-		}
-		// check types
+		i := 5
 		et := t.V
-		if et.WT != tp {
-			return 0, newTypeMismatch(et.WT, tp)
-		}
 
-		i := 5
+		// check types, and reject corrupted lengths before allocating the slice: every element
+		// needs at least minWireSize[et] bytes, so l elements can not fit if
+		// they exceed the remaining buffer. likely the data is broken.
+		if err := d.checkListHeader(et.WT, tp, l, et.allocSize(), b[i:], &minWireSize); err != nil {
+			return i, err
+		}
 
 		// decode list
 		h := (*sliceHeader)(p) // update the slice field
-		if l <= 0 {
+		if l == 0 {
 			h.Zero()
 			return i, nil
 		}
 
-		// reject corrupted lengths before allocating the slice: every element
-		// needs at least minWireSize[et] bytes, so l elements can not fit if
-		// they exceed the remaining buffer. likely the data is broken.
-		if remain := len(b) - i; l > remain/int(minWireSize[et.WT]) {
-			return i, newSizeExceedsBufferException(l, remain)
-		}
-
 		x := d.Malloc(l*et.Size, et.Align, et.MallocAbiType) // malloc for slice. make([]Type, l, l)
 		h.Data = x
 		h.Len = l
@@ -412,7 +525,7 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 				vp = sliceData                    // &v[j]
 			}
 
-			if et.FixedSize > 0 {
+			if et.decodeFast() {
 				i += decodeFixedSizeTypes(et.T, b[i:], vp)
 			} else {
 				n, err := d.decodeType(et, b[i:], vp, maxdepth-1)
@@ -422,7 +535,7 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 				i += n
 			}
 		}
-		return i, nil
+		return i, t.checkSetElems(unsafe.Pointer(h))
 
 	case tSTRUCT:
 		if t.Sd.hasInitFunc {
@@ -431,6 +544,18 @@ func (d *tDecoder) decodeType(t *tType, b []byte, p unsafe.Pointer, maxdepth int
 			f.InitDefault()
 		}
 		return d.Decode(b, p, t.Sd, maxdepth-1)
+
+	case tARRAY, tBYTEARRAY:
+		return d.decodeArray(t, b, p, maxdepth)
+
+	case tCODEC:
+		return decodeCodec(t, b, p)
+
+	case tMAPSET:
+		return d.decodeMapSet(t, b, p, maxdepth)
+
+	case tPOINTER:
+		return d.decodeType(t.V, b, p, maxdepth)
 	}
 	return 0, fmt.Errorf("unknown type: %d", t.T)
 }
@@ -0,0 +1,447 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// streamMaxPrealloc limits the memory pre-allocated for strings and lists
+// whose content is not buffered yet.
+//
+// Unlike decoding from []byte, the number of remaining bytes is unknown,
+// so the minWireSize checks can only be done against the buffered bytes.
+// For larger sizes, memory grows as the data arrives,
+// a corrupted size fails with io.ErrUnexpectedEOF instead of a huge allocation.
+const streamMaxPrealloc = 64 << 10
+
+var streamDecoderPool = sync.Pool{
+	New: func() interface{} {
+		d := &streamDecoder{}
+		d.s.init()
+		return d
+	},
+}
+
+// streamDecoder decodes Thrift Binary from a bufio.Reader,
+// it pulls bytes on demand while walking the structDesc tree.
+type streamDecoder struct {
+	tDecoder
+
+	r *bufio.Reader
+	n int // bytes consumed
+}
+
+func (d *streamDecoder) Reset(r *bufio.Reader) {
+	d.r = r
+	d.n = 0
+}
+
+// next returns the next n bytes, it's only valid before the next read.
+// n must not be larger than the buffer size of d.r
+func (d *streamDecoder) next(n int) ([]byte, error) {
+	b, err := d.r.Peek(n)
+	if err != nil {
+		if err == io.EOF && (d.n > 0 || len(b) > 0) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	_, _ = d.r.Discard(n) // always success after Peek
+	d.n += n
+	return b, nil
+}
+
+// readBytes reads the next l bytes to a newly allocated buffer.
+func (d *streamDecoder) readBytes(l int) ([]byte, error) {
+	if l <= streamMaxPrealloc || l <= d.r.Buffered() {
+		b := unsafe.Slice((*byte)(d.Malloc(l, 1, 0)), l)
+		n, err := io.ReadFull(d.r, b)
+		d.n += n
+		return b, unexpectedEOF(err)
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, streamMaxPrealloc))
+	n, err := io.CopyN(buf, d.r, int64(l))
+	d.n += int(n)
+	return buf.Bytes(), unexpectedEOF(err)
+}
+
+// skip skips the next value of type t. The skipped bytes are appended to b if keep,
+// and they're checked against the limits like the decoded values.
+func (d *streamDecoder) skip(b []byte, keep bool, t ttype, maxdepth int) ([]byte, error) {
+	if maxdepth == 0 {
+		return b, errDepthLimitExceeded
+	}
+	switch t {
+	case tBOOL, tBYTE, tI16, tI32, tI64, tDOUBLE, wUUID:
+		return d.discard(b, keep, int(typeToSize[t]))
+	case tSTRING:
+		x, err := d.next(strHeaderLen)
+		if err != nil {
+			return b, err
+		}
+		l := int(int32(binary.BigEndian.Uint32(x)))
+		if b, err = d.keepBytes(b, keep, x); err != nil {
+			return b, err
+		}
+		if l < 0 {
+			return b, errNegativeSize
+		}
+		if keep {
+			if err = d.checkStringSize(l); err != nil {
+				return b, err
+			}
+		}
+		return d.discard(b, keep, l)
+	case tSTRUCT:
+		for {
+			x, err := d.next(1)
+			if err != nil {
+				return b, err
+			}
+			tp := ttype(x[0])
+			if b, err = d.keepBytes(b, keep, x); err != nil {
+				return b, err
+			}
+			if tp == tSTOP {
+				return b, nil
+			}
+			if b, err = d.discard(b, keep, 2); err != nil { // field id
+				return b, err
+			}
+			if b, err = d.skip(b, keep, tp, maxdepth-1); err != nil {
+				return b, err
+			}
+		}
+	case tMAP:
+		x, err := d.next(mapHeaderLen)
+		if err != nil {
+			return b, err
+		}
+		kt, vt, l := ttype(x[0]), ttype(x[1]), int(int32(binary.BigEndian.Uint32(x[2:])))
+		if b, err = d.keepBytes(b, keep, x); err != nil {
+			return b, err
+		}
+		if l < 0 {
+			return b, errNegativeSize
+		}
+		if keep {
+			if err = d.checkContainer(l, 0); err != nil {
+				return b, err
+			}
+		}
+		for j := 0; j < l; j++ {
+			if b, err = d.skip(b, keep, kt, maxdepth-1); err != nil {
+				return b, err
+			}
+			if b, err = d.skip(b, keep, vt, maxdepth-1); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	case tSET, tLIST:
+		x, err := d.next(listHeaderLen)
+		if err != nil {
+			return b, err
+		}
+		et, l := ttype(x[0]), int(int32(binary.BigEndian.Uint32(x[1:])))
+		if b, err = d.keepBytes(b, keep, x); err != nil {
+			return b, err
+		}
+		if l < 0 {
+			return b, errNegativeSize
+		}
+		if keep {
+			if err = d.checkContainer(l, 0); err != nil {
+				return b, err
+			}
+		}
+		if sz := int(typeToSize[et]); sz > 0 {
+			return d.discard(b, keep, l*sz)
+		}
+		for j := 0; j < l; j++ {
+			if b, err = d.skip(b, keep, et, maxdepth-1); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	}
+	return b, fmt.Errorf("unknown type: %d", t)
+}
+
+// discard skips the next n bytes. The skipped bytes are appended to b if keep.
+func (d *streamDecoder) discard(b []byte, keep bool, n int) ([]byte, error) {
+	if !keep {
+		m, err := d.r.Discard(n)
+		d.n += m
+		return b, unexpectedEOF(err)
+	}
+	if err := d.alloc(n); err != nil {
+		return b, err
+	}
+	for n > 0 {
+		sz := n
+		if sz > d.r.Size() {
+			sz = d.r.Size()
+		}
+		x, err := d.next(sz)
+		if err != nil {
+			return b, err
+		}
+		b = append(b, x...)
+		n -= sz
+	}
+	return b, nil
+}
+
+// keepBytes appends x to b if keep, the appended bytes are counted as allocated.
+func (d *streamDecoder) keepBytes(b []byte, keep bool, x []byte) ([]byte, error) {
+	if !keep {
+		return b, nil
+	}
+	return append(b, x...), d.alloc(len(x))
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (d *streamDecoder) Decode(base unsafe.Pointer, sd *structDesc, maxdepth int) error {
+	if maxdepth == 0 {
+		return errDepthLimitExceeded
+	}
+	bs := sd.newRequiredBitset()
+	if bs != nil {
+		defer bitsetPool.Put(bs)
+	}
+
+	var ufs []byte
+	nfields := 0 // declared fields decoded, for unions
+	for {
+		x, err := d.next(1)
+		if err != nil {
+			return err
+		}
+		tp := ttype(x[0])
+		if tp == tSTOP {
+			break
+		}
+		if x, err = d.next(2); err != nil {
+			return err
+		}
+		fid := binary.BigEndian.Uint16(x)
+
+		f := sd.GetField(fid)
+		if f == nil || f.Type.WT != tp {
+			if err = d.checkSkipped(sd, f, fid, tp, d.n-fieldHeaderLen); err != nil {
+				return err
+			}
+			keep := sd.hasUnknownFields
+			if keep {
+				if err = d.alloc(fieldHeaderLen); err != nil {
+					return err
+				}
+				ufs = append(ufs, byte(tp), byte(fid>>8), byte(fid))
+			}
+			if ufs, err = d.skip(ufs, keep, tp, maxdepth-1); err != nil {
+				return sd.skipFieldErr(fid, err)
+			}
+			continue
+		}
+		nfields++
+		if err = d.decodeType(f.Type, d.fieldPointer(f, base), maxdepth-1); err != nil {
+			return sd.decodeFieldErr(fid, err)
+		}
+		if bs != nil {
+			bs.set(f.ID)
+		}
+	}
+	if err := sd.checkDecoded(bs, nfields); err != nil {
+		return err
+	}
+	if len(ufs) > 0 {
+		sd.setUnknownFields(base, ufs)
+	}
+	return nil
+}
+
+func (d *streamDecoder) decodeType(t *tType, p unsafe.Pointer, maxdepth int) error {
+	if maxdepth == 0 {
+		return errDepthLimitExceeded
+	}
+	if t.FixedSize > 0 {
+		x, err := d.next(t.FixedSize)
+		if err != nil {
+			return err
+		}
+		if t.Exact {
+			if err := checkExact(t, x); err != nil {
+				return err
+			}
+		}
+		decodeFixedSizeTypes(t.T, x, p)
+		return nil
+	}
+	switch t.T {
+	case tSTRING:
+		x, err := d.next(strHeaderLen)
+		if err != nil {
+			return err
+		}
+		l := int(int32(binary.BigEndian.Uint32(x)))
+		if l < 0 {
+			return errNegativeSize
+		}
+		if l == 0 {
+			setString(t, p, nil, 0)
+			return nil
+		}
+		if err := d.checkString(l); err != nil {
+			return err
+		}
+		b, err := d.readBytes(l)
+		if err != nil {
+			return err
+		}
+		setString(t, p, &b[0], l)
+		return nil
+
+	case tMAP:
+		x, err := d.next(mapHeaderLen)
+		if err != nil {
+			return err
+		}
+		t0, t1, l := ttype(x[0]), ttype(x[1]), int(int32(binary.BigEndian.Uint32(x[2:])))
+		kt := t.K
+		vt := t.V
+		if err := d.checkMapHeader(kt.WT, vt.WT, t0, t1, l, kt.allocSize()+vt.allocSize(), nil, nil); err != nil {
+			return err
+		}
+
+		// only trust the size if it fits in the buffered bytes, or let the map grow.
+		hint := l
+		if l > d.r.Buffered()/(int(minWireSize[kt.WT])+int(minWireSize[vt.WT])) &&
+			l*(kt.Size+vt.Size) > streamMaxPrealloc {
+			hint = streamMaxPrealloc / (kt.Size + vt.Size)
+		}
+
+		tmp := t.MapTmpVarsPool.Get().(*tmpMapVars)
+		k := tmp.k
+		v := tmp.v
+		kp := tmp.kp
+		vp := tmp.vp
+		m := reflect.MakeMapWithSize(t.RT, hint)
+		for j := 0; j < l; j++ {
+			if err = d.decodeType(kt, d.mallocIfPointer(kt, kp), maxdepth-1); err != nil {
+				break
+			}
+			if err = d.decodeType(vt, d.mallocIfPointer(vt, vp), maxdepth-1); err != nil {
+				break
+			}
+			m.SetMapIndex(k, v)
+		}
+		if err == nil {
+			// update map field
+			*(*unsafe.Pointer)(p) = m.UnsafePointer()
+		}
+		t.MapTmpVarsPool.Put(tmp)
+		return err
+
+	case tLIST, tSET:
+		x, err := d.next(listHeaderLen)
+		if err != nil {
+			return err
+		}
+		tp, l := ttype(x[0]), int(int32(binary.BigEndian.Uint32(x[1:])))
+		et := t.V
+		if err := d.checkListHeader(et.WT, tp, l, et.allocSize(), nil, nil); err != nil {
+			return err
+		}
+
+		h := (*sliceHeader)(p) // update the slice field
+		if l == 0 {
+			h.Zero()
+			return nil
+		}
+
+		// only trust the size if it fits in the buffered bytes, or let the slice grow.
+		c := l
+		if l > d.r.Buffered()/int(minWireSize[et.WT]) && l*et.Size > streamMaxPrealloc {
+			c = streamMaxPrealloc / et.Size
+		}
+		s := sliceHeader{Data: d.Malloc(c*et.Size, et.Align, et.MallocAbiType), Len: 0, Cap: c}
+		for j := 0; j < l; j++ {
+			if j == s.Cap {
+				s = d.growSlice(t, s, l)
+			}
+			vp := d.mallocIfPointer(et, unsafe.Add(s.Data, j*et.Size))
+			if err = d.decodeType(et, vp, maxdepth-1); err != nil {
+				return err
+			}
+			s.Len++
+		}
+		*h = s
+		return t.checkSetElems(unsafe.Pointer(h))
+
+	case tSTRUCT:
+		if t.Sd.hasInitFunc {
+			f := t.Sd.initFunc // copy on write, reuse itab of iface
+			updateIface(unsafe.Pointer(&f), p)
+			f.InitDefault()
+		}
+		return d.Decode(p, t.Sd, maxdepth-1)
+
+	case tARRAY, tBYTEARRAY:
+		return d.decodeArray(t, p, maxdepth)
+
+	case tCODEC:
+		return d.decodeCodec(t, p)
+
+	case tMAPSET:
+		return d.decodeMapSet(t, p, maxdepth)
+
+	case tPOINTER:
+		return d.decodeType(t.V, p, maxdepth)
+	}
+	return fmt.Errorf("unknown type: %d", t.T)
+}
+
+// growSlice doubles the cap of s of list type t, and the cap never exceeds l.
+func (d *streamDecoder) growSlice(t *tType, s sliceHeader, l int) sliceHeader {
+	et := t.V
+	c := 2 * s.Cap
+	if c > l {
+		c = l
+	}
+	ret := sliceHeader{Data: d.Malloc(c*et.Size, et.Align, et.MallocAbiType), Len: s.Len, Cap: c}
+	if et.MallocAbiType == 0 {
+		copy(unsafe.Slice((*byte)(ret.Data), s.Len*et.Size), unsafe.Slice((*byte)(s.Data), s.Len*et.Size))
+	} else { // with write barriers for pointers
+		reflect.Copy(reflect.NewAt(t.RT, unsafe.Pointer(&ret)).Elem(), reflect.NewAt(t.RT, unsafe.Pointer(&s)).Elem())
+	}
+	return ret
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+func TestDecodeFrom(t *testing.T) {
+	p0 := NewTestTypes()
+	p0.FBool = true
+	p0.I16 = -16
+	p0.I64 = 1 << 40
+	p0.Double = 1.5
+	p0.String_ = "hello"
+	p0.Binary = []byte("world")
+	p0.Enum = Numberz_TEN
+	p0.S = &Msg{Message: "msg", Type: 12}
+	p0.M0 = map[int32]int32{1: 2, -3: 4}
+	p0.M2 = map[int32]*Msg{1: {Type: 2}}
+	p0.M3 = map[string]*Msg{"k": {Message: "v"}}
+	p0.L0 = []int32{1, 2, 3}
+	p0.L1 = []string{"a", "b"}
+	p0.L2 = []*Msg{{Type: 1}, {Type: 2}}
+	p0.LM = []map[int32]int32{{1: 1}}
+	p0.ML = map[int32][]int32{1: {1}}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	p0 = &TestTypes{}
+	_, err = Decode(b, p0)
+	assert.Nil(t, err)
+
+	// one byte at a time, to make sure bytes are pulled on demand
+	p1 := &TestTypes{}
+	n, err := DecodeFrom(iotest.OneByteReader(bytes.NewReader(b)), p1)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+	assert.DeepEqual(t, p0, p1)
+
+	// structs one after another
+	r := bufio.NewReaderSize(bytes.NewReader(append(append([]byte{}, b...), b...)), 16)
+	for i := 0; i < 2; i++ {
+		p1 = &TestTypes{}
+		n, err = DecodeFrom(r, p1)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), n)
+		assert.DeepEqual(t, p0, p1)
+	}
+	_, err = DecodeFrom(r, p1)
+	assert.Equal(t, io.EOF, err)
+
+	// truncated
+	for i := 1; i < len(b); i++ {
+		_, err = DecodeFrom(bytes.NewReader(b[:i]), &TestTypes{})
+		assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), i, err)
+	}
+}
+
+func TestDecodeFromLargeValues(t *testing.T) {
+	type Msg struct {
+		S  string  `frugal:"1,default,string"`
+		L0 []int64 `frugal:"2,default,list<i64>"`
+		L1 []*Msg  `frugal:"3,default,list<Msg>"`
+	}
+	p0 := &Msg{S: strings.Repeat("x", 3*streamMaxPrealloc)}
+	p0.L0 = make([]int64, streamMaxPrealloc)
+	for i := range p0.L0 {
+		p0.L0[i] = int64(i)
+	}
+	p0.L1 = make([]*Msg, streamMaxPrealloc)
+	for i := range p0.L1 {
+		p0.L1[i] = &Msg{S: "s"}
+	}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+
+	p0 = &Msg{}
+	_, err = Decode(b, p0)
+	assert.Nil(t, err)
+
+	p1 := &Msg{}
+	n, err := DecodeFrom(bufio.NewReaderSize(bytes.NewReader(b), 16), p1)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+	assert.True(t, reflect.DeepEqual(p0, p1))
+}
+
+func TestDecodeFromUnknownFields(t *testing.T) {
+	type Msg0 struct {
+		A int32             `frugal:"1,default,i32"`
+		B string            `frugal:"2,default,string"`
+		C map[string][]byte `frugal:"3,default,map<string:binary>"`
+		D []*Msg0           `frugal:"4,default,list<Msg0>"`
+	}
+	type Msg1 struct {
+		A int32 `frugal:"1,default,i32"`
+
+		_unknownFields []byte
+	}
+	p0 := &Msg0{A: 1, B: "b", C: map[string][]byte{"k": []byte("v")}, D: []*Msg0{{A: 2}}}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+
+	p1 := &Msg1{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	p2 := &Msg1{}
+	_, err = DecodeFrom(bufio.NewReaderSize(bytes.NewReader(b), 16), p2)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p1, p2)
+}
+
+func TestDecodeFromErrors(t *testing.T) {
+	type Msg struct {
+		S string  `frugal:"1,required,string"`
+		L []int64 `frugal:"2,default,list<i64>"`
+	}
+
+	_, err := DecodeFrom(bytes.NewReader([]byte{0}), &Msg{})
+	assert.DeepEqual(t, newRequiredFieldNotSetException("S"), err)
+
+	// negative size
+	_, err = DecodeFrom(bytes.NewReader([]byte{0x0b, 0, 1, 0xff, 0xff, 0xff, 0xff}), &Msg{})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "negative size"), err)
+
+	// corrupted size doesn't cause huge allocation
+	_, err = DecodeFrom(bytes.NewReader([]byte{0x0b, 0, 1, 0x7f, 0xff, 0xff, 0xff, 'a'}), &Msg{})
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), err)
+	_, err = DecodeFrom(bytes.NewReader([]byte{0x0f, 0, 2, 0x0a, 0x7f, 0xff, 0xff, 0xff, 0}), &Msg{})
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), err)
+
+	// depth limit
+	b := make([]byte, 0, 3*(maxDepthLimit+1))
+	for i := 0; i <= maxDepthLimit; i++ {
+		b = append(b, byte(tSTRUCT), 0, 100) // unknown field
+	}
+	_, err = DecodeFrom(bytes.NewReader(b), &Msg{})
+	assert.True(t, errors.Is(err, errDepthLimitExceeded), err)
+}
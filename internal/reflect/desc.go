@@ -18,6 +18,7 @@ package reflect
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"sync"
 	"unsafe"
@@ -72,6 +73,34 @@ func createStructDesc(rv reflect.Value) (*structDesc, error) {
 	return sd, nil
 }
 
+// RegisterStruct declares fields of rt in code instead of struct tags, see defs.RegisterFields.
+// The structDesc of rt is created and cached when it's used, like types with struct tags.
+func RegisterStruct(rt reflect.Type, tags map[string]string) error {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	sdsmu.Lock()
+	defer sdsmu.Unlock()
+	if sds.Get(rtTypePtr(rt)) != nil || prefetchStructDescCache[rt] != nil {
+		return fmt.Errorf("%s is already in use", rt)
+	}
+	return defs.RegisterFields(rt, tags)
+}
+
+// RegisterUnion declares rt as a Thrift union, see defs.RegisterUnion.
+// Encoding and decoding fail unless exactly one field is set.
+func RegisterUnion(rt reflect.Type) error {
+	if rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	sdsmu.Lock()
+	defer sdsmu.Unlock()
+	if sds.Get(rtTypePtr(rt)) != nil || prefetchStructDescCache[rt] != nil {
+		return fmt.Errorf("%s is already in use", rt)
+	}
+	return defs.RegisterUnion(rt)
+}
+
 var prefetchStructDescCache = map[reflect.Type]*structDesc{}
 
 func newStructDescAndPrefetch(t reflect.Type) (*structDesc, error) {
@@ -94,7 +123,7 @@ func prefetchSubStructDesc(d *structDesc) error {
 	for i := range d.fields {
 		f := d.fields[i]
 		switch f.Type.T {
-		case tSTRUCT, tMAP, tLIST, tSET:
+		case tSTRUCT, tMAP, tLIST, tSET, tARRAY, tMAPSET, tPOINTER:
 			if err := fetchStructDesc(f.Type); err != nil {
 				return err
 			}
@@ -111,9 +140,12 @@ func fetchStructDesc(t *tType) error {
 		}
 		return fetchStructDesc(t.V)
 	}
-	if t.T == tLIST || t.T == tSET {
+	if t.T == tLIST || t.T == tSET || t.T == tMAPSET || t.T == tPOINTER {
 		return fetchStructDesc(t.V)
 	}
+	if t.T == tARRAY {
+		return fetchStructDesc(t.array().V)
+	}
 	if t.T != tSTRUCT || t.Sd != nil {
 		return nil
 	}
@@ -142,6 +174,8 @@ type structDesc struct {
 	hasInitFunc bool         // true if reflect.Type implements iInitDefault
 	initFunc    iInitDefault // need to change the data pointer when calling
 
+	isUnion bool // exactly one field is set if true, see RegisterUnion
+
 	hasUnknownFields    bool // for the _unknownFields feature
 	unknownFieldsOffset uintptr
 
@@ -161,7 +195,7 @@ func newStructDesc(t reflect.Type) (*structDesc, error) {
 	if err != nil {
 		return nil, err
 	}
-	d := &structDesc{rt: t}
+	d := &structDesc{rt: t, isUnion: defs.IsUnion(t)}
 	d.rvPool = sync.Pool{
 		New: func() interface{} {
 			rv := reflect.New(t)
@@ -231,21 +265,25 @@ func (d *structDesc) fromDefsFields(ff []defs.Field) {
 
 type tField struct {
 	ID     uint16
-	Offset uintptr
+	Name   string  // name of the Go field, for errors
+	Offset uintptr // relative to the struct of the last Embeds if any
+	Embeds []tEmbed
 	Type   *tType
 
 	Spec    defs.Requiredness
 	Default unsafe.Pointer
 
 	NoCopy             bool
+	RangeCheck         bool // for the "rangecheck" option of unsigned integers
 	CanSkipEncodeIfNil bool
 	CanSkipIfDefault   bool
 }
 
 var containerTypes = [256]bool{
-	tMAP:  true,
-	tLIST: true,
-	tSET:  true,
+	tMAP:    true,
+	tLIST:   true,
+	tSET:    true,
+	tMAPSET: true,
 }
 
 var typeToSize = [256]int8{
@@ -256,11 +294,14 @@ var typeToSize = [256]int8{
 	tI32:    4,
 	tI64:    8,
 	tENUM:   4,
+	tFLOAT:  8,
+	tUUID:   16,
+	wUUID:   16, // for wire types
 }
 
 // EncodedSize returns encoded size of the field, -1 if can not be determined.
 func (f *tField) EncodedSize() int {
-	if f.Type.IsPointer { // may be nil, then skip encoding
+	if f.Type.IsPointer || f.Embeds != nil { // may be nil, then skip encoding
 		return -1
 	}
 	if f.Spec == defs.Optional {
@@ -274,8 +315,16 @@ func (f *tField) EncodedSize() int {
 
 func (f *tField) fromDefsField(x defs.Field) {
 	f.ID = x.ID
+	f.Name = x.Name
 	f.Offset = uintptr(x.F)
-	f.Type = newTType(x.Type)
+	for _, e := range x.Embeds {
+		f.Embeds = append(f.Embeds, tEmbed{Offset: uintptr(e.F), RT: e.S})
+	}
+	if x.Opts&defs.PrecisionCheck != 0 {
+		f.Type = newExactTType(x.Type)
+	} else {
+		f.Type = newTType(x.Type)
+	}
 	f.Spec = x.Spec
 
 	t := f.Type
@@ -285,11 +334,13 @@ func (f *tField) fromDefsField(x defs.Field) {
 		// never goes here, defs will check the tag
 		panic("[BUG] nocopy on non-STRING type")
 	}
+	f.RangeCheck = (x.Opts & defs.RangeCheck) != 0
+
 	// for map or slice, t.IsPointer() is false,
 	// but we can consider the types as pointer as per lang spec
 	// for defs.T_binary, actually it's []byte, like tLIST
 	f.CanSkipEncodeIfNil = f.Spec == defs.Optional &&
-		(t.Tag == defs.T_pointer || t.Tag == defs.T_binary || containerTypes[t.T])
+		(t.Tag == defs.T_pointer || t.Tag == defs.T_binary && t.T != tBYTEARRAY || containerTypes[t.T])
 
 	// for SkipEncodeDefault
 	v := x.Default
@@ -0,0 +1,533 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/cloudwego/frugal/internal/opts"
+	"github.com/cloudwego/frugal/schema"
+)
+
+// dynStructDesc is compiled from schema.Struct for encoding and decoding the dynamic value model:
+//
+//	bool, int8, int16, int32, int64, float64, string, []byte, [16]byte for scalars, int32 for enums,
+//	map[string]interface{} for structs, []interface{} for lists and sets, map[interface{}]interface{} for maps.
+type dynStructDesc struct {
+	s *schema.Struct
+
+	fields   []*dynField
+	byID     map[int16]*dynField
+	byName   map[string]*dynField
+	defaults []*dynField
+}
+
+type dynField struct {
+	ID       int16
+	Name     string
+	T        *dynType
+	Required bool
+	Default  interface{}
+}
+
+type dynType struct {
+	Kind schema.Kind
+	WT   ttype
+
+	K  *dynType
+	V  *dynType
+	Sd *dynStructDesc
+
+	Enum *schema.Enum
+}
+
+// getOrCreateDynStructDesc returns the dynStructDesc of s, which is kept in s after it's created.
+func getOrCreateDynStructDesc(s *schema.Struct) (*dynStructDesc, error) {
+	if sd, _ := s.Compiled().(*dynStructDesc); sd != nil && sd.s == s { // not copied from another struct
+		return sd, nil
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	b := &dynDescBuilder{m: map[*schema.Struct]*dynStructDesc{}}
+	sd, err := b.newStructDesc(s)
+	if err != nil {
+		return nil, err
+	}
+	// check default values after all the descriptors are built, they may refer to each other
+	for _, sd := range b.sds {
+		if err := sd.checkDefaults(); err != nil {
+			return nil, err
+		}
+	}
+	s.SetCompiled(sd)
+	return sd, nil
+}
+
+// dynDescBuilder builds the dynStructDesc of a schema and the structs it refers to.
+type dynDescBuilder struct {
+	m   map[*schema.Struct]*dynStructDesc // for recursive types
+	sds []*dynStructDesc                  // in the order of creation
+}
+
+func (b *dynDescBuilder) newStructDesc(s *schema.Struct) (*dynStructDesc, error) {
+	if sd := b.m[s]; sd != nil { // recursive types
+		return sd, nil
+	}
+	sd := &dynStructDesc{
+		s:      s,
+		fields: make([]*dynField, 0, len(s.Fields)),
+		byID:   make(map[int16]*dynField, len(s.Fields)),
+		byName: make(map[string]*dynField, len(s.Fields)),
+	}
+	b.m[s] = sd
+	b.sds = append(b.sds, sd)
+	for _, f := range s.Fields {
+		t, err := b.newType(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("struct %s: field %s: %w", s.Name, f.Name, err)
+		}
+		df := &dynField{
+			ID:       f.ID,
+			Name:     f.Name,
+			T:        t,
+			Required: f.Requiredness == schema.Required,
+			Default:  f.Default,
+		}
+		if df.Default != nil {
+			sd.defaults = append(sd.defaults, df)
+		}
+		sd.fields = append(sd.fields, df)
+		sd.byID[df.ID] = df
+		sd.byName[df.Name] = df
+	}
+	return sd, nil
+}
+
+func (b *dynDescBuilder) newType(x *schema.Type) (t *dynType, err error) {
+	t = &dynType{Kind: x.Kind, WT: ttype(x.Kind.WireType()), Enum: x.Enum}
+	switch x.Kind {
+	case schema.STRUCT:
+		t.Sd, err = b.newStructDesc(x.Struct)
+	case schema.MAP:
+		switch x.Key.Kind {
+		case schema.STRUCT, schema.MAP, schema.SET, schema.LIST:
+			return nil, fmt.Errorf("unsupported map key type %s", x.Key)
+		}
+		if t.K, err = b.newType(x.Key); err == nil {
+			t.V, err = b.newType(x.Elem)
+		}
+	case schema.SET, schema.LIST:
+		t.V, err = b.newType(x.Elem)
+	}
+	return t, err
+}
+
+// checkDefaults checks the default values of sd in advance, they're encoded like other values.
+func (sd *dynStructDesc) checkDefaults() error {
+	for _, f := range sd.defaults {
+		if _, err := dynEncodedSize(f.T, f.Default); err != nil {
+			return fmt.Errorf("struct %s: default value of field %s: %w", sd.s.Name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func newDynTypeMismatch(t *dynType, v interface{}) error {
+	return fmt.Errorf("type mismatch. expect %s, got %T", t.Kind, v)
+}
+
+func withDynFieldErr(err error, sd *dynStructDesc, f *dynField) error {
+	return fmt.Errorf("%q field %d err: %w", sd.s.Name, f.ID, err)
+}
+
+// dynInt converts integers to int64, and checks the range of the given kind.
+func dynInt(t *dynType, v interface{}) (int64, error) {
+	var x int64
+	switch v := v.(type) {
+	case int8:
+		x = int64(v)
+	case int16:
+		x = int64(v)
+	case int32:
+		x = int64(v)
+	case int64:
+		x = v
+	case int:
+		x = int64(v)
+	case uint8:
+		x = int64(v)
+	case uint16:
+		x = int64(v)
+	case uint32:
+		x = int64(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("value %d overflows %s", v, t.Kind)
+		}
+		x = int64(v)
+	case uint:
+		if uint64(v) > math.MaxInt64 {
+			return 0, fmt.Errorf("value %d overflows %s", v, t.Kind)
+		}
+		x = int64(v)
+	case string:
+		if t.Enum != nil { // enum name
+			if e, ok := t.Enum.ValueByName(v); ok {
+				return int64(e), nil
+			}
+			return 0, fmt.Errorf("unknown value %q of enum %s", v, t.Enum.Name)
+		}
+		return 0, newDynTypeMismatch(t, v)
+	default:
+		return 0, newDynTypeMismatch(t, v)
+	}
+	var ok bool
+	switch t.Kind {
+	case schema.BYTE:
+		ok = x >= math.MinInt8 && x <= math.MaxInt8
+	case schema.I16:
+		ok = x >= math.MinInt16 && x <= math.MaxInt16
+	case schema.I32, schema.ENUM:
+		ok = x >= math.MinInt32 && x <= math.MaxInt32
+	default:
+		ok = true
+	}
+	if !ok {
+		return 0, fmt.Errorf("value %d overflows %s", x, t.Kind)
+	}
+	return x, nil
+}
+
+func dynDouble(t *dynType, v interface{}) (float64, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	}
+	x, err := dynInt(t, v)
+	if err != nil {
+		return 0, newDynTypeMismatch(t, v)
+	}
+	return float64(x), nil
+}
+
+func dynStrLen(t *dynType, v interface{}) (int, error) {
+	switch v := v.(type) {
+	case string:
+		return len(v), nil
+	case []byte:
+		return len(v), nil
+	}
+	return 0, newDynTypeMismatch(t, v)
+}
+
+// dynMapRange calls f for each entry of maps in the dynamic value model.
+func dynMapRange(t *dynType, v interface{}, f func(k, v interface{}) error) (int, error) {
+	switch m := v.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			if err := f(k, v); err != nil {
+				return 0, err
+			}
+		}
+		return len(m), nil
+	case map[string]interface{}:
+		for k, v := range m {
+			if err := f(k, v); err != nil {
+				return 0, err
+			}
+		}
+		return len(m), nil
+	}
+	return 0, newDynTypeMismatch(t, v)
+}
+
+func dynStructEncodedSize(sd *dynStructDesc, m map[string]interface{}) (int, error) {
+	if err := sd.check(m); err != nil {
+		return 0, err
+	}
+	ret := 1 // tSTOP
+	for _, f := range sd.fields {
+		v, ok := m[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+		n, err := dynEncodedSize(f.T, v)
+		if err != nil {
+			return 0, withDynFieldErr(err, sd, f)
+		}
+		ret += fieldHeaderLen + n
+	}
+	return ret, nil
+}
+
+// check checks unknown and required fields before encoding, and fields of unions
+func (sd *dynStructDesc) check(m map[string]interface{}) error {
+	n, nset := 0, 0
+	for _, f := range sd.fields {
+		v, ok := m[f.Name]
+		if ok {
+			n++
+		}
+		if v != nil {
+			nset++
+		} else if f.Required {
+			return newRequiredFieldNotSetException(f.Name)
+		}
+	}
+	if sd.s.Union && nset != 1 {
+		return newUnionFieldsException(sd.s.Name, nset)
+	}
+	if n != len(m) {
+		for k := range m {
+			if sd.byName[k] == nil {
+				return fmt.Errorf("unknown field %q of struct %s", k, sd.s.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func dynEncodedSize(t *dynType, v interface{}) (int, error) {
+	switch t.Kind {
+	case schema.BOOL:
+		if _, ok := v.(bool); !ok {
+			return 0, newDynTypeMismatch(t, v)
+		}
+		return 1, nil
+	case schema.BYTE, schema.I16, schema.I32, schema.I64, schema.ENUM:
+		if _, err := dynInt(t, v); err != nil {
+			return 0, err
+		}
+		return int(typeToSize[t.WT]), nil
+	case schema.DOUBLE:
+		if _, err := dynDouble(t, v); err != nil {
+			return 0, err
+		}
+		return 8, nil
+	case schema.STRING, schema.BINARY:
+		n, err := dynStrLen(t, v)
+		return strHeaderLen + n, err
+	case schema.UUID:
+		if _, ok := v.([16]byte); !ok {
+			return 0, newDynTypeMismatch(t, v)
+		}
+		return 16, nil
+	case schema.STRUCT:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return 0, newDynTypeMismatch(t, v)
+		}
+		return dynStructEncodedSize(t.Sd, m)
+	case schema.MAP:
+		ret := mapHeaderLen
+		_, err := dynMapRange(t, v, func(k, v interface{}) error {
+			n, err := dynEncodedSize(t.K, k)
+			if err != nil {
+				return err
+			}
+			ret += n
+			n, err = dynEncodedSize(t.V, v)
+			ret += n
+			return err
+		})
+		return ret, err
+	case schema.SET, schema.LIST:
+		l, ok := v.([]interface{})
+		if !ok {
+			return 0, newDynTypeMismatch(t, v)
+		}
+		ret := listHeaderLen
+		for _, e := range l {
+			n, err := dynEncodedSize(t.V, e)
+			if err != nil {
+				return 0, err
+			}
+			ret += n
+		}
+		return ret, nil
+	}
+	return 0, fmt.Errorf("unknown type: %d", t.Kind)
+}
+
+func appendDynStruct(sd *dynStructDesc, b []byte, m map[string]interface{}) ([]byte, error) {
+	if err := sd.check(m); err != nil {
+		return b, err
+	}
+	var err error
+	for _, f := range sd.fields {
+		v, ok := m[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+		b = append(b, byte(f.T.WT), byte(uint16(f.ID)>>8), byte(f.ID))
+		if b, err = appendDyn(f.T, b, v); err != nil {
+			return b, withDynFieldErr(err, sd, f)
+		}
+	}
+	return append(b, byte(tSTOP)), nil
+}
+
+func appendDyn(t *dynType, b []byte, v interface{}) ([]byte, error) {
+	switch t.Kind {
+	case schema.BOOL:
+		x, ok := v.(bool)
+		if !ok {
+			return b, newDynTypeMismatch(t, v)
+		}
+		if x {
+			return append(b, 1), nil
+		}
+		return append(b, 0), nil
+	case schema.BYTE, schema.I16, schema.I32, schema.I64, schema.ENUM:
+		x, err := dynInt(t, v)
+		if err != nil {
+			return b, err
+		}
+		switch t.WT {
+		case tBYTE:
+			return append(b, byte(x)), nil
+		case tI16:
+			return appendUint16(b, uint16(x)), nil
+		case tI32:
+			return appendUint32(b, uint32(x)), nil
+		}
+		return appendUint64(b, uint64(x)), nil
+	case schema.DOUBLE:
+		x, err := dynDouble(t, v)
+		if err != nil {
+			return b, err
+		}
+		return appendUint64(b, math.Float64bits(x)), nil
+	case schema.STRING, schema.BINARY:
+		switch x := v.(type) {
+		case string:
+			b = appendUint32(b, uint32(len(x)))
+			return append(b, x...), nil
+		case []byte:
+			b = appendUint32(b, uint32(len(x)))
+			return append(b, x...), nil
+		}
+		return b, newDynTypeMismatch(t, v)
+	case schema.UUID:
+		x, ok := v.([16]byte)
+		if !ok {
+			return b, newDynTypeMismatch(t, v)
+		}
+		return append(b, x[:]...), nil
+	case schema.STRUCT:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return b, newDynTypeMismatch(t, v)
+		}
+		return appendDynStruct(t.Sd, b, m)
+	case schema.MAP:
+		var n int
+		switch m := v.(type) {
+		case map[interface{}]interface{}:
+			n = len(m)
+		case map[string]interface{}:
+			n = len(m)
+		default:
+			return b, newDynTypeMismatch(t, v)
+		}
+		b = append(b, byte(t.K.WT), byte(t.V.WT),
+			byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+		var err error
+		_, err = dynMapRange(t, v, func(k, v interface{}) error {
+			if b, err = appendDyn(t.K, b, k); err == nil {
+				b, err = appendDyn(t.V, b, v)
+			}
+			return err
+		})
+		return b, err
+	case schema.SET, schema.LIST:
+		l, ok := v.([]interface{})
+		if !ok {
+			return b, newDynTypeMismatch(t, v)
+		}
+		n := len(l)
+		b = append(b, byte(t.V.WT), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+		var err error
+		for _, e := range l {
+			if b, err = appendDyn(t.V, b, e); err != nil {
+				return b, err
+			}
+		}
+		return b, nil
+	}
+	return b, fmt.Errorf("unknown type: %d", t.Kind)
+}
+
+// cloneDyn returns a deep copy of default values, or they may be changed by users
+func cloneDyn(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []byte:
+		return append([]byte{}, x...)
+	case []interface{}:
+		ret := make([]interface{}, len(x))
+		for i, e := range x {
+			ret[i] = cloneDyn(e)
+		}
+		return ret
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(x))
+		for k, e := range x {
+			ret[k] = cloneDyn(e)
+		}
+		return ret
+	case map[interface{}]interface{}:
+		ret := make(map[interface{}]interface{}, len(x))
+		for k, e := range x {
+			ret[k] = cloneDyn(e)
+		}
+		return ret
+	}
+	return v
+}
+
+func DynamicEncodedSize(s *schema.Struct, v map[string]interface{}) (int, error) {
+	sd, err := getOrCreateDynStructDesc(s)
+	if err != nil {
+		return 0, err
+	}
+	return dynStructEncodedSize(sd, v)
+}
+
+func AppendDynamic(b []byte, s *schema.Struct, v map[string]interface{}) ([]byte, error) {
+	sd, err := getOrCreateDynStructDesc(s)
+	if err != nil {
+		return b, err
+	}
+	return appendDynStruct(sd, b, v)
+}
+
+func DecodeDynamic(b []byte, s *schema.Struct) (map[string]interface{}, int, error) {
+	if s == nil {
+		return nil, 0, errors.New("schema is nil")
+	}
+	sd, err := getOrCreateDynStructDesc(s)
+	if err != nil {
+		return nil, 0, err
+	}
+	l := &decodeLimits{}
+	return decodeDynStruct(l, b, sd, l.reset(&opts.DefaultOptions))
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"unsafe"
+
+	"github.com/cloudwego/frugal/schema"
+)
+
+// decodeDynStruct decodes a struct to map[string]interface{} keyed by field names.
+//
+// Unknown fields are skipped, and absent fields with default values are set to the defaults.
+func decodeDynStruct(dl *decodeLimits, b []byte, sd *dynStructDesc, maxdepth int) (map[string]interface{}, int, error) {
+	if maxdepth == 0 {
+		return nil, 0, errDepthLimitExceeded
+	}
+	m := make(map[string]interface{}, len(sd.fields))
+	i := 0
+	nfields := 0 // declared fields decoded, for unions
+	for {
+		if i >= len(b) {
+			return nil, i, io.ErrShortBuffer
+		}
+		tp := ttype(b[i])
+		i++
+		if tp == tSTOP {
+			break
+		}
+		if len(b)-i < 2 {
+			return nil, i, io.ErrShortBuffer
+		}
+		fid := int16(binary.BigEndian.Uint16(b[i:]))
+		i += 2
+
+		f := sd.byID[fid]
+		if f == nil || f.T.WT != tp {
+			n, err := skipBinary(b[i:], tp, maxdepth-1)
+			if err != nil {
+				return nil, i, fmt.Errorf("skip unknown field %d of struct %s err: %w", fid, sd.s.Name, err)
+			}
+			i += n
+			continue
+		}
+		nfields++
+		v, n, err := decodeDyn(dl, f.T, b[i:], maxdepth-1)
+		if err != nil {
+			return nil, i, fmt.Errorf("decode field %d of struct %s err: %w", fid, sd.s.Name, err)
+		}
+		m[f.Name] = v
+		i += n
+	}
+	if sd.s.Union && nfields != 1 {
+		return nil, i, newUnionFieldsException(sd.s.Name, nfields)
+	}
+	if len(m) != len(sd.fields) {
+		for _, f := range sd.fields {
+			if _, ok := m[f.Name]; ok {
+				continue
+			}
+			if f.Required {
+				return nil, i, newRequiredFieldNotSetException(f.Name)
+			}
+			if f.Default != nil {
+				m[f.Name] = cloneDyn(f.Default)
+			}
+		}
+	}
+	return m, i, nil
+}
+
+const dynElemSize = int(unsafe.Sizeof(interface{}(nil))) // size of elements of []interface{}
+
+func decodeDyn(dl *decodeLimits, t *dynType, b []byte, maxdepth int) (interface{}, int, error) {
+	if maxdepth == 0 {
+		return nil, 0, errDepthLimitExceeded
+	}
+	if n := int(typeToSize[t.WT]); n > 0 && len(b) < n {
+		return nil, 0, io.ErrShortBuffer
+	}
+	switch t.Kind {
+	case schema.BOOL:
+		return b[0] != 0, 1, nil
+	case schema.BYTE:
+		return int8(b[0]), 1, nil
+	case schema.I16:
+		return int16(binary.BigEndian.Uint16(b)), 2, nil
+	case schema.I32, schema.ENUM:
+		return int32(binary.BigEndian.Uint32(b)), 4, nil
+	case schema.I64:
+		return int64(binary.BigEndian.Uint64(b)), 8, nil
+	case schema.DOUBLE:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), 8, nil
+	case schema.UUID:
+		return *(*[16]byte)(b), 16, nil
+
+	case schema.STRING, schema.BINARY:
+		if len(b) < strHeaderLen {
+			return nil, 0, io.ErrShortBuffer
+		}
+		l := int(int32(binary.BigEndian.Uint32(b)))
+		if l < 0 {
+			return nil, 0, errNegativeSize
+		}
+		i := strHeaderLen
+		if l > len(b)-i {
+			return nil, i, newSizeExceedsBufferException(l, len(b)-i)
+		}
+		if err := dl.checkString(l); err != nil {
+			return nil, i, err
+		}
+		if t.Kind == schema.BINARY {
+			return append([]byte{}, b[i:i+l]...), i + l, nil
+		}
+		return string(b[i : i+l]), i + l, nil
+
+	case schema.STRUCT:
+		return decodeDynStruct(dl, b, t.Sd, maxdepth)
+
+	case schema.MAP:
+		if len(b) < mapHeaderLen {
+			return nil, 0, io.ErrShortBuffer
+		}
+		t0, t1, l := ttype(b[0]), ttype(b[1]), int(int32(binary.BigEndian.Uint32(b[2:])))
+		kt, vt := t.K, t.V
+		// same as decodeType, reject corrupted lengths before allocating the map
+		if err := dl.checkMapHeader(kt.WT, vt.WT, t0, t1, l, 2*dynElemSize, b[mapHeaderLen:], &minWireSize); err != nil {
+			return nil, mapHeaderLen, err
+		}
+		m := make(map[interface{}]interface{}, l)
+		i := mapHeaderLen
+		for j := 0; j < l; j++ {
+			k, n, err := decodeDyn(dl, kt, b[i:], maxdepth-1)
+			if err != nil {
+				return nil, i, err
+			}
+			i += n
+			if x, ok := k.([]byte); ok { // []byte is not hashable
+				k = string(x)
+			}
+			v, n, err := decodeDyn(dl, vt, b[i:], maxdepth-1)
+			if err != nil {
+				return nil, i, err
+			}
+			i += n
+			m[k] = v
+		}
+		return m, i, nil
+
+	case schema.SET, schema.LIST:
+		if len(b) < listHeaderLen {
+			return nil, 0, io.ErrShortBuffer
+		}
+		tp, l := ttype(b[0]), int(int32(binary.BigEndian.Uint32(b[1:])))
+		et := t.V
+		// same as decodeType, reject corrupted lengths before allocating the slice
+		i := listHeaderLen
+		if err := dl.checkListHeader(et.WT, tp, l, dynElemSize, b[i:], &minWireSize); err != nil {
+			return nil, i, err
+		}
+		ret := make([]interface{}, l)
+		for j := 0; j < l; j++ {
+			v, n, err := decodeDyn(dl, et, b[i:], maxdepth-1)
+			if err != nil {
+				return nil, i, err
+			}
+			i += n
+			ret[j] = v
+		}
+		return ret, i, nil
+	}
+	return nil, 0, fmt.Errorf("unknown type: %d", t.Kind)
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/schema"
+)
+
+func newMsgSchema() *schema.Struct {
+	return &schema.Struct{
+		Name: "Msg",
+		Fields: []*schema.Field{
+			{ID: 1, Name: "message", Type: &schema.Type{Kind: schema.STRING}},
+			{ID: 2, Name: "type", Type: &schema.Type{Kind: schema.I32}},
+		},
+	}
+}
+
+func TestDynamic(t *testing.T) {
+	msg := newMsgSchema()
+	i32 := &schema.Type{Kind: schema.I32}
+	s := &schema.Struct{
+		Name: "TestTypes",
+		Fields: []*schema.Field{
+			{ID: 1, Name: "FBool", Type: &schema.Type{Kind: schema.BOOL}, Requiredness: schema.Required},
+			{ID: 2, Name: "FByte", Type: &schema.Type{Kind: schema.BYTE}},
+			{ID: 4, Name: "I16", Type: &schema.Type{Kind: schema.I16}},
+			{ID: 6, Name: "I64", Type: &schema.Type{Kind: schema.I64}},
+			{ID: 7, Name: "Double", Type: &schema.Type{Kind: schema.DOUBLE}},
+			{ID: 8, Name: "String", Type: &schema.Type{Kind: schema.STRING}},
+			{ID: 9, Name: "Binary", Type: &schema.Type{Kind: schema.BINARY}},
+			{ID: 10, Name: "Enum", Type: &schema.Type{Kind: schema.ENUM}},
+			{ID: 12, Name: "S", Type: &schema.Type{Kind: schema.STRUCT, Struct: msg}},
+			{ID: 20, Name: "M0", Type: &schema.Type{Kind: schema.MAP, Key: i32, Elem: i32}},
+			{ID: 23, Name: "M3", Type: &schema.Type{Kind: schema.MAP, Key: &schema.Type{Kind: schema.STRING},
+				Elem: &schema.Type{Kind: schema.STRUCT, Struct: msg}}},
+			{ID: 30, Name: "L0", Type: &schema.Type{Kind: schema.LIST, Elem: i32}},
+			{ID: 40, Name: "S0", Type: &schema.Type{Kind: schema.SET, Elem: i32}},
+			{ID: 60, Name: "ML", Type: &schema.Type{Kind: schema.MAP, Key: i32,
+				Elem: &schema.Type{Kind: schema.LIST, Elem: i32}}},
+		},
+	}
+
+	p0 := &TestTypes{
+		FBool: true, FByte: -1, I8: 3, I16: 16, I64: -64, Double: 1.5,
+		String_: "str", Binary: []byte("bin"), Enum: Numberz_TEN, S: &Msg{Message: "m", Type: 1},
+		M0: map[int32]int32{1: 2}, M3: map[string]*Msg{"k": {Type: 3}},
+		L0: []int32{1, 2}, S0: []int32{3}, ML: map[int32][]int32{4: {5}},
+	}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+
+	// struct -> dynamic
+	m, n, err := DecodeDynamic(b, s)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+	assert.DeepEqual(t, map[string]interface{}{
+		"FBool": true, "FByte": int8(-1), "I16": int16(16), "I64": int64(-64), "Double": 1.5,
+		"String": "str", "Binary": []byte("bin"), "Enum": int32(Numberz_TEN),
+		"S":  map[string]interface{}{"message": "m", "type": int32(1)},
+		"M0": map[interface{}]interface{}{int32(1): int32(2)},
+		"M3": map[interface{}]interface{}{"k": map[string]interface{}{"message": "", "type": int32(3)}},
+		"L0": []interface{}{int32(1), int32(2)},
+		"S0": []interface{}{int32(3)},
+		"ML": map[interface{}]interface{}{int32(4): []interface{}{int32(5)}},
+	}, m)
+
+	// dynamic -> struct
+	sz, err := DynamicEncodedSize(s, m)
+	assert.Nil(t, err)
+	b, err = AppendDynamic(nil, s, m)
+	assert.Nil(t, err)
+	assert.Equal(t, sz, len(b))
+	p1 := &TestTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	p0.I8 = 0 // not in schema
+	assert.DeepEqual(t, p0, p1)
+
+	// loose input types
+	m = map[string]interface{}{
+		"FBool": false, "FByte": 1, "I16": uint8(2), "I64": int32(3), "Double": 4,
+		"String": []byte("s"), "Binary": "b", "Enum": nil,
+		"M0": map[string]interface{}{}, "L0": []interface{}{}, "S0": []interface{}{}, "S": map[string]interface{}{},
+	}
+	sz, err = DynamicEncodedSize(s, m)
+	assert.Nil(t, err)
+	b, err = AppendDynamic(nil, s, m)
+	assert.Nil(t, err)
+	assert.Equal(t, sz, len(b))
+	p1 = &TestTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, &TestTypes{FByte: 1, I16: 2, I64: 3, Double: 4, String_: "s", Binary: []byte("b"),
+		S: &Msg{}, M0: map[int32]int32{}, L0: []int32{}, S0: []int32{}}, p1)
+}
+
+func TestDynamicDefaultsAndRequired(t *testing.T) {
+	s := &schema.Struct{
+		Name: "S",
+		Fields: []*schema.Field{
+			{ID: 1, Name: "a", Type: &schema.Type{Kind: schema.I64}, Requiredness: schema.Required},
+			{ID: 2, Name: "b", Type: &schema.Type{Kind: schema.LIST, Elem: &schema.Type{Kind: schema.STRING}},
+				Default: []interface{}{"x"}},
+			{ID: 3, Name: "c", Type: &schema.Type{Kind: schema.ENUM,
+				Enum: &schema.Enum{Name: "E", Values: []schema.EnumValue{{Name: "ONE", Value: 1}}}}},
+		},
+	}
+	b, err := AppendDynamic(nil, s, map[string]interface{}{"a": 1, "c": "ONE"})
+	assert.Nil(t, err)
+	m, _, err := DecodeDynamic(b, s)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, map[string]interface{}{"a": int64(1), "b": []interface{}{"x"}, "c": int32(1)}, m)
+	m["b"].([]interface{})[0] = "y" // default value is copied
+	m, _, _ = DecodeDynamic(b, s)
+	assert.DeepEqual(t, []interface{}{"x"}, m["b"])
+
+	_, err = AppendDynamic(nil, s, map[string]interface{}{})
+	assert.DeepEqual(t, newRequiredFieldNotSetException("a"), err)
+	_, _, err = DecodeDynamic([]byte{0}, s)
+	assert.DeepEqual(t, newRequiredFieldNotSetException("a"), err)
+
+	_, err = AppendDynamic(nil, s, map[string]interface{}{"a": 1, "d": 1})
+	assert.True(t, err != nil && strings.Contains(err.Error(), `unknown field "d"`), err)
+	_, err = AppendDynamic(nil, s, map[string]interface{}{"a": "1"})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "type mismatch"), err)
+	_, err = AppendDynamic(nil, s, map[string]interface{}{"a": 1, "c": "TWO"})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "unknown value"), err)
+	_, err = DynamicEncodedSize(s, map[string]interface{}{"a": 1, "c": 1 << 40})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "overflows"), err)
+
+	// invalid default value
+	_, _, err = DecodeDynamic(b, &schema.Struct{Fields: []*schema.Field{
+		{ID: 1, Name: "a", Type: &schema.Type{Kind: schema.I64}, Default: "x"}}})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "default value"), err)
+}
+
+func TestDynamicDecodeErrors(t *testing.T) {
+	s := newMsgSchema()
+	b, err := AppendDynamic(nil, s, map[string]interface{}{"message": "hello", "type": 1})
+	assert.Nil(t, err)
+	for i := 0; i < len(b); i++ {
+		_, _, err = DecodeDynamic(b[:i], s)
+		assert.True(t, err != nil, i)
+	}
+
+	// unknown fields are skipped
+	_, err = AppendDynamic(nil, s, map[string]interface{}{"message": "hello", "type": 1})
+	assert.Nil(t, err)
+	m, _, err := DecodeDynamic(append(appendStringField(nil, 100, "x"), b...), s)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, map[string]interface{}{"message": "hello", "type": int32(1)}, m)
+
+	// corrupted size
+	l := &schema.Struct{Fields: []*schema.Field{
+		{ID: 1, Name: "l", Type: &schema.Type{Kind: schema.LIST, Elem: &schema.Type{Kind: schema.I64}}}}}
+	_, _, err = DecodeDynamic([]byte{0x0f, 0, 1, 0x0a, 0x7f, 0xff, 0xff, 0xff, 0}, l)
+	assertSizeLimit(t, err)
+
+	// depth limit with a recursive schema
+	r := &schema.Struct{Name: "R"}
+	r.Fields = []*schema.Field{{ID: 1, Name: "r", Type: &schema.Type{Kind: schema.STRUCT, Struct: r}}}
+	b = b[:0]
+	for i := 0; i < maxDepthLimit; i++ {
+		b = append(b, byte(tSTRUCT), 0, 1)
+	}
+	_, _, err = DecodeDynamic(b, r)
+	assert.True(t, errors.Is(err, errDepthLimitExceeded), err)
+}
+
+func TestDynStructDescCache(t *testing.T) {
+	s := newMsgSchema()
+	sd0, err := getOrCreateDynStructDesc(s)
+	assert.Nil(t, err)
+	sd1, err := getOrCreateDynStructDesc(s)
+	assert.Nil(t, err)
+	assert.True(t, sd0 == sd1)
+
+	// a copied schema doesn't reuse the descriptor of the original one
+	c := *s
+	c.Fields = c.Fields[:1]
+	sd2, err := getOrCreateDynStructDesc(&c)
+	assert.Nil(t, err)
+	assert.True(t, sd2 != sd0 && sd2.s == &c)
+	assert.Equal(t, 1, len(sd2.fields))
+
+	// errors are not kept
+	invalid := &schema.Struct{Name: "S", Fields: []*schema.Field{{ID: 1, Name: "a"}}}
+	_, err = getOrCreateDynStructDesc(invalid)
+	assert.True(t, err != nil)
+	invalid.Fields[0].Type = &schema.Type{Kind: schema.I32}
+	_, err = getOrCreateDynStructDesc(invalid)
+	assert.Nil(t, err)
+
+	// default values are checked after all the recursive structs are built
+	newRecursive := func(def interface{}) *schema.Struct {
+		a := &schema.Struct{Name: "A"}
+		b := &schema.Struct{Name: "B", Fields: []*schema.Field{{
+			ID: 1, Name: "a", Type: &schema.Type{Kind: schema.STRUCT, Struct: a}, Default: def,
+		}}}
+		a.Fields = []*schema.Field{
+			{ID: 1, Name: "n", Type: &schema.Type{Kind: schema.I32}},
+			{ID: 2, Name: "b", Type: &schema.Type{Kind: schema.STRUCT, Struct: b}, Requiredness: schema.Optional},
+		}
+		return a
+	}
+	a := newRecursive(map[string]interface{}{"n": int32(1)})
+	sd, err := getOrCreateDynStructDesc(a)
+	assert.Nil(t, err)
+	assert.True(t, sd == a.Compiled())
+	_, err = getOrCreateDynStructDesc(newRecursive(map[string]interface{}{"n": "x"}))
+	assert.True(t, err != nil && strings.Contains(err.Error(), "default value of field a"), err)
+}
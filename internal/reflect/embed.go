@@ -0,0 +1,52 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// tEmbed is an embedded pointer to struct which a promoted field is accessed through.
+type tEmbed struct {
+	Offset uintptr      // relative to the struct of the previous tEmbed if any
+	RT     reflect.Type // the struct type
+}
+
+// embeddedPointer returns the pointer to the promoted field of the struct at base,
+// or nil if any of the embedded struct pointers is nil.
+func (f *tField) embeddedPointer(base unsafe.Pointer) unsafe.Pointer {
+	for _, e := range f.Embeds {
+		if base = *(*unsafe.Pointer)(unsafe.Add(base, e.Offset)); base == nil {
+			return nil
+		}
+	}
+	return unsafe.Add(base, f.Offset)
+}
+
+// mallocEmbeddedPointer is like embeddedPointer,
+// but allocates the embedded structs if the pointers are nil for decoding.
+func (f *tField) mallocEmbeddedPointer(base unsafe.Pointer) unsafe.Pointer {
+	for _, e := range f.Embeds {
+		p := (*unsafe.Pointer)(unsafe.Add(base, e.Offset))
+		if *p == nil {
+			*p = reflect.New(e.RT).UnsafePointer()
+		}
+		base = *p
+	}
+	return unsafe.Add(base, f.Offset)
+}
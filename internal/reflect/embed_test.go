@@ -0,0 +1,118 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+type EmbedBase struct {
+	LogID  string  `frugal:"1,default,string"`
+	Caller *string `frugal:"2,optional,string"`
+}
+
+type EmbedPage struct {
+	Offset int32 `frugal:"3,default,i32"`
+	Limit  int32 `frugal:"4,default,i32"`
+}
+
+type embedTags struct {
+	Tags []string `frugal:"5,default,list<string>"`
+}
+
+type EmbedRequest struct {
+	EmbedBase
+	*EmbedPage
+	embedTags
+	Name string `frugal:"10,default,string"`
+}
+
+type FlatRequest struct {
+	LogID  string   `frugal:"1,default,string"`
+	Caller *string  `frugal:"2,optional,string"`
+	Offset int32    `frugal:"3,default,i32"`
+	Limit  int32    `frugal:"4,default,i32"`
+	Tags   []string `frugal:"5,default,list<string>"`
+	Name   string   `frugal:"10,default,string"`
+}
+
+func TestEmbedded(t *testing.T) {
+	caller := "svc"
+	p0 := &EmbedRequest{
+		EmbedBase: EmbedBase{LogID: "id", Caller: &caller},
+		EmbedPage: &EmbedPage{Offset: 10, Limit: 20},
+		embedTags: embedTags{Tags: []string{"a"}},
+		Name:      "req",
+	}
+	s0 := &FlatRequest{LogID: "id", Caller: &caller, Offset: 10, Limit: 20, Tags: []string{"a"}, Name: "req"}
+
+	// the same as the flat struct on wire
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	expect, err := Append(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	p1 := &EmbedRequest{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	buf := &bytes.Buffer{}
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, b, buf.Bytes())
+	p1 = &EmbedRequest{}
+	_, err = DecodeFrom(buf, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	p1 = &EmbedRequest{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	// fields of nil embedded pointers are skipped
+	p0.EmbedPage = nil
+	s0.Offset, s0.Limit = 0, 0
+	b, err = Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	s1 := &FlatRequest{}
+	_, err = Decode(b, s1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, s0, s1)
+	p1 = &EmbedRequest{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	buf.Reset()
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), buf.Len())
+}
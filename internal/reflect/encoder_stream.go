@@ -0,0 +1,232 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"unsafe"
+)
+
+// streamEncoderBufSize is the size of the scratch buffer of streamEncoder.
+// strings or binaries which don't fit in it are written to io.Writer directly.
+const streamEncoderBufSize = 4096
+
+var streamEncoderPool = sync.Pool{
+	New: func() interface{} {
+		return &streamEncoder{b: make([]byte, 0, streamEncoderBufSize)}
+	},
+}
+
+// streamEncoder encodes Thrift Binary to an io.Writer.
+// It walks the structDesc tree like appendStruct, and flushes the scratch buffer as it goes.
+type streamEncoder struct {
+	w io.Writer
+	b []byte // scratch buffer, it never grows
+	n int    // bytes written to w
+}
+
+func (e *streamEncoder) Reset(w io.Writer) {
+	e.w = w
+	e.b = e.b[:0]
+	e.n = 0
+}
+
+func (e *streamEncoder) Flush() error {
+	if len(e.b) == 0 {
+		return nil
+	}
+	n, err := e.w.Write(e.b)
+	e.n += n
+	e.b = e.b[:0]
+	return err
+}
+
+// ensure flushes the scratch buffer if it has no space for n bytes.
+// n must not be larger than cap(e.b).
+func (e *streamEncoder) ensure(n int) error {
+	if cap(e.b)-len(e.b) < n {
+		return e.Flush()
+	}
+	return nil
+}
+
+// fits reports whether n bytes can be appended to the scratch buffer, it flushes if necessary.
+func (e *streamEncoder) fits(n int) (bool, error) {
+	if n > cap(e.b) {
+		return false, nil
+	}
+	return true, e.ensure(n)
+}
+
+// writeString writes s without length header. s is written directly if it's too large.
+func (e *streamEncoder) writeString(s string) error {
+	if ok, err := e.fits(len(s)); ok || err != nil {
+		e.b = append(e.b, s...)
+		return err
+	}
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	n, err := e.w.Write(unsafe.Slice(unsafe.StringData(s), len(s)))
+	e.n += n
+	return err
+}
+
+func (e *streamEncoder) EncodeStruct(sd *structDesc, base unsafe.Pointer) error {
+	if err := sd.checkEncode(base); err != nil {
+		return err
+	}
+	if base == nil {
+		if err := e.ensure(1); err != nil {
+			return err
+		}
+		e.b = append(e.b, byte(tSTOP))
+		return nil
+	}
+	for _, f := range sd.fields {
+		p := f.encodedPointer(base)
+		if p == nil {
+			continue
+		}
+		if err := sd.checkEncodedField(f, p); err != nil {
+			return err
+		}
+		t := f.Type
+
+		// field header and the value if it's not a string, at most 3+8 bytes
+		if err := e.ensure(fieldHeaderLen + 8); err != nil {
+			return err
+		}
+		e.b = append(e.b, byte(t.WT), byte(f.ID>>8), byte(f.ID))
+		if err := e.encodeAny(t, p); err != nil {
+			return withFieldErr(err, sd, f)
+		}
+	}
+	if xb := sd.getUnknownFields(base); len(xb) > 0 {
+		if err := e.writeString(unsafe.String(&xb[0], len(xb))); err != nil {
+			return err
+		}
+	}
+	if err := e.ensure(1); err != nil {
+		return err
+	}
+	e.b = append(e.b, byte(tSTOP))
+	return nil
+}
+
+func (e *streamEncoder) encodeAny(t *tType, p unsafe.Pointer) error {
+	if t.FixedSize > 0 {
+		if err := e.ensure(t.FixedSize); err != nil {
+			return err
+		}
+		e.b, _ = appendAny(t, e.b, p, nil)
+		return nil
+	}
+	if t.IsPointer {
+		p = *(*unsafe.Pointer)(p)
+	}
+	switch t.T {
+	case tSTRING:
+		s := *((*string)(p))
+		if err := e.ensure(strHeaderLen); err != nil {
+			return err
+		}
+		e.b = appendUint32(e.b, uint32(len(s)))
+		return e.writeString(s)
+
+	case tSTRUCT:
+		return e.EncodeStruct(t.Sd, p)
+
+	case tMAP:
+		var n int
+		if *(*unsafe.Pointer)(p) != nil {
+			n = maplen(*(*unsafe.Pointer)(p))
+		}
+		if kt, vt := t.K, t.V; kt.FixedSize > 0 && vt.FixedSize > 0 {
+			ok, err := e.fits(mapHeaderLen + n*(kt.FixedSize+vt.FixedSize))
+			if ok || err != nil { // fast path
+				if err == nil {
+					e.b, err = t.AppendFunc(t, e.b, p, nil)
+				}
+				return err
+			}
+		}
+		if err := e.ensure(mapHeaderLen); err != nil {
+			return err
+		}
+		b, m := appendMapHeader(t, e.b, p)
+		e.b = b
+		if m == 0 {
+			return nil
+		}
+		it := newMapIter(rvWithPtr(t.RV, p))
+		for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
+			m--
+			if err := e.encodeAny(t.K, kp); err != nil {
+				return err
+			}
+			if err := e.encodeAny(t.V, vp); err != nil {
+				return err
+			}
+		}
+		return checkMapN(m)
+
+	case tLIST, tSET:
+		et := t.V
+		if et.FixedSize > 0 {
+			ok, err := e.fits(listHeaderLen + (*sliceHeader)(p).Len*et.FixedSize)
+			if ok || err != nil { // fast path
+				if err == nil {
+					e.b, err = t.AppendFunc(t, e.b, p, nil)
+				}
+				return err
+			}
+		}
+		if err := t.checkSetElems(p); err != nil {
+			return err
+		}
+		if err := e.ensure(listHeaderLen); err != nil {
+			return err
+		}
+		b, n, vp := appendListHeader(et, e.b, p)
+		e.b = b
+		for i := uint32(0); i < n; i++ {
+			if i != 0 {
+				vp = unsafe.Add(vp, et.Size) // move to next element
+			}
+			if err := e.encodeAny(et, vp); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case tARRAY, tBYTEARRAY:
+		return e.encodeArray(t, p)
+
+	case tCODEC:
+		return e.encodeCodec(t, p)
+
+	case tMAPSET:
+		return e.encodeMapSet(t, p)
+
+	case tPOINTER:
+		return e.encodeAny(t.V, p)
+	}
+	return fmt.Errorf("unknown type: %d", t.T)
+}
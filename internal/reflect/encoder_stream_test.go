@@ -0,0 +1,116 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+type writesRecorder struct {
+	bytes.Buffer
+	writes [][]byte
+}
+
+func (w *writesRecorder) Write(b []byte) (int, error) {
+	w.writes = append(w.writes, b)
+	return w.Buffer.Write(b)
+}
+
+type errWriter struct{ n int }
+
+var errWrite = errors.New("write error")
+
+func (w *errWriter) Write(b []byte) (int, error) {
+	if len(b) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+	w.n -= len(b)
+	return len(b), nil
+}
+
+func TestEncodeTo(t *testing.T) {
+	large := strings.Repeat("x", 2*streamEncoderBufSize)
+
+	p := NewTestTypes()
+	p.FBool = true
+	p.I64 = 1 << 40
+	p.String_ = large
+	p.Binary = []byte("binary")
+	p.S = &Msg{Message: large, Type: 1}
+	p.M0 = map[int32]int32{1: 2} // single entry, the order of maps is random
+	p.M1 = map[int32]string{1: large}
+	p.L0 = make([]int32, streamEncoderBufSize) // larger than the buffer
+	p.L1 = []string{"a", large, "b"}
+	p.L2 = []*Msg{{Type: 1}, nil, {Message: "m"}}
+	p.LM = []map[int32]int32{{1: 1}}
+	p.ML = map[int32][]int32{1: {1}}
+	for i := range p.L0 {
+		p.L0[i] = int32(i)
+	}
+	b, err := Append(nil, p)
+	assert.Nil(t, err)
+
+	w := &writesRecorder{}
+	n, err := EncodeTo(w, p)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+	assert.BytesEqual(t, b, w.Bytes())
+	for _, x := range w.writes {
+		// large strings are written directly, or it must be from the scratch buffer
+		assert.True(t, len(x) <= streamEncoderBufSize || string(x) == large, len(x))
+	}
+
+	// non-pointer
+	w.Reset()
+	n, err = EncodeTo(w, *p)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, b, w.Bytes()[:n])
+}
+
+func TestEncodeToUnknownFields(t *testing.T) {
+	type Msg struct {
+		A int32 `frugal:"1,default,i32"`
+
+		_unknownFields []byte
+	}
+	p := &Msg{A: 1}
+	p._unknownFields = appendStringField(nil, 2, strings.Repeat("x", 2*streamEncoderBufSize))
+	b, err := Append(nil, p)
+	assert.Nil(t, err)
+
+	w := &bytes.Buffer{}
+	n, err := EncodeTo(w, p)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+	assert.BytesEqual(t, b, w.Bytes())
+}
+
+func TestEncodeToWriteError(t *testing.T) {
+	p := &Msg{Message: strings.Repeat("x", 2*streamEncoderBufSize)}
+	for _, limit := range []int{0, 3, 7, 100} {
+		n, err := EncodeTo(&errWriter{n: limit}, p)
+		assert.True(t, errors.Is(err, errWrite), err)
+		assert.Equal(t, limit, n)
+	}
+}
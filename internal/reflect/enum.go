@@ -0,0 +1,60 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Enums are int64 types encoded as i32, values are not checked by default.
+// For types registered by RegisterEnum, tENUM types are marked as Exact,
+// and decoding fails if a value is not valid.
+
+var enumValidators = map[reflect.Type]func(v int64) bool{} // protected by sdsmu
+
+// RegisterEnum registers the func which reports whether a value of the enum type rt is valid.
+// It must be called before rt is used.
+func RegisterEnum(rt reflect.Type, valid func(v int64) bool) error {
+	if rt.Kind() != reflect.Int64 {
+		return fmt.Errorf("enum type %s is not int64", rt)
+	}
+	sdsmu.Lock()
+	defer sdsmu.Unlock()
+	if _, ok := enumValidators[rt]; ok {
+		return fmt.Errorf("enum %s is already registered", rt)
+	}
+	for k := range ttypes {
+		if k.S == rt || k.S.Kind() == reflect.Ptr && k.S.Elem() == rt {
+			return fmt.Errorf("%s is already in use", rt)
+		}
+	}
+	enumValidators[rt] = valid
+	return nil
+}
+
+// checkEnum checks the decoded value of the enum type t if it's registered.
+func (t *tType) checkEnum(v int64) error {
+	if t.EnumValid == nil || t.EnumValid(v) {
+		return nil
+	}
+	rt := t.RT
+	if t.IsPointer {
+		rt = rt.Elem()
+	}
+	return newUnknownEnumValueException(rt.String(), v)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+type checkedEnum int64
+
+type uncheckedEnum int64
+
+type EnumTypes struct {
+	E    checkedEnum            `frugal:"1,default,checkedEnum"`
+	P    *checkedEnum           `frugal:"2,optional,checkedEnum"`
+	List []checkedEnum          `frugal:"3,default,list<checkedEnum>"`
+	Map  map[string]checkedEnum `frugal:"4,default,map<string:checkedEnum>"`
+	U    uncheckedEnum          `frugal:"5,default,uncheckedEnum"`
+}
+
+type BoolTypes struct {
+	B    bool   `frugal:"1,default,bool"`
+	List []bool `frugal:"2,default,list<bool>"`
+}
+
+func TestDecodeBool(t *testing.T) {
+	b, err := Append(nil, &BoolTypes{B: true, List: []bool{true, false}})
+	assert.Nil(t, err)
+	b[3] = 2  // B
+	b[12] = 3 // List[0]
+	for _, decode := range []func(b []byte, p *BoolTypes) error{
+		func(b []byte, p *BoolTypes) error { _, err := Decode(b, p); return err },
+		func(b []byte, p *BoolTypes) error { _, err := DecodeFrom(bytes.NewReader(b), p); return err },
+	} {
+		p := &BoolTypes{}
+		assert.Nil(t, decode(b, p))
+		assert.True(t, p.B == true)
+		assert.True(t, p.List[0] == true)
+		assert.True(t, p.List[1] == false)
+		assert.DeepEqual(t, &BoolTypes{B: true, List: []bool{true, false}}, p)
+	}
+}
+
+func TestRegisterEnum(t *testing.T) {
+	valid := func(v int64) bool { return v >= 0 && v < 3 }
+	assert.Nil(t, RegisterEnum(reflect.TypeOf(checkedEnum(0)), valid))
+	assert.True(t, RegisterEnum(reflect.TypeOf(checkedEnum(0)), valid) != nil)   // registered
+	assert.True(t, RegisterEnum(reflect.TypeOf(int32(0)), valid) != nil)         // not int64
+	assert.True(t, RegisterEnum(reflect.TypeOf(uncheckedEnum(0)), valid) == nil) // not in use
+	delete(enumValidators, reflect.TypeOf(uncheckedEnum(0)))
+
+	three := checkedEnum(3)
+	for _, tc := range []struct {
+		name string
+		p    *EnumTypes
+		ok   bool
+	}{
+		{"valid", &EnumTypes{E: 2, List: []checkedEnum{0, 1}, Map: map[string]checkedEnum{"a": 1}, U: 3}, true},
+		{"field", &EnumTypes{E: 3}, false},
+		{"negative", &EnumTypes{E: -1}, false},
+		{"pointer", &EnumTypes{P: &three}, false},
+		{"list", &EnumTypes{List: []checkedEnum{0, 3}}, false},
+		{"map", &EnumTypes{Map: map[string]checkedEnum{"a": 3}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			check := func(err error) {
+				t.Helper()
+				if tc.ok {
+					assert.Nil(t, err)
+					return
+				}
+				var pe *thrift.ProtocolException
+				assert.True(t, errors.As(err, &pe), err)
+				assert.Equal(t, int32(thrift.INVALID_DATA), pe.TypeID())
+				assert.True(t, strings.Contains(err.Error(), "of enum reflect.checkedEnum"), err)
+			}
+			// values are not checked when encoding
+			b, err := Append(nil, tc.p)
+			assert.Nil(t, err)
+			_, err = Decode(b, &EnumTypes{})
+			check(err)
+			_, err = DecodeFrom(bytes.NewReader(b), &EnumTypes{})
+			check(err)
+			b, err = AppendCompact(nil, tc.p)
+			assert.Nil(t, err)
+			_, err = DecodeCompact(b, &EnumTypes{})
+			check(err)
+		})
+	}
+
+	// the type is in use after decoding
+	assert.True(t, RegisterEnum(reflect.TypeOf(uncheckedEnum(0)), valid) != nil)
+}
@@ -25,6 +25,7 @@ import (
 var (
 	errDepthLimitExceeded = thrift.NewProtocolException(thrift.DEPTH_LIMIT, "depth limit exceeded")
 	errNegativeSize       = thrift.NewProtocolException(thrift.NEGATIVE_SIZE, "negative size")
+	errVarintOverflow     = thrift.NewProtocolException(thrift.INVALID_DATA, "varint overflows 64-bit integer")
 )
 
 func newRequiredFieldNotSetException(name string) error {
@@ -45,6 +46,49 @@ func newSizeExceedsBufferException(size, remain int) error {
 	)
 }
 
+func newVarintOverflowException(t ttype, v uint64) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("zigzag varint %d overflows %s", v, ttype2str(t)),
+	)
+}
+
+func newSizeLimitException(what string, size, limit int) error {
+	return thrift.NewProtocolException(
+		thrift.SIZE_LIMIT,
+		fmt.Sprintf("%s size %d exceeds limit %d", what, size, limit),
+	)
+}
+
+func newUnknownFieldException(name string, fid uint16, got ttype, off int) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("unknown field %d of type %s in struct %s at offset %d", fid, ttype2str(got), name, off),
+	)
+}
+
+func newFieldTypeMismatchException(name string, fid uint16, expected, got ttype, off int) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("field %d of struct %s expects type %s, got %s at offset %d",
+			fid, name, ttype2str(expected), ttype2str(got), off),
+	)
+}
+
+func newUnknownEnumValueException(name string, v int64) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("unknown value %d of enum %s", v, name),
+	)
+}
+
+func newUnionFieldsException(name string, n int) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("union %s must have exactly one field set, got %d", name, n),
+	)
+}
+
 func newTypeMismatch(expect, got ttype) error {
 	return thrift.NewProtocolException(
 		thrift.INVALID_DATA,
@@ -58,3 +102,27 @@ func newTypeMismatchKV(gotk, gotv, expectk, expectv ttype) error {
 		fmt.Sprintf("type mismatch. got map[%s]%s, expect map[%s]%s",
 			ttype2str(expectk), ttype2str(expectv), ttype2str(gotk), ttype2str(gotv)))
 }
+
+func newArrayLenMismatch(t *tType, got int) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("array length mismatch. expect %d for %s, got %d", t.Len, t.RT, got))
+}
+
+func newDuplicateSetElemException(t *tType) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("duplicate elements in set of %s", t.RT))
+}
+
+func newUnknownType(t ttype) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("unknown type %d", t))
+}
+
+func newUnknownCompactType(t ctype) error {
+	return thrift.NewProtocolException(
+		thrift.INVALID_DATA,
+		fmt.Sprintf("unknown compact type %d", t))
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/cloudwego/frugal/internal/defs"
+)
+
+// float32 values are tFLOAT, they're widened to double when encoding,
+// and narrowed when decoding, which may lose precision.
+// For fields with the "precisioncheck" option, tFLOAT types are marked as Exact,
+// and decoding fails if a double can't be narrowed to float32 exactly.
+
+// checkExact checks the fixed-size value in b of the Exact type t before decoding it.
+func checkExact(t *tType, b []byte) error {
+	if t.T == tENUM {
+		return t.checkEnum(int64(int32(binary.BigEndian.Uint32(b))))
+	}
+	_, err := narrowFloat(binary.BigEndian.Uint64(b), true)
+	return err
+}
+
+// newExactTType is like newTType, but marks tFLOAT types as Exact, including elements of containers.
+func newExactTType(x *defs.Type) *tType {
+	k := ttypesK{T: x.String() + ",precisioncheck", S: x.S}
+	if t := ttypes[k]; t != nil {
+		return t
+	}
+	t := &tType{}
+	*t = *newTType(x) // shares everything except K, V
+	ttypes[k] = t
+
+	t.Exact = t.Exact || t.T == tFLOAT
+	if x.K != nil && x.K.IsFloat32() {
+		t.K = newExactTType(x.K)
+	}
+	if x.V != nil && x.V.IsFloat32() {
+		t.V = newExactTType(x.V)
+	}
+	return t
+}
+
+// narrowFloat converts the double of the given bits to float32.
+// It returns an error if exact and the double can't be represented by float32 without losing precision.
+func narrowFloat(bits uint64, exact bool) (float32, error) {
+	v := math.Float64frombits(bits)
+	f := float32(v)
+	if exact && float64(f) != v && !math.IsNaN(v) {
+		return f, fmt.Errorf("double %v can't be narrowed to float32 without losing precision", v)
+	}
+	return f, nil
+}
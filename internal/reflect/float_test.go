@@ -0,0 +1,153 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+type Float32Types struct {
+	F  float32               `frugal:"1,default,double"`
+	P  *float32              `frugal:"2,optional,double"`
+	L  []float32             `frugal:"3,default,list<double>"`
+	S  []float32             `frugal:"4,default,set<double>"`
+	M  map[string]float32    `frugal:"5,default,map<string:double>"`
+	MK map[float32][]float32 `frugal:"6,default,map<double:list<double>>"`
+}
+
+type Float64Types struct {
+	F  float64               `frugal:"1,default,double"`
+	P  *float64              `frugal:"2,optional,double"`
+	L  []float64             `frugal:"3,default,list<double>"`
+	S  []float64             `frugal:"4,default,set<double>"`
+	M  map[string]float64    `frugal:"5,default,map<string:double>"`
+	MK map[float64][]float64 `frugal:"6,default,map<double:list<double>>"`
+}
+
+type Float32PrecisionCheck struct {
+	F float32           `frugal:"1,default,double,precisioncheck"`
+	P *float32          `frugal:"2,optional,double,precisioncheck"`
+	L []float32         `frugal:"3,optional,list<double>,precisioncheck"`
+	M map[int32]float32 `frugal:"4,optional,map<i32:double>,precisioncheck"`
+	N []float32         `frugal:"5,optional,list<double>"`
+}
+
+type Float64PrecisionCheck struct {
+	F float64           `frugal:"1,default,double"`
+	P *float64          `frugal:"2,optional,double"`
+	L []float64         `frugal:"3,optional,list<double>"`
+	M map[int32]float64 `frugal:"4,optional,map<i32:double>"`
+	N []float64         `frugal:"5,optional,list<double>"`
+}
+
+func TestFloat32(t *testing.T) {
+	f32 := float32(-2.5)
+	p0 := &Float32Types{
+		F: 1.5, P: &f32, L: []float32{0.25, math.MaxFloat32, float32(math.Inf(-1))}, S: []float32{3},
+		M: map[string]float32{"a": 0.125}, MK: map[float32][]float32{1: {2, 3}},
+	}
+	f64 := float64(-2.5)
+	d0 := &Float64Types{
+		F: 1.5, P: &f64, L: []float64{0.25, math.MaxFloat32, math.Inf(-1)}, S: []float64{3},
+		M: map[string]float64{"a": 0.125}, MK: map[float64][]float64{1: {2, 3}},
+	}
+
+	// widened to double
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	expect, err := Append(nil, d0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	p1 := &Float32Types{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	buf := &bytes.Buffer{}
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, buf.Bytes())
+	p1 = &Float32Types{}
+	_, err = DecodeFrom(buf, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	expect, err = AppendCompact(nil, d0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+	p1 = &Float32Types{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	// narrowed from double
+	d0 = &Float64Types{F: 0.1, L: []float64{math.MaxFloat64}}
+	b, err = Append(nil, d0)
+	assert.Nil(t, err)
+	p1 = &Float32Types{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.Equal(t, float32(0.1), p1.F)
+	assert.True(t, math.IsInf(float64(p1.L[0]), 1))
+}
+
+func TestFloat32PrecisionCheck(t *testing.T) {
+	f32 := float32(0.5)
+	p0 := &Float32PrecisionCheck{F: 0.25, P: &f32, L: []float32{1, float32(math.NaN())}, M: map[int32]float32{1: -1}}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	_, err = Decode(b, &Float32PrecisionCheck{})
+	assert.Nil(t, err)
+
+	// fields without the option are narrowed as usual, even of the same type
+	b, err = Append(nil, &Float64PrecisionCheck{N: []float64{0.1}})
+	assert.Nil(t, err)
+	p1 := &Float32PrecisionCheck{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.Equal(t, float32(0.1), p1.N[0])
+
+	f64 := 0.1
+	for _, d := range []*Float64PrecisionCheck{
+		{F: 0.1},
+		{P: &f64},
+		{L: []float64{1, math.MaxFloat64}},
+		{M: map[int32]float64{1: 1e-50}},
+	} {
+		b, err = Append(nil, d)
+		assert.Nil(t, err)
+		_, err = Decode(b, &Float32PrecisionCheck{})
+		assert.True(t, err != nil && strings.Contains(err.Error(), "losing precision"), err)
+		_, err = DecodeFrom(bytes.NewReader(b), &Float32PrecisionCheck{})
+		assert.True(t, err != nil && strings.Contains(err.Error(), "losing precision"), err)
+
+		b, err = AppendCompact(nil, d)
+		assert.Nil(t, err)
+		_, err = DecodeCompact(b, &Float32PrecisionCheck{})
+		assert.True(t, err != nil && strings.Contains(err.Error(), "losing precision"), err)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"github.com/cloudwego/frugal/internal/opts"
+)
+
+// decodeLimits checks the limits of opts.Options when decoding.
+//
+// Sizes are checked against the limits before allocating memory for them.
+// The allocated bytes are estimated by the Go sizes of strings, binaries and container elements,
+// fixed-size values like struct fields are not counted.
+type decodeLimits struct {
+	maxStringSize    int
+	maxContainerSize int
+	maxAllocSize     int
+
+	allocated int
+}
+
+// reset resets the limits to o, and returns the max depth.
+func (l *decodeLimits) reset(o *opts.Options) int {
+	l.maxStringSize = o.MaxStringSize
+	l.maxContainerSize = o.MaxContainerSize
+	l.maxAllocSize = o.MaxAllocSize
+	l.allocated = 0
+	if o.MaxDepth > 0 {
+		return o.MaxDepth
+	}
+	return maxDepthLimit
+}
+
+// checkStringSize checks the length of a string or binary without allocating it.
+func (l *decodeLimits) checkStringSize(n int) error {
+	if l.maxStringSize > 0 && n > l.maxStringSize {
+		return newSizeLimitException("string", n, l.maxStringSize)
+	}
+	return nil
+}
+
+// checkString checks the length of a string or binary, which is going to be allocated.
+func (l *decodeLimits) checkString(n int) error {
+	if err := l.checkStringSize(n); err != nil {
+		return err
+	}
+	return l.alloc(n)
+}
+
+// checkContainer checks the number of elements of a container,
+// and the elements of elemSize bytes which are going to be allocated.
+func (l *decodeLimits) checkContainer(n, elemSize int) error {
+	if l.maxContainerSize > 0 && n > l.maxContainerSize {
+		return newSizeLimitException("container", n, l.maxContainerSize)
+	}
+	return l.alloc(n * elemSize)
+}
+
+func (l *decodeLimits) alloc(n int) error {
+	if l.maxAllocSize <= 0 {
+		return nil
+	}
+	l.allocated += n
+	if l.allocated > l.maxAllocSize {
+		return newSizeLimitException("allocated", l.allocated, l.maxAllocSize)
+	}
+	return nil
+}
+
+// allocSize returns the bytes allocated for a value of t in containers, including the value it points to.
+func (t *tType) allocSize() int {
+	if t.IsPointer {
+		return t.Size + t.V.Size
+	}
+	return t.Size
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/internal/opts"
+	"github.com/cloudwego/frugal/schema"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+type LimitsNode struct {
+	Next *LimitsNode `frugal:"1,optional,LimitsNode"`
+}
+
+type LimitsTypes struct {
+	Str    string           `frugal:"1,default,string"`
+	Bin    []byte           `frugal:"2,default,binary,nocopy"`
+	List   []int64          `frugal:"3,default,list<i64>"`
+	Map    map[string]int32 `frugal:"4,default,map<string:i32>"`
+	Set    map[int16]bool   `frugal:"5,default,set<i16>"`
+	Nested [][]string       `frugal:"6,default,list<list<string>>"`
+	Node   *LimitsNode      `frugal:"7,optional,LimitsNode"`
+}
+
+func assertLimitErr(t *testing.T, typeID int, err error) {
+	t.Helper()
+	var pe *thrift.ProtocolException
+	assert.True(t, errors.As(err, &pe), err)
+	assert.Equal(t, int32(typeID), pe.TypeID(), err)
+}
+
+// testLimits decodes p0 with o by all the decoders, and asserts the error.
+func testLimits(t *testing.T, p0 *LimitsTypes, o opts.Options, typeID int) {
+	t.Helper()
+	check := func(err error) {
+		t.Helper()
+		if typeID == 0 {
+			assert.Nil(t, err)
+		} else {
+			assertLimitErr(t, typeID, err)
+		}
+	}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	_, err = DecodeWithOptions(b, &LimitsTypes{}, &o)
+	check(err)
+	_, err = DecodeFromWithOptions(bytes.NewReader(b), &LimitsTypes{}, &o)
+	check(err)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	_, err = DecodeCompactWithOptions(b, &LimitsTypes{}, &o)
+	check(err)
+}
+
+func TestDecodeLimits(t *testing.T) {
+	testLimits(t, &LimitsTypes{Str: "hello"}, opts.Options{MaxStringSize: 5}, 0)
+	testLimits(t, &LimitsTypes{Str: "hello!"}, opts.Options{MaxStringSize: 5}, thrift.SIZE_LIMIT)
+	testLimits(t, &LimitsTypes{Nested: [][]string{{"hello!"}}}, opts.Options{MaxStringSize: 5}, thrift.SIZE_LIMIT)
+	testLimits(t, &LimitsTypes{Map: map[string]int32{"hello!": 1}}, opts.Options{MaxStringSize: 5}, thrift.SIZE_LIMIT)
+
+	testLimits(t, &LimitsTypes{List: []int64{1, 2}}, opts.Options{MaxContainerSize: 2}, 0)
+	testLimits(t, &LimitsTypes{List: []int64{1, 2, 3}}, opts.Options{MaxContainerSize: 2}, thrift.SIZE_LIMIT)
+	testLimits(t, &LimitsTypes{Map: map[string]int32{"a": 1, "b": 2, "c": 3}}, opts.Options{MaxContainerSize: 2}, thrift.SIZE_LIMIT)
+	testLimits(t, &LimitsTypes{Set: map[int16]bool{1: true, 2: true, 3: true}}, opts.Options{MaxContainerSize: 2}, thrift.SIZE_LIMIT)
+	testLimits(t, &LimitsTypes{Nested: [][]string{{"a", "b", "c"}}}, opts.Options{MaxContainerSize: 2}, thrift.SIZE_LIMIT)
+
+	// 8 bytes for each int64, and 1 byte for each char
+	testLimits(t, &LimitsTypes{Str: "ab", List: []int64{1, 2}}, opts.Options{MaxAllocSize: 18}, 0)
+	testLimits(t, &LimitsTypes{Str: "abc", List: []int64{1, 2}}, opts.Options{MaxAllocSize: 18}, thrift.SIZE_LIMIT)
+	testLimits(t, &LimitsTypes{List: []int64{1, 2, 3}}, opts.Options{MaxAllocSize: 18}, thrift.SIZE_LIMIT)
+
+	// a struct field takes 2 levels: the field and the struct
+	node := &LimitsNode{Next: &LimitsNode{}}
+	testLimits(t, &LimitsTypes{Node: node}, opts.Options{MaxDepth: 5}, 0)
+	testLimits(t, &LimitsTypes{Node: node}, opts.Options{MaxDepth: 4}, thrift.DEPTH_LIMIT)
+	testLimits(t, &LimitsTypes{Nested: [][]string{{"a"}}}, opts.Options{MaxDepth: 4}, 0)
+	testLimits(t, &LimitsTypes{Nested: [][]string{{"a"}}}, opts.Options{MaxDepth: 3}, thrift.DEPTH_LIMIT)
+
+	// no limits by default
+	testLimits(t, &LimitsTypes{Str: strings.Repeat("x", 1<<20), List: make([]int64, 1<<16)}, opts.Options{}, 0)
+}
+
+func TestDecodeLimits_NoCopy(t *testing.T) {
+	// no allocation for nocopy binaries, but the length is limited
+	b, err := Append(nil, &LimitsTypes{Bin: []byte("hello")})
+	assert.Nil(t, err)
+	o := opts.Options{MaxStringSize: 5, MaxAllocSize: 1}
+	_, err = DecodeWithOptions(b, &LimitsTypes{}, &o)
+	assert.Nil(t, err)
+	o.MaxStringSize = 4
+	_, err = DecodeWithOptions(b, &LimitsTypes{}, &o)
+	assertLimitErr(t, thrift.SIZE_LIMIT, err)
+
+	b, err = AppendCompact(nil, &LimitsTypes{Bin: []byte("hello")})
+	assert.Nil(t, err)
+	_, err = DecodeCompactWithOptions(b, &LimitsTypes{}, &o)
+	assertLimitErr(t, thrift.SIZE_LIMIT, err)
+}
+
+func TestDecodeLimits_Default(t *testing.T) {
+	old := opts.DefaultOptions
+	defer func() { opts.DefaultOptions = old }()
+	opts.DefaultOptions = opts.Options{MaxStringSize: 2, MaxContainerSize: 1}
+
+	b, err := Append(nil, &LimitsTypes{Str: "abc"})
+	assert.Nil(t, err)
+	_, err = Decode(b, &LimitsTypes{})
+	assertLimitErr(t, thrift.SIZE_LIMIT, err)
+
+	s := &schema.Struct{Name: "S", Fields: []*schema.Field{
+		{ID: 1, Name: "s", Type: &schema.Type{Kind: schema.STRING}},
+		{ID: 3, Name: "l", Type: &schema.Type{Kind: schema.LIST, Elem: &schema.Type{Kind: schema.I64}}},
+	}}
+	_, _, err = DecodeDynamic(b, s)
+	assertLimitErr(t, thrift.SIZE_LIMIT, err)
+	b, err = Append(nil, &LimitsTypes{List: []int64{1, 2}})
+	assert.Nil(t, err)
+	_, _, err = DecodeDynamic(b, s)
+	assertLimitErr(t, thrift.SIZE_LIMIT, err)
+	b, err = Append(nil, &LimitsTypes{List: []int64{1}})
+	assert.Nil(t, err)
+	_, _, err = DecodeDynamic(b, s)
+	assert.Nil(t, err)
+}
+
+type LimitsUnknown struct {
+	Node *LimitsNode `frugal:"7,optional,LimitsNode"`
+
+	_unknownFields []byte
+}
+
+func TestDecodeLimits_StreamUnknownFields(t *testing.T) {
+	decode := func(b []byte, o opts.Options) error {
+		_, err := DecodeFromWithOptions(bytes.NewReader(b), &LimitsUnknown{}, &o)
+		return err
+	}
+	encode := func(p *LimitsTypes) []byte {
+		b, err := Append(nil, p)
+		assert.Nil(t, err)
+		return b
+	}
+
+	// 3 bytes for the field header, 4 bytes for the length and 5 bytes for the string
+	b := append(appendStringField(nil, 1, "hello"), byte(tSTOP))
+	assert.Nil(t, decode(b, opts.Options{MaxStringSize: 5, MaxAllocSize: 12}))
+	assertLimitErr(t, thrift.SIZE_LIMIT, decode(b, opts.Options{MaxStringSize: 4}))
+	assertLimitErr(t, thrift.SIZE_LIMIT, decode(b, opts.Options{MaxAllocSize: 11}))
+
+	assert.Nil(t, decode(encode(&LimitsTypes{List: []int64{1, 2}}), opts.Options{MaxContainerSize: 2}))
+	assertLimitErr(t, thrift.SIZE_LIMIT, decode(encode(&LimitsTypes{List: []int64{1, 2, 3}}), opts.Options{MaxContainerSize: 2}))
+	assertLimitErr(t, thrift.SIZE_LIMIT, decode(encode(&LimitsTypes{Map: map[string]int32{"a": 1, "b": 2, "c": 3}}), opts.Options{MaxContainerSize: 2}))
+	assertLimitErr(t, thrift.SIZE_LIMIT, decode(encode(&LimitsTypes{Nested: [][]string{{"a", "b", "c"}}}), opts.Options{MaxContainerSize: 2}))
+
+	// a corrupted size fails before the bytes are read
+	b = []byte{byte(tSTRING), 0, 1, 0x7f, 0xff, 0xff, 0xff}
+	assertLimitErr(t, thrift.SIZE_LIMIT, decode(b, opts.Options{MaxAllocSize: 1 << 20}))
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"encoding/binary"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+// Go maps like map[K]struct{} or map[K]bool are tMAPSET, which are encoded as sets of the keys.
+// For map[K]bool, keys of false values are not in the set, they're skipped when encoding.
+// When decoding, values of map[K]bool are always true.
+// Unlike slices, decoding always creates a non-nil map, even if the set is empty.
+
+// mapSetLen returns the number of elements of the set which p points to.
+func (t *tType) mapSetLen(p unsafe.Pointer) int {
+	if *(*unsafe.Pointer)(p) == nil {
+		return 0
+	}
+	if !t.SetBool {
+		return maplen(*(*unsafe.Pointer)(p))
+	}
+	n := 0
+	it := newMapIter(rvWithPtr(t.RV, p))
+	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
+		if *(*bool)(vp) {
+			n++
+		}
+	}
+	return n
+}
+
+// rangeMapSet calls f with the pointer of each element of the set which p points to.
+func (t *tType) rangeMapSet(p unsafe.Pointer, f func(kp unsafe.Pointer) error) error {
+	if *(*unsafe.Pointer)(p) == nil {
+		return nil
+	}
+	it := newMapIter(rvWithPtr(t.RV, p))
+	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
+		if t.SetBool && !*(*bool)(vp) {
+			continue
+		}
+		if err := f(kp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tType) encodedMapSetSize(p unsafe.Pointer) (int, error) {
+	et := t.V
+	if et.FixedSize > 0 {
+		return listHeaderLen + t.mapSetLen(p)*et.FixedSize, nil
+	}
+	ret := listHeaderLen
+	err := t.rangeMapSet(p, func(kp unsafe.Pointer) error {
+		if et.T == tSTRING {
+			ret += encodedStringSize(kp)
+			return nil
+		}
+		n, err := et.EncodedSizeFunc(kp)
+		ret += n
+		return err
+	})
+	return ret, err
+}
+
+func appendMapSet(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
+	if err := t.checkSetElems(p); err != nil {
+		return b, err
+	}
+	et := t.V
+	n := uint32(t.mapSetLen(p))
+	b = append(b, byte(et.WT), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	err := t.rangeMapSet(p, func(kp unsafe.Pointer) (err error) {
+		n--
+		b, err = appendAny(et, b, kp, w)
+		return err
+	})
+	if err != nil {
+		return b, err
+	}
+	return b, checkMapN(n)
+}
+
+func (d *tDecoder) decodeMapSet(t *tType, b []byte, p unsafe.Pointer, maxdepth int) (int, error) {
+	if len(b) < listHeaderLen {
+		return 0, io.ErrShortBuffer
+	}
+	tp, l := ttype(b[0]), int(int32(binary.BigEndian.Uint32(b[1:])))
+	i := listHeaderLen
+	et := t.V
+	if err := d.checkListHeader(et.WT, tp, l, et.allocSize(), b[i:], &minWireSize); err != nil {
+		return i, err
+	}
+	tmp := t.MapTmpVarsPool.Get().(*tmpMapVars)
+	m := reflect.MakeMapWithSize(t.RT, l)
+	var err error
+	for j := 0; j < l; j++ {
+		kp := d.mallocIfPointer(et, tmp.kp)
+		if et.decodeFast() {
+			i += decodeFixedSizeTypes(et.T, b[i:], kp)
+		} else {
+			var n int
+			if n, err = d.decodeType(et, b[i:], kp, maxdepth-1); err != nil {
+				break
+			}
+			i += n
+		}
+		m.SetMapIndex(tmp.k, tmp.v)
+	}
+	if err == nil {
+		*(*unsafe.Pointer)(p) = m.UnsafePointer()
+		err = t.checkDecodedMapSet(p, l)
+	}
+	t.MapTmpVarsPool.Put(tmp)
+	return i, err
+}
+
+func appendCompactMapSet(t *tType, b []byte, p unsafe.Pointer) ([]byte, error) {
+	if err := t.checkSetElems(p); err != nil {
+		return b, err
+	}
+	et := t.V
+	n := t.mapSetLen(p)
+	b = appendCompactListHeader(b, ttype2ctype[et.WT], n)
+	err := t.rangeMapSet(p, func(kp unsafe.Pointer) (err error) {
+		n--
+		b, err = appendCompactAny(et, b, kp)
+		return err
+	})
+	if err != nil {
+		return b, err
+	}
+	return b, checkMapN(uint32(n))
+}
+
+func compactMapSetSize(t *tType, p unsafe.Pointer) (int, error) {
+	et := t.V
+	n := t.mapSetLen(p)
+	ret := compactListHeaderSize(n)
+	switch et.T {
+	case tBOOL, tBYTE:
+		return ret + n, nil // fast path
+	case tDOUBLE, tFLOAT:
+		return ret + n*8, nil // fast path
+	}
+	err := t.rangeMapSet(p, func(kp unsafe.Pointer) error {
+		n, err := compactSizeAny(et, kp)
+		ret += n
+		return err
+	})
+	return ret, err
+}
+
+func (d *tDecoder) decodeCompactMapSet(t *tType, b []byte, p unsafe.Pointer, maxdepth int) (int, error) {
+	tp, l, i, err := readCompactListHeader(b)
+	if err != nil {
+		return i, err
+	}
+	et := t.V
+	if err := d.checkListHeader(et.WT, tp, l, et.allocSize(), b[i:], &compactMinWireSize); err != nil {
+		return i, err
+	}
+	if l == 0 {
+		*(*unsafe.Pointer)(p) = reflect.MakeMapWithSize(t.RT, 0).UnsafePointer()
+		return i, nil
+	}
+	tmp := t.MapTmpVarsPool.Get().(*tmpMapVars)
+	m := reflect.MakeMapWithSize(t.RT, l)
+	for j := 0; j < l; j++ {
+		var n int
+		if n, err = d.decodeCompactType(et, b[i:], d.mallocIfPointer(et, tmp.kp), maxdepth-1); err != nil {
+			break
+		}
+		i += n
+		m.SetMapIndex(tmp.k, tmp.v)
+	}
+	if err == nil {
+		*(*unsafe.Pointer)(p) = m.UnsafePointer()
+		err = t.checkDecodedMapSet(p, l)
+	}
+	t.MapTmpVarsPool.Put(tmp)
+	return i, err
+}
+
+func (e *streamEncoder) encodeMapSet(t *tType, p unsafe.Pointer) error {
+	if err := t.checkSetElems(p); err != nil {
+		return err
+	}
+	if err := e.ensure(listHeaderLen); err != nil {
+		return err
+	}
+	et := t.V
+	n := uint32(t.mapSetLen(p))
+	e.b = append(e.b, byte(et.WT), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	err := t.rangeMapSet(p, func(kp unsafe.Pointer) error {
+		n--
+		return e.encodeAny(et, kp)
+	})
+	if err != nil {
+		return err
+	}
+	return checkMapN(n)
+}
+
+func (d *streamDecoder) decodeMapSet(t *tType, p unsafe.Pointer, maxdepth int) error {
+	x, err := d.next(listHeaderLen)
+	if err != nil {
+		return err
+	}
+	tp, l := ttype(x[0]), int(int32(binary.BigEndian.Uint32(x[1:])))
+	et := t.V
+	if err := d.checkListHeader(et.WT, tp, l, et.allocSize(), nil, nil); err != nil {
+		return err
+	}
+
+	// only trust the size if it fits in the buffered bytes, or let the map grow.
+	hint := l
+	if l > d.r.Buffered()/int(minWireSize[et.WT]) && l*et.Size > streamMaxPrealloc {
+		hint = streamMaxPrealloc / et.Size
+	}
+	tmp := t.MapTmpVarsPool.Get().(*tmpMapVars)
+	m := reflect.MakeMapWithSize(t.RT, hint)
+	for j := 0; j < l; j++ {
+		if err = d.decodeType(et, d.mallocIfPointer(et, tmp.kp), maxdepth-1); err != nil {
+			break
+		}
+		m.SetMapIndex(tmp.k, tmp.v)
+	}
+	if err == nil {
+		*(*unsafe.Pointer)(p) = m.UnsafePointer()
+		err = t.checkDecodedMapSet(p, l)
+	}
+	t.MapTmpVarsPool.Put(tmp)
+	return err
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+type MapSetElem struct {
+	X int32 `frugal:"1,default,i32"`
+}
+
+type MapSetTypes struct {
+	I32s    map[int32]struct{}            `frugal:"1,default,set<i32>"`
+	Strs    map[string]bool               `frugal:"2,default,set<string>"`
+	Opt     map[int64]struct{}            `frugal:"3,optional,set<i64>"`
+	Elems   map[*MapSetElem]struct{}      `frugal:"4,default,set<MapSetElem>"`
+	Nested  map[string]map[int16]bool     `frugal:"5,default,map<string:set<i16>>"`
+	List    []map[float64]struct{}        `frugal:"6,default,list<set<double>>"`
+	Uint    map[uint8]struct{}            `frugal:"7,default,set<byte>,unsigned"`
+	Bools   map[bool]struct{}             `frugal:"8,default,set<bool>"`
+	Arrays  map[[2]int32]struct{}         `frugal:"9,default,set<list<i32>>"`
+	Enums   map[MapSetEnum]struct{}       `frugal:"10,default,set<MapSetEnum>"`
+	Float32 map[float32]bool              `frugal:"11,default,set<double>"`
+	Values  map[int32]map[string]struct{} `frugal:"12,default,map<i32:set<string>>"`
+}
+
+type MapSetEnum int64
+
+type SliceSetTypes struct {
+	I32s    []int32            `frugal:"1,default,set<i32>"`
+	Strs    []string           `frugal:"2,default,set<string>"`
+	Opt     []int64            `frugal:"3,optional,set<i64>"`
+	Elems   []*MapSetElem      `frugal:"4,default,set<MapSetElem>"`
+	Nested  map[string][]int16 `frugal:"5,default,map<string:set<i16>>"`
+	List    [][]float64        `frugal:"6,default,list<set<double>>"`
+	Uint    []uint8            `frugal:"7,default,set<byte>,unsigned"`
+	Bools   []bool             `frugal:"8,default,set<bool>"`
+	Arrays  [][]int32          `frugal:"9,default,set<list<i32>>"`
+	Enums   []MapSetEnum       `frugal:"10,default,set<MapSetEnum>"`
+	Float32 []float64          `frugal:"11,default,set<double>"`
+	Values  map[int32][]string `frugal:"12,default,map<i32:set<string>>"`
+}
+
+func testMapSetRoundTrip(t *testing.T, p0 *MapSetTypes) {
+	t.Helper()
+
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	p1 := &MapSetTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	buf := &bytes.Buffer{}
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), buf.Len()) // the order of elements may differ
+	p1 = &MapSetTypes{}
+	_, err = DecodeFrom(buf, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	p1 = &MapSetTypes{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+}
+
+func TestMapSet(t *testing.T) {
+	// single element sets, the same as slices on wire
+	p0 := &MapSetTypes{
+		I32s:    map[int32]struct{}{1: {}},
+		Strs:    map[string]bool{"a": true},
+		Opt:     map[int64]struct{}{-1: {}},
+		Elems:   map[*MapSetElem]struct{}{{X: 1}: {}},
+		Nested:  map[string]map[int16]bool{"x": {7: true}},
+		List:    []map[float64]struct{}{{1.5: {}}, {}},
+		Uint:    map[uint8]struct{}{255: {}},
+		Bools:   map[bool]struct{}{true: {}},
+		Arrays:  map[[2]int32]struct{}{{1, 2}: {}},
+		Enums:   map[MapSetEnum]struct{}{3: {}},
+		Float32: map[float32]bool{0.5: true},
+		Values:  map[int32]map[string]struct{}{1: {"b": {}}},
+	}
+	s0 := &SliceSetTypes{
+		I32s:    []int32{1},
+		Strs:    []string{"a"},
+		Opt:     []int64{-1},
+		Elems:   []*MapSetElem{{X: 1}},
+		Nested:  map[string][]int16{"x": {7}},
+		List:    [][]float64{{1.5}, {}},
+		Uint:    []uint8{255},
+		Bools:   []bool{true},
+		Arrays:  [][]int32{{1, 2}},
+		Enums:   []MapSetEnum{3},
+		Float32: []float64{0.5},
+		Values:  map[int32][]string{1: {"b"}},
+	}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	expect, err := Append(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	expect, err = AppendCompact(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	// pointer keys can't be compared by DeepEqual after decoding,
+	// and nil maps are decoded as empty ones
+	p0.Elems = map[*MapSetElem]struct{}{}
+	testMapSetRoundTrip(t, p0)
+
+	// multiple elements
+	p0 = &MapSetTypes{
+		I32s:    map[int32]struct{}{1: {}, 2: {}, 3: {}},
+		Strs:    map[string]bool{"a": true, "b": true},
+		Opt:     map[int64]struct{}{},
+		Nested:  map[string]map[int16]bool{"x": {1: true, 2: true}, "y": {}},
+		List:    []map[float64]struct{}{{1: {}, 2: {}}},
+		Uint:    map[uint8]struct{}{0: {}, 128: {}, 255: {}},
+		Bools:   map[bool]struct{}{true: {}, false: {}},
+		Arrays:  map[[2]int32]struct{}{{1, 2}: {}, {3, 4}: {}},
+		Enums:   map[MapSetEnum]struct{}{1: {}, 2: {}},
+		Float32: map[float32]bool{0.5: true, 1: true},
+		Values:  map[int32]map[string]struct{}{1: {"a": {}, "b": {}}},
+		Elems:   map[*MapSetElem]struct{}{},
+	}
+	testMapSetRoundTrip(t, p0)
+}
+
+func TestMapSetBoolFalse(t *testing.T) {
+	// keys of false values are not in the set
+	p0 := &MapSetTypes{Strs: map[string]bool{"a": false, "b": true, "c": false}}
+	s0 := &SliceSetTypes{Strs: []string{"b"}}
+
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	expect, err := Append(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	buf := &bytes.Buffer{}
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, buf.Bytes())
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	expect, err = AppendCompact(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	p1 := &MapSetTypes{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, map[string]bool{"b": true}, p1.Strs)
+}
+
+func TestMapSetOptional(t *testing.T) {
+	// nil optional sets are skipped, and empty ones are not
+	b, err := Append(nil, &MapSetTypes{})
+	assert.Nil(t, err)
+	expect, err := Append(nil, &SliceSetTypes{})
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	b, err = Append(nil, &MapSetTypes{Opt: map[int64]struct{}{}})
+	assert.Nil(t, err)
+	expect, err = Append(nil, &SliceSetTypes{Opt: []int64{}})
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+}
+
+func TestMapSetElemPointer(t *testing.T) {
+	p0 := &MapSetTypes{Elems: map[*MapSetElem]struct{}{{X: 1}: {}, {X: 2}: {}}}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+
+	p1 := &MapSetTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(p1.Elems))
+	sum := int32(0)
+	for e := range p1.Elems {
+		sum += e.X
+	}
+	assert.Equal(t, int32(3), sum)
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"encoding/binary"
+
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+const (
+	msgVersion1    = 0x80010000
+	msgVersionMask = 0xffff0000
+	msgTypeMask    = 0x0000ffff
+)
+
+var (
+	errMessageHeader  = thrift.NewProtocolException(thrift.INVALID_DATA, "message header: buf too small")
+	errMessageVersion = thrift.NewProtocolException(thrift.BAD_VERSION, "message header: bad version")
+)
+
+// applicationException has the same layout as TApplicationException,
+// it's encoded and decoded with the descriptors like any other struct.
+type applicationException struct {
+	Message string `frugal:"1,default,string"`
+	Type    int32  `frugal:"2,default,i32"`
+}
+
+// toApplicationException converts errors to applicationException for EXCEPTION messages.
+// User-defined exceptions are always replied within result structs,
+// so the payload of an EXCEPTION message can only be TApplicationException.
+func toApplicationException(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *thrift.ApplicationException:
+		return &applicationException{Message: x.Msg(), Type: x.TypeID()}
+	case interface {
+		error
+		TypeId() int32
+	}:
+		return &applicationException{Message: x.Error(), Type: x.TypeId()}
+	case error:
+		return &applicationException{Message: x.Error(), Type: thrift.UNKNOWN_APPLICATION_EXCEPTION}
+	}
+	return v
+}
+
+func messageHeaderSize(name string, strict bool) int {
+	if strict {
+		return 4 + 4 + len(name) + 4 // version|type, name, seqid
+	}
+	return 4 + len(name) + 1 + 4 // name, type, seqid
+}
+
+func appendMessageHeader(b []byte, name string, typeID thrift.TMessageType, seq int32, strict bool) []byte {
+	if strict {
+		b = appendUint32(b, uint32(msgVersion1)|uint32(typeID&msgTypeMask))
+		b = appendUint32(b, uint32(len(name)))
+		b = append(b, name...)
+	} else {
+		b = appendUint32(b, uint32(len(name)))
+		b = append(b, name...)
+		b = append(b, byte(typeID))
+	}
+	return appendUint32(b, uint32(seq))
+}
+
+// MessageEncodedSize returns the encoded size of a message with the given name and payload v.
+func MessageEncodedSize(name string, typeID thrift.TMessageType, strict bool, v interface{}) int {
+	if typeID == thrift.EXCEPTION {
+		v = toApplicationException(v)
+	}
+	return messageHeaderSize(name, strict) + EncodedSize(v)
+}
+
+// AppendMessage appends a message with the given header and payload v to b.
+//
+// For EXCEPTION messages, errors are encoded as TApplicationException.
+func AppendMessage(b []byte, name string, typeID thrift.TMessageType, seq int32, strict bool, v interface{}) ([]byte, error) {
+	if typeID == thrift.EXCEPTION {
+		v = toApplicationException(v)
+	}
+	b = appendMessageHeader(b, name, typeID, seq, strict)
+	return Append(b, v)
+}
+
+// DecodeMessageBegin decodes a strict or non-strict message header from b.
+func DecodeMessageBegin(b []byte) (name string, typeID thrift.TMessageType, seq int32, strict bool, n int, err error) {
+	if len(b) < 4 {
+		return "", 0, 0, false, 0, errMessageHeader
+	}
+	sz := int32(binary.BigEndian.Uint32(b))
+	if sz < 0 { // strict, starts with version|type
+		if uint32(sz)&msgVersionMask != msgVersion1 {
+			return "", 0, 0, false, 0, errMessageVersion
+		}
+		strict = true
+		typeID = thrift.TMessageType(uint32(sz) & msgTypeMask)
+		if len(b) < 8 {
+			return "", 0, 0, false, 0, errMessageHeader
+		}
+		sz = int32(binary.BigEndian.Uint32(b[4:]))
+		n = 8
+	} else {
+		n = 4
+	}
+	if sz < 0 {
+		return "", 0, 0, false, 0, errNegativeSize
+	}
+	if int(sz) > len(b)-n {
+		return "", 0, 0, false, 0, newSizeExceedsBufferException(int(sz), len(b)-n)
+	}
+	name = string(b[n : n+int(sz)])
+	n += int(sz)
+	if !strict {
+		if len(b) < n+1 {
+			return "", 0, 0, false, 0, errMessageHeader
+		}
+		typeID = thrift.TMessageType(b[n])
+		n++
+	}
+	if len(b) < n+4 {
+		return "", 0, 0, false, 0, errMessageHeader
+	}
+	seq = int32(binary.BigEndian.Uint32(b[n:]))
+	n += 4
+	return name, typeID, seq, strict, n, nil
+}
+
+// DecodeMessage decodes a message from b, and the payload to v.
+//
+// For EXCEPTION messages, the payload is decoded as TApplicationException,
+// and returned as a *thrift.ApplicationException error. v is left untouched.
+func DecodeMessage(b []byte, v interface{}) (name string, typeID thrift.TMessageType, seq int32, strict bool, n int, err error) {
+	name, typeID, seq, strict, n, err = DecodeMessageBegin(b)
+	if err != nil {
+		return
+	}
+	var i int
+	if typeID == thrift.EXCEPTION {
+		ex := &applicationException{}
+		if i, err = Decode(b[n:], ex); err == nil {
+			err = thrift.NewApplicationException(ex.Type, ex.Message)
+		}
+		n += i
+		return
+	}
+	i, err = Decode(b[n:], v)
+	n += i
+	return
+}
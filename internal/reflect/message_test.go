@@ -0,0 +1,93 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+func TestMessage(t *testing.T) {
+	p := &Msg{Message: "hello", Type: 1}
+	for _, strict := range []bool{true, false} {
+		b, err := AppendMessage(nil, "Echo", thrift.CALL, 7, strict, p)
+		assert.Nil(t, err)
+		assert.Equal(t, len(b), MessageEncodedSize("Echo", thrift.CALL, strict, p))
+
+		if strict { // same as the gopkg one
+			h := thrift.Binary.AppendMessageBegin(nil, "Echo", thrift.CALL, 7)
+			assert.BytesEqual(t, h, b[:len(h)])
+		}
+
+		p1 := &Msg{}
+		name, typeID, seq, strict1, n, err := DecodeMessage(b, p1)
+		assert.Nil(t, err)
+		assert.Equal(t, "Echo", name)
+		assert.Equal(t, thrift.CALL, typeID)
+		assert.Equal(t, int32(7), seq)
+		assert.Equal(t, strict, strict1)
+		assert.Equal(t, len(b), n)
+		assert.DeepEqual(t, p, p1)
+	}
+}
+
+func TestMessageException(t *testing.T) {
+	ex := thrift.NewApplicationException(thrift.UNKNOWN_METHOD, "unknown method")
+	b, err := AppendMessage(nil, "Echo", thrift.EXCEPTION, 1, true, ex)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), MessageEncodedSize("Echo", thrift.EXCEPTION, true, ex))
+	h := thrift.Binary.AppendMessageBegin(nil, "Echo", thrift.EXCEPTION, 1)
+	body := make([]byte, ex.BLength())
+	ex.FastWrite(body)
+	assert.BytesEqual(t, append(h, body...), b)
+
+	p := &Msg{Type: 1}
+	_, typeID, _, _, n, err := DecodeMessage(b, p)
+	assert.Equal(t, thrift.EXCEPTION, typeID)
+	assert.Equal(t, len(b), n)
+	assert.DeepEqual(t, ex, err)
+	assert.Equal(t, int32(1), p.Type) // untouched
+
+	// errors other than ApplicationException
+	b, err = AppendMessage(nil, "Echo", thrift.EXCEPTION, 1, false, errors.New("oops"))
+	assert.Nil(t, err)
+	_, _, _, _, _, err = DecodeMessage(b, p)
+	assert.DeepEqual(t, thrift.NewApplicationException(thrift.UNKNOWN_APPLICATION_EXCEPTION, "oops"), err)
+}
+
+func TestDecodeMessageBeginErrors(t *testing.T) {
+	b := appendMessageHeader(nil, "Echo", thrift.REPLY, 1, true)
+	for i := 0; i < len(b); i++ {
+		_, _, _, _, _, err := DecodeMessageBegin(b[:i])
+		assert.True(t, err != nil, i)
+	}
+	b = appendMessageHeader(nil, "Echo", thrift.REPLY, 1, false)
+	for i := 0; i < len(b); i++ {
+		_, _, _, _, _, err := DecodeMessageBegin(b[:i])
+		assert.True(t, err != nil, i)
+	}
+
+	_, _, _, _, _, err := DecodeMessageBegin([]byte{0x80, 0x02, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0})
+	assert.DeepEqual(t, errMessageVersion, err)
+	_, _, _, _, _, err = DecodeMessageBegin([]byte{0x80, 0x01, 0, 1, 0xff, 0, 0, 0, 0, 0, 0, 0})
+	assert.DeepEqual(t, errNegativeSize, err)
+	_, _, _, _, _, err = DecodeMessageBegin([]byte{0, 0, 0, 9, 0, 0, 0, 0})
+	assertSizeLimit(t, err)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/defs"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+// Pointers to slices or maps like *[]T or *map[K]V are tPOINTER,
+// of which t.V is the type of the slice or map, and t.WT is the wire type of t.V.
+// They're for optional fields which tell an absent container (nil pointer) from an empty one.
+// Like other pointers, they're dereferenced before calling funcs of tPOINTER,
+// and then the funcs of t.V are called with the dereferenced pointer.
+
+// isContainerPointer returns true if x is a pointer to slice or map, excluding []byte.
+func isContainerPointer(x *defs.Type) bool {
+	if x.T != defs.T_pointer {
+		return false
+	}
+	switch x.V.T {
+	case defs.T_list, defs.T_set, defs.T_map:
+		return !x.V.IsArray()
+	}
+	return false
+}
+
+func (t *tType) encodedPointerSize(p unsafe.Pointer) (int, error) {
+	return t.V.EncodedSizeFunc(*(*unsafe.Pointer)(p))
+}
+
+func appendPointer(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error) {
+	return t.V.AppendFunc(t.V, b, p, w)
+}
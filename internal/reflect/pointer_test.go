@@ -0,0 +1,134 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+type PointerElem struct {
+	X int32 `frugal:"1,default,i32"`
+}
+
+type PointerTypes struct {
+	List   *[]int32                 `frugal:"1,optional,list<i32>"`
+	Set    *[]string                `frugal:"2,optional,set<string>"`
+	Map    *map[string]*PointerElem `frugal:"3,optional,map<string:PointerElem>"`
+	MapSet *map[int64]struct{}      `frugal:"4,optional,set<i64>"`
+	Elems  *[]*PointerElem          `frugal:"5,optional,list<PointerElem>"`
+	Uint   *[]uint16                `frugal:"6,optional,list<i16>,unsigned,rangecheck"`
+}
+
+type PointerSliceTypes struct {
+	List   []int32                 `frugal:"1,optional,list<i32>"`
+	Set    []string                `frugal:"2,optional,set<string>"`
+	Map    map[string]*PointerElem `frugal:"3,optional,map<string:PointerElem>"`
+	MapSet []int64                 `frugal:"4,optional,set<i64>"`
+	Elems  []*PointerElem          `frugal:"5,optional,list<PointerElem>"`
+	Uint   []uint16                `frugal:"6,optional,list<i16>,unsigned,rangecheck"`
+}
+
+func testPointerRoundTrip(t *testing.T, p0 *PointerTypes, s0 *PointerSliceTypes) {
+	t.Helper()
+
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	expect, err := Append(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+	p1 := &PointerTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	buf := &bytes.Buffer{}
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, b, buf.Bytes())
+	p1 = &PointerTypes{}
+	_, err = DecodeFrom(buf, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	expect, err = AppendCompact(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+	p1 = &PointerTypes{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+}
+
+func TestPointerToContainer(t *testing.T) {
+	list := []int32{1, 2}
+	set := []string{"a"}
+	m := map[string]*PointerElem{"x": {X: 1}}
+	ms := map[int64]struct{}{7: {}}
+	elems := []*PointerElem{{X: 2}}
+	uints := []uint16{1, 2}
+	p0 := &PointerTypes{
+		List: &list, Set: &set, Map: &m, MapSet: &ms, Elems: &elems, Uint: &uints,
+	}
+	s0 := &PointerSliceTypes{
+		List: list, Set: set, Map: m, MapSet: []int64{7}, Elems: elems, Uint: uints,
+	}
+	testPointerRoundTrip(t, p0, s0)
+
+	// nil pointers are absent
+	testPointerRoundTrip(t, &PointerTypes{}, &PointerSliceTypes{})
+
+	// empty containers are present
+	emptyList := []int32{}
+	emptyMap := map[string]*PointerElem{}
+	emptyMapSet := map[int64]struct{}{}
+	p0 = &PointerTypes{List: &emptyList, Map: &emptyMap, MapSet: &emptyMapSet}
+	s0 = &PointerSliceTypes{List: []int32{}, Map: map[string]*PointerElem{}, MapSet: []int64{}}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	expect, err := Append(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+	p1 := &PointerTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.True(t, p1.List != nil && len(*p1.List) == 0)
+	assert.True(t, p1.Map != nil && len(*p1.Map) == 0)
+	assert.True(t, p1.MapSet != nil && len(*p1.MapSet) == 0)
+	assert.True(t, p1.Set == nil && p1.Elems == nil)
+
+	// a pointer to a nil slice is present too
+	var nilList []int32
+	b, err = Append(nil, &PointerTypes{List: &nilList})
+	assert.Nil(t, err)
+	p1 = &PointerTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.True(t, p1.List != nil && len(*p1.List) == 0)
+
+	// rangecheck works with the elements
+	uints = []uint16{1, 65535}
+	_, err = Append(nil, &PointerTypes{Uint: &uints})
+	assert.True(t, err != nil && strings.Contains(err.Error(), "overflows"), err)
+}
@@ -17,10 +17,15 @@
 package reflect
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/opts"
+	"github.com/cloudwego/gopkg/protocol/thrift"
 )
 
 func EncodedSize(v interface{}) int {
@@ -81,10 +86,14 @@ func Append(b []byte, v interface{}) ([]byte, error) {
 		// it checks in createStructDesc
 		p = rvPtr(rv)
 	}
-	return appendStruct(&tType{Sd: sd}, b, p)
+	return appendStruct(&tType{Sd: sd}, b, p, nil)
 }
 
 func Decode(b []byte, v interface{}) (int, error) {
+	return DecodeWithOptions(b, v, &opts.DefaultOptions)
+}
+
+func DecodeWithOptions(b []byte, v interface{}, o *opts.Options) (int, error) {
 	panicIfHackErr()
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr {
@@ -101,7 +110,166 @@ func Decode(b []byte, v interface{}) (int, error) {
 		return 0, err
 	}
 	d := decoderPool.Get().(*tDecoder)
-	n, err := d.Decode(b, rv.UnsafePointer(), sd, maxDepthLimit)
+	d.resetStrict(o, b)
+	n, err := d.Decode(b, rv.UnsafePointer(), sd, d.reset(o))
+	d.resetStrict(o, nil) // don't keep b in the pool
 	decoderPool.Put(d)
 	return n, err
 }
+
+func CompactEncodedSize(v interface{}) int {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		panic(fmt.Sprintf("unexpected err when parse fields: %s", err))
+	}
+	var p unsafe.Pointer
+	if rv.Kind() == reflect.Struct {
+		// unaddressable, need to copy to heap, and then get the ptr
+		prv := sd.rvPool.Get().(*reflect.Value)
+		defer sd.rvPool.Put(prv)
+		(*prv).Elem().Set(rv)
+		p = (*rvtype)(unsafe.Pointer(prv)).ptr
+	} else {
+		p = rvPtr(rv)
+	}
+	n, err := compactEncodedSize(&tType{Sd: sd}, p)
+	if err != nil {
+		panic(fmt.Sprintf("unexpected err: %s", err))
+	}
+	return n
+}
+
+func AppendCompact(b []byte, v interface{}) ([]byte, error) {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		return b, err
+	}
+	var p unsafe.Pointer
+	if rv.Kind() == reflect.Struct {
+		// unaddressable, need to copy to heap, and then get the ptr
+		prv := sd.rvPool.Get().(*reflect.Value)
+		defer sd.rvPool.Put(prv)
+		(*prv).Elem().Set(rv)
+		p = (*rvtype)(unsafe.Pointer(prv)).ptr
+	} else {
+		p = rvPtr(rv)
+	}
+	return appendCompactStruct(&tType{Sd: sd}, b, p)
+}
+
+func DecodeCompact(b []byte, v interface{}) (int, error) {
+	return DecodeCompactWithOptions(b, v, &opts.DefaultOptions)
+}
+
+func DecodeCompactWithOptions(b []byte, v interface{}, o *opts.Options) (int, error) {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return 0, errors.New("not a pointer")
+	}
+	if rv.IsNil() {
+		return 0, errors.New("can't decode nil pointer")
+	}
+	if rv.Elem().Kind() != reflect.Struct {
+		return 0, errors.New("not a pointer to a struct")
+	}
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		return 0, err
+	}
+	d := decoderPool.Get().(*tDecoder)
+	d.resetStrict(o, b)
+	n, err := d.DecodeCompact(b, rv.UnsafePointer(), sd, d.reset(o))
+	d.resetStrict(o, nil) // don't keep b in the pool
+	decoderPool.Put(d)
+	return n, err
+}
+
+func DecodeFrom(r io.Reader, v interface{}) (int, error) {
+	return DecodeFromWithOptions(r, v, &opts.DefaultOptions)
+}
+
+func DecodeFromWithOptions(r io.Reader, v interface{}, o *opts.Options) (int, error) {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr {
+		return 0, errors.New("not a pointer")
+	}
+	if rv.IsNil() {
+		return 0, errors.New("can't decode nil pointer")
+	}
+	if rv.Elem().Kind() != reflect.Struct {
+		return 0, errors.New("not a pointer to a struct")
+	}
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		return 0, err
+	}
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	d := streamDecoderPool.Get().(*streamDecoder)
+	d.Reset(br)
+	d.resetStrict(o, nil)
+	err = d.Decode(rv.UnsafePointer(), sd, d.reset(o))
+	n := d.n
+	d.Reset(nil)
+	streamDecoderPool.Put(d)
+	return n, err
+}
+
+func EncodeTo(w io.Writer, v interface{}) (int, error) {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		return 0, err
+	}
+	var p unsafe.Pointer
+	if rv.Kind() == reflect.Struct {
+		// unaddressable, need to copy to heap, and then get the ptr
+		prv := sd.rvPool.Get().(*reflect.Value)
+		defer sd.rvPool.Put(prv)
+		(*prv).Elem().Set(rv)
+		p = (*rvtype)(unsafe.Pointer(prv)).ptr // like `rvPtr` without copy
+	} else {
+		p = rvPtr(rv)
+	}
+	e := streamEncoderPool.Get().(*streamEncoder)
+	e.Reset(w)
+	err = e.EncodeStruct(sd, p)
+	if err == nil {
+		err = e.Flush()
+	}
+	n := e.n
+	e.Reset(nil)
+	streamEncoderPool.Put(e)
+	return n, err
+}
+
+// AppendNocopy is the same as Append, but large strings or binaries are written by w.WriteDirect.
+// cap(b) must be the end of the buffer, it's passed as remainCap of WriteDirect.
+func AppendNocopy(b []byte, w thrift.NocopyWriter, v interface{}) ([]byte, error) {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		return b, err
+	}
+	var p unsafe.Pointer
+	if rv.Kind() == reflect.Struct {
+		// unaddressable, need to copy to heap, and then get the ptr
+		prv := sd.rvPool.Get().(*reflect.Value)
+		defer sd.rvPool.Put(prv)
+		(*prv).Elem().Set(rv)
+		p = (*rvtype)(unsafe.Pointer(prv)).ptr // like `rvPtr` without copy
+	} else {
+		p = rvPtr(rv)
+	}
+	return appendStruct(&tType{Sd: sd}, b, p, w)
+}
@@ -19,9 +19,11 @@ package reflect
 import (
 	"fmt"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/internal/opts"
 )
 
 func init() {
@@ -86,6 +88,38 @@ func BenchmarkAppend(b *testing.B) {
 	}
 }
 
+// discardNocopyWriter drops the bytes written directly, like a netpoll buffer without flushing
+type discardNocopyWriter struct{}
+
+func (discardNocopyWriter) WriteDirect(b []byte, remainCap int) error { return nil }
+
+func BenchmarkAppendNocopy(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		str  string
+	}{
+		{"small", "hello"},
+		{"large", strings.Repeat("x", opts.NocopyWriteThreshold)},
+	} {
+		p := initTestTypesForBenchmark()
+		p.Str1 = &tc.str
+		n := EncodedSize(p)
+		buf := make([]byte, n)
+		b.Run(tc.name+"/w=nil", func(b *testing.B) {
+			b.SetBytes(int64(n))
+			for i := 0; i < b.N; i++ {
+				_, _ = AppendNocopy(buf[:0:n], nil, p)
+			}
+		})
+		b.Run(tc.name+"/w!=nil", func(b *testing.B) {
+			b.SetBytes(int64(n))
+			for i := 0; i < b.N; i++ {
+				_, _ = AppendNocopy(buf[:0:n], discardNocopyWriter{}, p)
+			}
+		})
+	}
+}
+
 func BenchmarkEncodedSize(b *testing.B) {
 	p := initTestTypesForBenchmark()
 	_ = EncodedSize(p) // pretouch
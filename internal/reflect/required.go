@@ -0,0 +1,161 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/defs"
+)
+
+// Required fields are checked when encoding if opts.CheckRequired is enabled, or by CheckRequired.
+//
+// A required field is not set if it's a nil pointer or a nil []byte,
+// or if it's promoted from a nil embedded struct pointer.
+// Other types always have values to encode, like zero values of scalars or empty containers.
+// A nil struct pointer in containers is encoded as an empty struct, none of its required fields are set.
+
+// checkRequired checks the required fields of the struct at base, nested structs are not checked.
+func (sd *structDesc) checkRequired(base unsafe.Pointer) error {
+	for _, id := range sd.requiredFieldIDs {
+		f := sd.GetField(id)
+		if base == nil || !f.isRequiredSet(base) {
+			return newRequiredFieldNotSetException(f.Name)
+		}
+	}
+	return nil
+}
+
+func (f *tField) isRequiredSet(base unsafe.Pointer) bool {
+	p := unsafe.Add(base, f.Offset)
+	if f.Embeds != nil {
+		if p = f.embeddedPointer(base); p == nil {
+			return false
+		}
+	}
+	t := f.Type
+	if t.IsPointer || t.Tag == defs.T_binary && t.RT.Kind() == reflect.Slice {
+		return *(*unsafe.Pointer)(p) != nil
+	}
+	return true
+}
+
+// CheckRequired returns an error if any of the required fields of v or its nested structs is not set,
+// the same as encoding with opts.CheckRequired enabled.
+func CheckRequired(v interface{}) error {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		return err
+	}
+	var p unsafe.Pointer
+	if rv.Kind() == reflect.Struct {
+		// unaddressable, need to copy to heap, and then get the ptr
+		prv := sd.rvPool.Get().(*reflect.Value)
+		defer sd.rvPool.Put(prv)
+		(*prv).Elem().Set(rv)
+		p = (*rvtype)(unsafe.Pointer(prv)).ptr
+	} else {
+		p = rvPtr(rv)
+	}
+	return checkRequiredStruct(sd, p)
+}
+
+func checkRequiredStruct(sd *structDesc, base unsafe.Pointer) error {
+	if err := sd.checkRequired(base); err != nil || base == nil {
+		return err
+	}
+	for _, f := range sd.fields {
+		if f.Type.SimpleType {
+			continue
+		}
+		p := unsafe.Add(base, f.Offset)
+		if f.Embeds != nil {
+			if p = f.embeddedPointer(base); p == nil {
+				continue
+			}
+		}
+		if f.CanSkipEncodeIfNil && *(*unsafe.Pointer)(p) == nil {
+			continue // not encoded
+		}
+		if err := checkRequiredValue(f.Type, p); err != nil {
+			return withFieldErr(err, sd, f)
+		}
+	}
+	return nil
+}
+
+// checkRequiredValue checks the structs in the value of type t which p points to.
+func checkRequiredValue(t *tType, p unsafe.Pointer) error {
+	if t.IsPointer {
+		p = *(*unsafe.Pointer)(p)
+		if t.T == tPOINTER {
+			if p == nil {
+				return nil
+			}
+			t = t.V
+		}
+	}
+	switch t.T {
+	case tSTRUCT:
+		return checkRequiredStruct(t.Sd, p)
+	case tARRAY:
+		if t.V.SimpleType {
+			return nil
+		}
+		for i := 0; i < t.Len; i++ {
+			if err := checkRequiredValue(t.V, unsafe.Add(p, i*t.V.Size)); err != nil {
+				return err
+			}
+		}
+	case tLIST, tSET:
+		if t.V.SimpleType {
+			return nil
+		}
+		s := (*sliceHeader)(p)
+		for i := 0; i < s.Len; i++ {
+			if err := checkRequiredValue(t.V, unsafe.Add(s.Data, i*t.V.Size)); err != nil {
+				return err
+			}
+		}
+	case tMAP:
+		if t.K.SimpleType && t.V.SimpleType {
+			return nil
+		}
+		kk, vv := mapEntries(t, p)
+		for i := range kk {
+			if err := checkRequiredValue(t.K, kk[i]); err != nil {
+				return err
+			}
+			if err := checkRequiredValue(t.V, vv[i]); err != nil {
+				return err
+			}
+		}
+	case tMAPSET:
+		if t.V.SimpleType {
+			return nil
+		}
+		for _, kp := range mapSetKeys(t, p) {
+			if err := checkRequiredValue(t.V, kp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/internal/opts"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+type RequiredInner struct {
+	X int32  `frugal:"1,required,i32"`
+	B []byte `frugal:"2,required,binary"`
+}
+
+type RequiredEmbedded struct {
+	E string `frugal:"20,required,string"`
+}
+
+type RequiredTypes struct {
+	*RequiredEmbedded
+	S     string                    `frugal:"1,required,string"`
+	Inner *RequiredInner            `frugal:"2,required,RequiredInner"`
+	Opt   *RequiredInner            `frugal:"3,optional,RequiredInner"`
+	Def   *RequiredInner            `frugal:"4,default,RequiredInner"`
+	List  []*RequiredInner          `frugal:"5,optional,list<RequiredInner>"`
+	Map   map[string]*RequiredInner `frugal:"6,optional,map<string:RequiredInner>"`
+	I64s  []int64                   `frugal:"7,required,list<i64>"`
+}
+
+func newRequiredTypes() *RequiredTypes {
+	return &RequiredTypes{
+		RequiredEmbedded: &RequiredEmbedded{},
+		Inner:            &RequiredInner{B: []byte{}},
+		Def:              &RequiredInner{B: []byte("b")},
+	}
+}
+
+func assertRequiredErr(t *testing.T, name string, err error) {
+	t.Helper()
+	var pe *thrift.ProtocolException
+	assert.True(t, errors.As(err, &pe), err)
+	assert.Equal(t, int32(thrift.INVALID_DATA), pe.TypeID(), err)
+	assert.True(t, strings.Contains(err.Error(), "required field \""+name+"\" is not set"), err)
+}
+
+func TestCheckRequired(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		f     func(p *RequiredTypes)
+		field string
+	}{
+		{"ok", func(p *RequiredTypes) {}, ""},
+		{"nil struct", func(p *RequiredTypes) { p.Inner = nil }, "Inner"},
+		{"nil binary", func(p *RequiredTypes) { p.Inner.B = nil }, "B"},
+		{"nil embedded", func(p *RequiredTypes) { p.RequiredEmbedded = nil }, "E"},
+		{"nil default struct", func(p *RequiredTypes) { p.Def = nil }, "X"},
+		{"nil optional struct", func(p *RequiredTypes) { p.Opt = nil }, ""},
+		{"optional struct", func(p *RequiredTypes) { p.Opt = &RequiredInner{} }, "B"},
+		{"list", func(p *RequiredTypes) { p.List = []*RequiredInner{{B: []byte{}}, {}} }, "B"},
+		{"list nil", func(p *RequiredTypes) { p.List = []*RequiredInner{nil} }, "X"},
+		{"map", func(p *RequiredTypes) { p.Map = map[string]*RequiredInner{"a": {}} }, "B"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newRequiredTypes()
+			tc.f(p)
+			check := func(err error) {
+				t.Helper()
+				if tc.field == "" {
+					assert.Nil(t, err)
+				} else {
+					assertRequiredErr(t, tc.field, err)
+				}
+			}
+			check(CheckRequired(p))
+			check(CheckRequired(*p))
+
+			// not checked by default
+			b, err := Append(nil, p)
+			assert.Nil(t, err)
+
+			old := opts.CheckRequired
+			opts.CheckRequired = true
+			defer func() { opts.CheckRequired = old }()
+			_, err = Append(nil, p)
+			check(err)
+			_, err = AppendCompact(nil, p)
+			check(err)
+			_, err = EncodeTo(&bytes.Buffer{}, p)
+			check(err)
+			_, err = AppendNocopy(nil, nil, p)
+			check(err)
+
+			// nil structs are encoded as empty structs without required fields
+			if tc.field == "X" {
+				_, err = Decode(b, &RequiredTypes{})
+				assertRequiredErr(t, tc.field, err)
+			}
+		})
+	}
+}
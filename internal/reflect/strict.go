@@ -0,0 +1,57 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/opts"
+)
+
+// decodeStrict rejects the fields which are skipped by decoders by default if enabled by opts.Options:
+// fields of unknown IDs, and fields of known IDs with unexpected wire types.
+type decodeStrict struct {
+	disallowUnknownFields   bool
+	disallowMismatchedTypes bool
+
+	begin unsafe.Pointer // the first byte to decode, for offsets of errors
+}
+
+// resetStrict resets the strictness to o for decoding b, b is nil for streams.
+func (s *decodeStrict) resetStrict(o *opts.Options, b []byte) {
+	s.disallowUnknownFields = o.DisallowUnknownFields
+	s.disallowMismatchedTypes = o.DisallowMismatchedFieldTypes
+	s.begin = unsafe.Pointer(unsafe.SliceData(b))
+}
+
+// offsetOf returns the offset of b in the buffer being decoded.
+func (s *decodeStrict) offsetOf(b []byte) int {
+	return int(uintptr(unsafe.Pointer(unsafe.SliceData(b))) - uintptr(s.begin))
+}
+
+// checkSkipped checks the field fid of wire type tp at off which is going to be skipped,
+// f is nil if the ID is unknown.
+func (s *decodeStrict) checkSkipped(sd *structDesc, f *tField, fid uint16, tp ttype, off int) error {
+	if f == nil {
+		if s.disallowUnknownFields {
+			return newUnknownFieldException(sd.Name(), fid, tp, off)
+		}
+	} else if s.disallowMismatchedTypes {
+		return newFieldTypeMismatchException(sd.Name(), fid, f.Type.WT, tp, off)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/internal/opts"
+	"github.com/cloudwego/gopkg/protocol/thrift"
+)
+
+type StrictA struct {
+	X int32  `frugal:"1,default,i32"`
+	S string `frugal:"2,default,string"`
+}
+
+type StrictOuter struct {
+	I int16    `frugal:"1,default,i16"`
+	A *StrictA `frugal:"2,default,StrictA"`
+}
+
+// StrictWire is StrictA with field 1 of another type and an unknown field 3
+type StrictWire struct {
+	X     string `frugal:"1,default,string"`
+	S     string `frugal:"2,default,string"`
+	Extra int64  `frugal:"3,default,i64"`
+}
+
+type StrictOuterWire struct {
+	I int16       `frugal:"1,default,i16"`
+	A *StrictWire `frugal:"2,default,StrictWire"`
+}
+
+func TestStrictDecode(t *testing.T) {
+	p0 := &StrictOuterWire{I: 1, A: &StrictWire{X: "x", S: "s", Extra: 1}}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	cb, err := AppendCompact(nil, p0)
+	assert.Nil(t, err)
+
+	const (
+		mismatchErr = "field 1 of struct reflect.StrictA expects type I32, got STRING at offset "
+		unknownErr  = "unknown field 3 of type I64 in struct reflect.StrictA at offset "
+	)
+	for _, tc := range []struct {
+		name string
+		o    opts.Options
+		err  string
+		off  string // for binary
+		coff string // for compact
+	}{
+		{"default", opts.Options{}, "", "", ""},
+		{"unknown", opts.Options{DisallowUnknownFields: true}, unknownErr, "24", "9"},
+		{"mismatch", opts.Options{DisallowMismatchedFieldTypes: true}, mismatchErr, "8", "3"},
+		{"both", opts.Options{DisallowUnknownFields: true, DisallowMismatchedFieldTypes: true}, mismatchErr, "8", "3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			check := func(err error, off string) {
+				t.Helper()
+				if tc.err == "" {
+					assert.Nil(t, err)
+					return
+				}
+				var pe *thrift.ProtocolException
+				assert.True(t, errors.As(err, &pe), err)
+				assert.Equal(t, int32(thrift.INVALID_DATA), pe.TypeID())
+				assert.True(t, strings.HasSuffix(err.Error(), tc.err+off), err)
+			}
+			_, err := DecodeWithOptions(b, &StrictOuter{}, &tc.o)
+			check(err, tc.off)
+			_, err = DecodeFromWithOptions(bytes.NewReader(b), &StrictOuter{}, &tc.o)
+			check(err, tc.off)
+			_, err = DecodeCompactWithOptions(cb, &StrictOuter{}, &tc.o)
+			check(err, tc.coff)
+		})
+	}
+
+	// known fields of expected types only
+	b, err = Append(nil, &StrictOuter{I: 1, A: &StrictA{X: 1}})
+	assert.Nil(t, err)
+	o := opts.Options{DisallowUnknownFields: true, DisallowMismatchedFieldTypes: true}
+	_, err = DecodeWithOptions(b, &StrictOuter{}, &o)
+	assert.Nil(t, err)
+}
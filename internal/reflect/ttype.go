@@ -23,6 +23,7 @@ import (
 	"unsafe"
 
 	"github.com/cloudwego/frugal/internal/defs"
+	"github.com/cloudwego/gopkg/protocol/thrift"
 )
 
 type ttype uint8
@@ -47,9 +48,20 @@ const (
 	tUTF16  ttype = 17
 
 	// internal use only
-	tENUM ttype = 0xfe // XXX: kitex issue, int64, but encode as int32 ...
+	tENUM  ttype = 0xfe // XXX: kitex issue, int64, but encode as int32 ...
+	tFLOAT ttype = 0xfd // float32, but encode as double
+
+	tARRAY     ttype = 0xfc // [N]T, but encode as list or set
+	tBYTEARRAY ttype = 0xfb // [N]byte, but encode as binary
+	tCODEC     ttype = 0xfa // custom codec, encode as the registered type
+	tMAPSET    ttype = 0xf9 // map[K]struct{} or map[K]bool, but encode as set
+	tPOINTER   ttype = 0xf8 // *[]T or *map[K]V, encode as the type it points to
+	tUUID      ttype = 0xf7 // [16]byte, encode as wUUID
 )
 
+// wUUID is the wire type of tUUID, which takes the place of tUTF8 in Thrift.
+const wUUID = tUTF8
+
 var t2s = [256]string{
 	tBOOL:   "BOOL",
 	tI08:    "I08",
@@ -62,7 +74,16 @@ var t2s = [256]string{
 	tMAP:    "MAP",
 	tSET:    "SET",
 	tLIST:   "LIST",
+	wUUID:   "UUID",
 	tENUM:   "ENUM",
+	tFLOAT:  "FLOAT",
+
+	tARRAY:     "ARRAY",
+	tBYTEARRAY: "BYTEARRAY",
+	tCODEC:     "CODEC",
+	tMAPSET:    "MAPSET",
+	tPOINTER:   "POINTER",
+	tUUID:      "UUID",
 }
 
 func ttype2str(t ttype) string {
@@ -81,10 +102,12 @@ var simpleTypes = [256]bool{
 	tI32:    true,
 	tI64:    true,
 	tENUM:   true,
+	tFLOAT:  true,
 	tSTRING: true,
+	tUUID:   true,
 }
 
-type appendFuncType func(t *tType, b []byte, p unsafe.Pointer) ([]byte, error)
+type appendFuncType func(t *tType, b []byte, p unsafe.Pointer, w thrift.NocopyWriter) ([]byte, error)
 
 type tType struct {
 	T ttype
@@ -109,6 +132,15 @@ type tType struct {
 	IsPointer  bool // true if t.Tag == defs.T_pointer
 	SimpleType bool // true if simpleTypes[t.T]
 	FixedSize  int  // typeToSize[t.T]
+	Exact      bool // true if decoded values must be checked, see EnumValid and narrowFloat
+	Len        int  // for tARRAY, tBYTEARRAY, the length of the array
+	SetBool    bool // for tMAPSET, true for map[K]bool
+
+	// for tCODEC
+	Codec *Codec
+
+	// for tENUM, it reports whether a value is valid, nil if the enum is not registered
+	EnumValid func(v int64) bool
 
 	// for tSTRUCT
 	Sd *structDesc
@@ -130,6 +162,8 @@ func (t *tType) Equal(p0, p1 unsafe.Pointer) bool {
 		return *(*int8)(p0) == *(*int8)(p1)
 	case tDOUBLE:
 		return *(*float64)(p0) == *(*float64)(p1)
+	case tFLOAT:
+		return *(*float32)(p0) == *(*float32)(p1)
 	case tI16:
 		return *(*int16)(p0) == *(*int16)(p1)
 	case tI32:
@@ -138,6 +172,8 @@ func (t *tType) Equal(p0, p1 unsafe.Pointer) bool {
 		return *(*int64)(p0) == *(*int64)(p1)
 	case tSTRING:
 		return *(*string)(p0) == *(*string)(p1)
+	case tUUID:
+		return *(*[16]byte)(p0) == *(*[16]byte)(p1)
 	}
 	return false
 }
@@ -166,8 +202,41 @@ func newTType(x *defs.Type) *tType {
 	t.T = ttype(x.Tag())
 	t.WT = t.T
 	t.Tag = x.T
-	if x.IsEnum() {
+	if t.T == wUUID {
+		t.T = tUUID
+	} else if isContainerPointer(x) {
+		t.T = tPOINTER
+	} else if x.IsEnum() {
 		t.T = tENUM
+		if x.T == defs.T_pointer {
+			t.EnumValid = enumValidators[x.V.S]
+		} else {
+			t.EnumValid = enumValidators[x.S]
+		}
+		t.Exact = t.EnumValid != nil
+	} else if t.T == tDOUBLE && x.IsFloat32() {
+		t.T = tFLOAT
+	} else if x.IsCodec() {
+		t.T = tCODEC
+		if x.T == defs.T_pointer {
+			t.Codec = codecs[x.V.S]
+		} else {
+			t.Codec = codecs[x.S]
+		}
+	} else if x.IsArray() {
+		rt := x.S
+		if t.T == tSTRING {
+			t.T = tBYTEARRAY
+		} else {
+			t.T = tARRAY
+		}
+		if x.T == defs.T_pointer {
+			rt = rt.Elem()
+		}
+		t.Len = rt.Len()
+	} else if x.IsMapSet() {
+		t.T = tMAPSET
+		t.SetBool = x.S.Elem().Kind() == reflect.Bool
 	}
 	t.RT = x.S
 	t.Size = int(x.S.Size())
@@ -178,7 +247,7 @@ func newTType(x *defs.Type) *tType {
 		t.MallocAbiType = rtTypePtr(t.RT) // pass to mallocgc
 	}
 
-	if t.T == tMAP {
+	if t.T == tMAP || t.T == tMAPSET {
 		t.RV = reflect.New(t.RT) // alloc on heap, make it addressable
 		t.RV = t.RV.Elem()
 		t.MapTmpVarsPool = initOrGetMapTmpVarsPool(t)
@@ -193,6 +262,14 @@ func newTType(x *defs.Type) *tType {
 		t.EncodedSizeFunc = t.encodedListSize
 	case tSTRUCT:
 		t.EncodedSizeFunc = t.EncodedSize
+	case tARRAY, tBYTEARRAY:
+		t.EncodedSizeFunc = t.encodedArraySize
+	case tCODEC:
+		t.EncodedSizeFunc = t.encodedCodecSize
+	case tMAPSET:
+		t.EncodedSizeFunc = t.encodedMapSetSize
+	case tPOINTER:
+		t.EncodedSizeFunc = t.encodedPointerSize
 	}
 	if x.K != nil {
 		t.K = newTType(x.K)
@@ -207,15 +284,17 @@ func newTType(x *defs.Type) *tType {
 		updateMapAppendFunc(t)
 	case tSTRUCT:
 		t.AppendFunc = appendStruct
+	case tARRAY, tBYTEARRAY:
+		t.AppendFunc = appendArray
+	case tCODEC:
+		t.AppendFunc = appendCodec
+	case tMAPSET:
+		t.AppendFunc = appendMapSet
+	case tPOINTER:
+		t.AppendFunc = appendPointer
 	default:
 		t.AppendFunc = appendAny
 	}
-	if t.IsPointer && t.V.IsPointer {
-		// XXX: make it simple... not support it, it's not common
-		// The code is not generated by thriftgo?
-		// it makes thing complicated, coz we can only get the data recursively.
-		panic("doesn't support multilevel pointers like **p")
-	}
 	return t
 }
 
@@ -245,14 +324,11 @@ func (t *tType) EncodedSize(base unsafe.Pointer) (int, error) {
 	ret := sd.fixedLenFieldSize
 	for _, i := range sd.varLenFields {
 		f := sd.fields[i]
-		p := unsafe.Add(base, f.Offset)
-		if f.CanSkipEncodeIfNil && *(*unsafe.Pointer)(p) == nil {
+		p := f.encodedPointer(base)
+		if p == nil {
 			continue
 		}
 		t := f.Type
-		if f.CanSkipIfDefault && t.Equal(f.Default, p) {
-			continue
-		}
 		if n := t.FixedSize; n > 0 {
 			ret += (fieldHeaderLen + int(n))
 			// fast skip types like tBOOL, tBYTE, tDOUBLE, tI16, tI32, tI64
@@ -272,9 +348,7 @@ func (t *tType) EncodedSize(base unsafe.Pointer) (int, error) {
 		}
 		ret += n
 	}
-	if sd.hasUnknownFields {
-		ret += len(*(*[]byte)(unsafe.Add(base, sd.unknownFieldsOffset)))
-	}
+	ret += len(sd.getUnknownFields(base))
 	ret += 1 // tSTOP
 	return ret, nil
 }
@@ -309,12 +383,12 @@ func (t *tType) encodedMapSize(p unsafe.Pointer) (int, error) {
 	it := newMapIter(rvWithPtr(t.RV, p))
 	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
 		// Key
-		// tSTRING, tSTRUCT
+		// tSTRING, tSTRUCT, tARRAY, tBYTEARRAY
 		if !doneK {
 			if kt.T == tSTRING {
 				ret += encodedStringSize(kp)
 			} else {
-				n, err := kt.EncodedSize(kp)
+				n, err := kt.EncodedSizeFunc(kp)
 				if err != nil {
 					return ret, err
 				}
@@ -344,28 +418,28 @@ func (t *tType) encodedListSize(p unsafe.Pointer) (int, error) {
 	if *(*unsafe.Pointer)(p) == nil {
 		return listHeaderLen, nil // 0-len list
 	}
-	vt := t.V
 	h := (*sliceHeader)(p)
+	return encodedListElemsSize(t.V, h.Data, h.Len)
+}
+
+// encodedListElemsSize returns the size of list header and n elements of type vt starting from vp.
+func encodedListElemsSize(vt *tType, vp unsafe.Pointer, n int) (int, error) {
 	if vt.FixedSize > 0 {
-		return listHeaderLen + (h.Len * vt.FixedSize), nil
+		return listHeaderLen + (n * vt.FixedSize), nil
 	}
 	ret := listHeaderLen
-	if h.Len == 0 {
-		return ret, nil
-	}
-	vp := h.Data
-	for i := 0; i < h.Len; i++ {
+	for i := 0; i < n; i++ {
 		if i != 0 {
 			vp = unsafe.Add(vp, vt.Size) //  move to next element
 		}
 		if vt.T == tSTRING {
 			ret += encodedStringSize(vp)
 		} else {
-			n, err := vt.EncodedSizeFunc(vp)
+			sz, err := vt.EncodedSizeFunc(vp)
 			if err != nil {
 				return ret, err
 			}
-			ret += n
+			ret += sz
 		}
 	}
 	return ret, nil
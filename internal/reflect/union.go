@@ -0,0 +1,76 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+// UnionFieldID returns the ID of the field set in the union v, which is registered by RegisterUnion.
+func UnionFieldID(v interface{}) (uint16, error) {
+	panicIfHackErr()
+	rv := reflect.ValueOf(v)
+	sd, err := getOrcreateStructDesc(rv)
+	if err != nil {
+		return 0, err
+	}
+	if !sd.isUnion {
+		return 0, fmt.Errorf("%s is not a union", sd.Name())
+	}
+	var p unsafe.Pointer
+	if rv.Kind() == reflect.Struct {
+		// unaddressable, need to copy to heap, and then get the ptr
+		prv := sd.rvPool.Get().(*reflect.Value)
+		defer sd.rvPool.Put(prv)
+		(*prv).Elem().Set(rv)
+		p = (*rvtype)(unsafe.Pointer(prv)).ptr
+	} else {
+		p = rvPtr(rv)
+	}
+	f, err := sd.unionField(p)
+	if err != nil {
+		return 0, err
+	}
+	return f.ID, nil
+}
+
+// unionField returns the field set in the union at base, all fields of unions can be nil.
+func (sd *structDesc) unionField(base unsafe.Pointer) (*tField, error) {
+	var ret *tField
+	n := 0
+	if base == nil {
+		return nil, newUnionFieldsException(sd.Name(), n)
+	}
+	for _, f := range sd.fields {
+		p := unsafe.Add(base, f.Offset)
+		if f.Embeds != nil {
+			if p = f.embeddedPointer(base); p == nil {
+				continue
+			}
+		}
+		if *(*unsafe.Pointer)(p) != nil {
+			ret = f
+			n++
+		}
+	}
+	if n != 1 {
+		return nil, newUnionFieldsException(sd.Name(), n)
+	}
+	return ret, nil
+}
@@ -0,0 +1,387 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"hash/maphash"
+	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/defs"
+	"github.com/cloudwego/frugal/internal/opts"
+)
+
+// Elements of sets are checked for uniqueness if opts.CheckSetUniqueness is enabled,
+// both when encoding and decoding.
+//
+// Elements of comparable Go types like int32 or string are checked by Go maps directly.
+// Others like structs or lists are checked by hashValue and equalValue,
+// which walk the values by the descriptors instead of Go types:
+// struct pointers are equal if the structs they point to are equal,
+// and nested sets and maps are equal regardless of the order of elements.
+
+var hashSeed = maphash.MakeSeed()
+
+// checkSetElems checks the elements of the set p points to,
+// p is a slice of tSET, an array of tARRAY encoded as set, or a map of tMAPSET.
+func (t *tType) checkSetElems(p unsafe.Pointer) error {
+	if !opts.CheckSetUniqueness || t.WT != tSET {
+		return nil
+	}
+	switch t.T {
+	case tSET:
+		return checkUniqueness(t.V, (*sliceHeader)(p))
+	case tARRAY:
+		return checkUniqueness(t.V, &sliceHeader{Data: p, Len: t.Len, Cap: t.Len})
+	case tMAPSET:
+		// keys of Go maps are unique, except struct pointers which are compared by address
+		if !t.V.IsPointer {
+			return nil
+		}
+		keys := mapSetKeys(t, p)
+		for i, kp := range keys {
+			keys[i] = *(*unsafe.Pointer)(kp) // the struct pointers
+		}
+		return checkUniqueness(t.V, (*sliceHeader)(unsafe.Pointer(&keys)))
+	}
+	return nil
+}
+
+// checkDecodedMapSet checks if l elements decoded from wire are all in the map of tMAPSET,
+// duplicated elements are merged into the same key otherwise.
+func (t *tType) checkDecodedMapSet(p unsafe.Pointer, l int) error {
+	if !opts.CheckSetUniqueness {
+		return nil
+	}
+	if t.V.IsPointer {
+		return t.checkSetElems(p)
+	}
+	if maplen(*(*unsafe.Pointer)(p)) != l {
+		return newDuplicateSetElemException(t.V)
+	}
+	return nil
+}
+
+// checkUniqueness returns an error if there are equal elements in the slice h of type t.
+func checkUniqueness(t *tType, h *sliceHeader) error {
+	if h.Len < 2 {
+		return nil
+	}
+	var uniq bool
+	switch t.T {
+	case tBOOL:
+		uniq = checkUnique(unsafe.Slice((*bool)(h.Data), h.Len))
+	case tI08:
+		uniq = checkUnique(unsafe.Slice((*int8)(h.Data), h.Len))
+	case tI16:
+		uniq = checkUnique(unsafe.Slice((*int16)(h.Data), h.Len))
+	case tI32:
+		uniq = checkUnique(unsafe.Slice((*int32)(h.Data), h.Len))
+	case tI64, tENUM:
+		uniq = checkUnique(unsafe.Slice((*int64)(h.Data), h.Len))
+	case tDOUBLE:
+		uniq = checkUnique(unsafe.Slice((*float64)(h.Data), h.Len))
+	case tFLOAT:
+		uniq = checkUnique(unsafe.Slice((*float32)(h.Data), h.Len))
+	case tSTRING:
+		if t.Tag == defs.T_binary {
+			uniq = checkUniqueByHash(t, h) // []byte is not comparable
+		} else {
+			uniq = checkUnique(unsafe.Slice((*string)(h.Data), h.Len))
+		}
+	case tUUID:
+		uniq = checkUnique(unsafe.Slice((*[16]byte)(h.Data), h.Len))
+	default:
+		uniq = checkUniqueByHash(t, h)
+	}
+	if !uniq {
+		return newDuplicateSetElemException(t)
+	}
+	return nil
+}
+
+func checkUnique[T comparable](vv []T) bool {
+	m := make(map[T]struct{}, len(vv))
+	for _, v := range vv {
+		if _, ok := m[v]; ok {
+			return false
+		}
+		m[v] = struct{}{}
+	}
+	return true
+}
+
+func checkUniqueByHash(t *tType, h *sliceHeader) bool {
+	m := make(map[uint64][]unsafe.Pointer, h.Len) // hash -> elements
+	for i := 0; i < h.Len; i++ {
+		p := unsafe.Add(h.Data, i*t.Size)
+		k := hashOf(t, p)
+		for _, q := range m[k] {
+			if equalValue(t, p, q) {
+				return false
+			}
+		}
+		m[k] = append(m[k], p)
+	}
+	return true
+}
+
+func hashOf(t *tType, p unsafe.Pointer) uint64 {
+	var h maphash.Hash
+	h.SetSeed(hashSeed)
+	hashValue(&h, t, p)
+	return h.Sum64()
+}
+
+func hashUint64(h *maphash.Hash, v uint64) {
+	var b [8]byte
+	*(*uint64)(unsafe.Pointer(&b)) = v
+	_, _ = h.Write(b[:])
+}
+
+// hashValue writes the value of type t which p points to, equal values by equalValue have the same hash.
+func hashValue(h *maphash.Hash, t *tType, p unsafe.Pointer) {
+	if t.IsPointer {
+		if p = *(*unsafe.Pointer)(p); p == nil {
+			_ = h.WriteByte(0)
+			return
+		}
+		_ = h.WriteByte(1)
+		if t.T == tPOINTER {
+			t = t.V
+		}
+	}
+	switch t.T {
+	case tBOOL, tBYTE:
+		_ = h.WriteByte(*(*byte)(p))
+	case tI16:
+		hashUint64(h, uint64(*(*uint16)(p)))
+	case tI32:
+		hashUint64(h, uint64(*(*uint32)(p)))
+	case tI64, tENUM:
+		hashUint64(h, *(*uint64)(p))
+	case tDOUBLE:
+		v := *(*float64)(p)
+		if v == 0 {
+			v = 0 // -0 == +0
+		}
+		hashUint64(h, *(*uint64)(unsafe.Pointer(&v)))
+	case tFLOAT:
+		v := *(*float32)(p)
+		if v == 0 {
+			v = 0 // -0 == +0
+		}
+		hashUint64(h, uint64(*(*uint32)(unsafe.Pointer(&v))))
+	case tSTRING: // also works for []byte
+		s := *(*string)(p)
+		hashUint64(h, uint64(len(s)))
+		_, _ = h.WriteString(s)
+	case tUUID:
+		_, _ = h.Write((*[16]byte)(p)[:])
+	case tBYTEARRAY:
+		_, _ = h.Write(unsafe.Slice((*byte)(p), t.Len))
+	case tCODEC:
+		b, _ := t.Codec.Append(nil, p)
+		_, _ = h.Write(b)
+	case tARRAY:
+		for i := 0; i < t.Len; i++ {
+			hashValue(h, t.V, unsafe.Add(p, i*t.V.Size))
+		}
+	case tLIST, tSET:
+		s := (*sliceHeader)(p)
+		hashUint64(h, uint64(s.Len))
+		var sum uint64
+		for i := 0; i < s.Len; i++ {
+			vp := unsafe.Add(s.Data, i*t.V.Size)
+			if t.T == tLIST {
+				hashValue(h, t.V, vp)
+			} else {
+				sum += hashOf(t.V, vp) // order independent
+			}
+		}
+		hashUint64(h, sum)
+	case tMAP:
+		var sum uint64
+		kk, vv := mapEntries(t, p)
+		for i := range kk {
+			sum += hashOf(t.K, kk[i])*31 + hashOf(t.V, vv[i])
+		}
+		hashUint64(h, uint64(len(kk)))
+		hashUint64(h, sum)
+	case tMAPSET:
+		var sum uint64
+		kk := mapSetKeys(t, p)
+		for _, kp := range kk {
+			sum += hashOf(t.V, kp)
+		}
+		hashUint64(h, uint64(len(kk)))
+		hashUint64(h, sum)
+	case tSTRUCT:
+		for _, f := range t.Sd.fields {
+			fp := unsafe.Add(p, f.Offset)
+			if f.Embeds != nil {
+				if fp = f.embeddedPointer(p); fp == nil {
+					_ = h.WriteByte(0)
+					continue
+				}
+			}
+			hashValue(h, f.Type, fp)
+		}
+	}
+}
+
+// equalValue returns true if the values of type t which p0 and p1 point to are equal.
+func equalValue(t *tType, p0, p1 unsafe.Pointer) bool {
+	if t.IsPointer {
+		p0, p1 = *(*unsafe.Pointer)(p0), *(*unsafe.Pointer)(p1)
+		if p0 == nil || p1 == nil {
+			return p0 == p1
+		}
+		if t.T == tPOINTER {
+			t = t.V
+		}
+	}
+	if t.SimpleType {
+		return t.Equal(p0, p1) // tSTRING also works for []byte
+	}
+	switch t.T {
+	case tBYTEARRAY:
+		return bytes.Equal(unsafe.Slice((*byte)(p0), t.Len), unsafe.Slice((*byte)(p1), t.Len))
+	case tCODEC:
+		b0, err0 := t.Codec.Append(nil, p0)
+		b1, err1 := t.Codec.Append(nil, p1)
+		return err0 == nil && err1 == nil && bytes.Equal(b0, b1)
+	case tARRAY:
+		for i := 0; i < t.Len; i++ {
+			if !equalValue(t.V, unsafe.Add(p0, i*t.V.Size), unsafe.Add(p1, i*t.V.Size)) {
+				return false
+			}
+		}
+		return true
+	case tLIST, tSET:
+		s0, s1 := (*sliceHeader)(p0), (*sliceHeader)(p1)
+		if s0.Len != s1.Len {
+			return false
+		}
+		if t.T == tSET {
+			ee := sliceElems(t, p1)
+			for _, vp := range sliceElems(t, p0) {
+				if !containsValue(t.V, ee, vp) {
+					return false
+				}
+			}
+			return true
+		}
+		for i := 0; i < s0.Len; i++ {
+			if !equalValue(t.V, unsafe.Add(s0.Data, i*t.V.Size), unsafe.Add(s1.Data, i*t.V.Size)) {
+				return false
+			}
+		}
+		return true
+	case tMAP:
+		kk0, vv0 := mapEntries(t, p0)
+		kk1, vv1 := mapEntries(t, p1)
+		if len(kk0) != len(kk1) {
+			return false
+		}
+	next:
+		for i := range kk0 {
+			for j := range kk1 {
+				if equalValue(t.K, kk0[i], kk1[j]) {
+					if !equalValue(t.V, vv0[i], vv1[j]) {
+						return false
+					}
+					continue next
+				}
+			}
+			return false
+		}
+		return true
+	case tMAPSET:
+		kk0, kk1 := mapSetKeys(t, p0), mapSetKeys(t, p1)
+		if len(kk0) != len(kk1) {
+			return false
+		}
+		for _, kp := range kk0 {
+			if !containsValue(t.V, kk1, kp) {
+				return false
+			}
+		}
+		return true
+	case tSTRUCT:
+		for _, f := range t.Sd.fields {
+			fp0, fp1 := unsafe.Add(p0, f.Offset), unsafe.Add(p1, f.Offset)
+			if f.Embeds != nil {
+				fp0, fp1 = f.embeddedPointer(p0), f.embeddedPointer(p1)
+				if fp0 == nil || fp1 == nil {
+					if fp0 != fp1 {
+						return false
+					}
+					continue
+				}
+			}
+			if !equalValue(f.Type, fp0, fp1) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// containsValue returns true if one of the values of type t which pp point to equals to the one p points to.
+func containsValue(t *tType, pp []unsafe.Pointer, p unsafe.Pointer) bool {
+	for _, q := range pp {
+		if equalValue(t, q, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// sliceElems returns pointers to the elements of the slice of type t which p points to.
+func sliceElems(t *tType, p unsafe.Pointer) []unsafe.Pointer {
+	s := (*sliceHeader)(p)
+	ret := make([]unsafe.Pointer, s.Len)
+	for i := range ret {
+		ret[i] = unsafe.Add(s.Data, i*t.V.Size)
+	}
+	return ret
+}
+
+// mapSetKeys returns pointers to the elements of the set of tMAPSET which p points to.
+func mapSetKeys(t *tType, p unsafe.Pointer) []unsafe.Pointer {
+	var ret []unsafe.Pointer
+	_ = t.rangeMapSet(p, func(kp unsafe.Pointer) error {
+		ret = append(ret, kp)
+		return nil
+	})
+	return ret
+}
+
+// mapEntries returns pointers to the keys and values of the map of tMAP which p points to.
+func mapEntries(t *tType, p unsafe.Pointer) (kk, vv []unsafe.Pointer) {
+	if *(*unsafe.Pointer)(p) == nil {
+		return nil, nil
+	}
+	it := newMapIter(rvWithPtr(t.RV, p))
+	for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
+		kk = append(kk, kp)
+		vv = append(vv, vp)
+	}
+	return kk, vv
+}
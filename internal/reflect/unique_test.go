@@ -0,0 +1,169 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/internal/opts"
+)
+
+type UniqueElem struct {
+	X    int32            `frugal:"1,default,i32"`
+	S    *string          `frugal:"2,optional,string"`
+	List []int64          `frugal:"3,default,list<i64>"`
+	Map  map[string][]int `frugal:"4,default,map<string:set<i64>>"`
+}
+
+type UniqueTypes struct {
+	I32s    []int32                  `frugal:"1,optional,set<i32>"`
+	Strs    []string                 `frugal:"2,optional,set<string>"`
+	Bins    [][]byte                 `frugal:"3,optional,set<binary>"`
+	Elems   []*UniqueElem            `frugal:"4,optional,set<UniqueElem>"`
+	Lists   [][]int32                `frugal:"5,optional,set<list<i32>>"`
+	Sets    [][]int32                `frugal:"6,optional,set<set<i32>>"`
+	Array   *[2]float64              `frugal:"7,optional,set<double>"`
+	MapSet  map[*UniqueElem]bool     `frugal:"8,optional,set<UniqueElem>"`
+	Maps    []map[string]int16       `frugal:"9,optional,set<map<string:i16>>"`
+	NotSet  []int32                  `frugal:"10,optional,list<i32>"`
+	Nested  map[string][]*UniqueElem `frugal:"11,optional,map<string:set<UniqueElem>>"`
+	I32Set  map[int32]struct{}       `frugal:"12,optional,set<i32>"`
+	Arrays  [][2]byte                `frugal:"13,optional,set<binary>"`
+	Float32 []float32                `frugal:"14,optional,set<double>"`
+}
+
+func withSetUniqueness(t *testing.T) {
+	old := opts.CheckSetUniqueness
+	opts.CheckSetUniqueness = true
+	t.Cleanup(func() { opts.CheckSetUniqueness = old })
+}
+
+func testUniqueness(t *testing.T, p *UniqueTypes, uniq bool) {
+	t.Helper()
+
+	// encoding
+	opts.CheckSetUniqueness = false
+	b, err := Append(nil, p)
+	assert.Nil(t, err)
+	cb, err := AppendCompact(nil, p)
+	assert.Nil(t, err)
+	opts.CheckSetUniqueness = true
+
+	check := func(err error) {
+		t.Helper()
+		if uniq {
+			assert.Nil(t, err)
+		} else {
+			assert.True(t, err != nil && strings.Contains(err.Error(), "duplicate elements in set"), err)
+		}
+	}
+	_, err = Append(nil, p)
+	check(err)
+	_, err = AppendCompact(nil, p)
+	check(err)
+	_, err = EncodeTo(&bytes.Buffer{}, p)
+	check(err)
+
+	// decoding
+	_, err = Decode(b, &UniqueTypes{})
+	check(err)
+	_, err = DecodeCompact(cb, &UniqueTypes{})
+	check(err)
+	_, err = DecodeFrom(bytes.NewReader(b), &UniqueTypes{})
+	check(err)
+}
+
+func TestSetUniqueness(t *testing.T) {
+	withSetUniqueness(t)
+	s1, s2 := "a", "a"
+	for _, tc := range []struct {
+		name string
+		p    *UniqueTypes
+		uniq bool
+	}{
+		{"empty", &UniqueTypes{}, true},
+		{"i32", &UniqueTypes{I32s: []int32{1, 2, 3}}, true},
+		{"i32 dup", &UniqueTypes{I32s: []int32{1, 2, 1}}, false},
+		{"string dup", &UniqueTypes{Strs: []string{"a", "a"}}, false},
+		{"binary", &UniqueTypes{Bins: [][]byte{[]byte("a"), []byte("ab")}}, true},
+		{"binary dup", &UniqueTypes{Bins: [][]byte{[]byte("a"), []byte("a")}}, false},
+		{"struct", &UniqueTypes{Elems: []*UniqueElem{{X: 1}, {X: 2}, {X: 1, S: &s1}}}, true},
+		{"struct dup", &UniqueTypes{Elems: []*UniqueElem{{X: 1, S: &s1}, {X: 2}, {X: 1, S: &s2}}}, false},
+		{"struct nested", &UniqueTypes{Elems: []*UniqueElem{
+			{List: []int64{1, 2}, Map: map[string][]int{"a": {1, 2}}},
+			{List: []int64{2, 1}, Map: map[string][]int{"a": {2, 1}}},
+		}}, true},
+		{"struct nested dup", &UniqueTypes{Elems: []*UniqueElem{
+			{List: []int64{1, 2}, Map: map[string][]int{"a": {1, 2}, "b": nil}},
+			{List: []int64{1, 2}, Map: map[string][]int{"b": {}, "a": {2, 1}}},
+		}}, false},
+		{"list", &UniqueTypes{Lists: [][]int32{{1, 2}, {2, 1}}}, true},
+		{"list dup", &UniqueTypes{Lists: [][]int32{{1, 2}, {1, 2}}}, false},
+		{"set dup", &UniqueTypes{Sets: [][]int32{{1, 2}, {2, 1}}}, false},
+		{"array", &UniqueTypes{Array: &[2]float64{1, 2}}, true},
+		{"array dup", &UniqueTypes{Array: &[2]float64{1, 1}}, false},
+		{"mapset", &UniqueTypes{MapSet: map[*UniqueElem]bool{{X: 1}: true, {X: 2}: true}}, true},
+		{"mapset dup", &UniqueTypes{MapSet: map[*UniqueElem]bool{{X: 1}: true, {X: 1}: true}}, false},
+		{"mapset false", &UniqueTypes{MapSet: map[*UniqueElem]bool{{X: 1}: true, {X: 1}: false}}, true},
+		{"map", &UniqueTypes{Maps: []map[string]int16{{"a": 1}, {"a": 2}}}, true},
+		{"map dup", &UniqueTypes{Maps: []map[string]int16{{"a": 1, "b": 2}, {"b": 2, "a": 1}}}, false},
+		{"list not checked", &UniqueTypes{NotSet: []int32{1, 1}}, true},
+		{"nested dup", &UniqueTypes{Nested: map[string][]*UniqueElem{"a": {{X: 1}, {X: 1}}}}, false},
+		{"bytes array dup", &UniqueTypes{Arrays: [][2]byte{{1, 2}, {1, 2}}}, false},
+		{"float32 dup", &UniqueTypes{Float32: []float32{0, 1, 0}}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testUniqueness(t, tc.p, tc.uniq)
+		})
+	}
+}
+
+func TestSetUniqueness_DecodeMapSet(t *testing.T) {
+	withSetUniqueness(t)
+
+	// the same as set<i32> on wire
+	b, err := Append(nil, &UniqueTypes{I32s: []int32{1, 2}})
+	assert.Nil(t, err)
+	p := &struct {
+		Set map[int32]struct{} `frugal:"1,optional,set<i32>"`
+	}{}
+	_, err = Decode(b, p)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(p.Set))
+
+	opts.CheckSetUniqueness = false
+	b, err = Append(nil, &UniqueTypes{I32s: []int32{1, 1}})
+	assert.Nil(t, err)
+	opts.CheckSetUniqueness = true
+	_, err = Decode(b, p)
+	assert.True(t, err != nil && strings.Contains(err.Error(), "duplicate elements in set"), err)
+	_, err = DecodeFrom(bytes.NewReader(b), p)
+	assert.True(t, err != nil && strings.Contains(err.Error(), "duplicate elements in set"), err)
+}
+
+func TestSetUniqueness_Disabled(t *testing.T) {
+	assert.True(t, !opts.CheckSetUniqueness)
+	b, err := Append(nil, &UniqueTypes{I32s: []int32{1, 1}})
+	assert.Nil(t, err)
+	p := &UniqueTypes{}
+	_, err = Decode(b, p)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []int32{1, 1}, p.I32s)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// Unsigned integers of fields with the "unsigned" option are encoded and decoded
+// as the signed types of the same sizes bit by bit, no code is needed for that.
+// checkUnsignedRange is for the "rangecheck" option,
+// which rejects values that don't fit in the signed types.
+
+var maxSignedValues = [256]uint64{
+	tBYTE: math.MaxInt8,
+	tI16:  math.MaxInt16,
+	tI32:  math.MaxInt32,
+	tENUM: math.MaxInt32,
+	tI64:  math.MaxInt64,
+}
+
+// checkUnsignedRange checks unsigned integers of the value p points to, including elements of containers.
+func checkUnsignedRange(t *tType, p unsafe.Pointer) error {
+	rt := t.RT
+	if t.IsPointer {
+		if p = *(*unsafe.Pointer)(p); p == nil {
+			return nil
+		}
+		rt = rt.Elem()
+	}
+	switch t.T {
+	case tBYTE, tI16, tI32, tI64, tENUM:
+		var v uint64
+		switch rt.Kind() {
+		case reflect.Uint8:
+			v = uint64(*(*uint8)(p))
+		case reflect.Uint16:
+			v = uint64(*(*uint16)(p))
+		case reflect.Uint32:
+			v = uint64(*(*uint32)(p))
+		case reflect.Uint64, reflect.Uint:
+			if rt.Size() == 4 {
+				v = uint64(*(*uint32)(p))
+			} else {
+				v = *(*uint64)(p)
+			}
+		default:
+			return nil
+		}
+		if v > maxSignedValues[t.T] {
+			return fmt.Errorf("value %d overflows %s", v, ttype2str(t.WT))
+		}
+
+	case tLIST, tSET:
+		h := (*sliceHeader)(p)
+		for i := 0; i < h.Len; i++ {
+			if err := checkUnsignedRange(t.V, unsafe.Add(h.Data, i*t.V.Size)); err != nil {
+				return err
+			}
+		}
+
+	case tARRAY:
+		at := t.array()
+		for i := 0; i < at.Len; i++ {
+			if err := checkUnsignedRange(at.V, unsafe.Add(p, i*at.V.Size)); err != nil {
+				return err
+			}
+		}
+
+	case tPOINTER:
+		return checkUnsignedRange(t.V, p)
+
+	case tMAPSET:
+		return t.rangeMapSet(p, func(kp unsafe.Pointer) error {
+			return checkUnsignedRange(t.V, kp)
+		})
+
+	case tMAP:
+		if *(*unsafe.Pointer)(p) == nil {
+			return nil
+		}
+		it := newMapIter(rvWithPtr(t.RV, p))
+		for kp, vp := it.Next(); kp != nil; kp, vp = it.Next() {
+			if err := checkUnsignedRange(t.K, kp); err != nil {
+				return err
+			}
+			if err := checkUnsignedRange(t.V, vp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+)
+
+type UnsignedTypes struct {
+	U8  uint8             `frugal:"1,default,byte,unsigned"`
+	U16 uint16            `frugal:"2,default,i16,unsigned"`
+	U32 *uint32           `frugal:"3,optional,i32,unsigned"`
+	U64 uint64            `frugal:"4,default,i64,unsigned"`
+	U   uint              `frugal:"5,default,i64,unsigned"`
+	L   []uint32          `frugal:"6,default,list<i32>,unsigned"`
+	S   []uint16          `frugal:"7,default,set<i16>,unsigned"`
+	M   map[uint64]uint8  `frugal:"8,default,map<i64:byte>,unsigned"`
+	ML  map[int32][]uint8 `frugal:"9,default,map<i32:list<byte>>,unsigned"`
+}
+
+type SignedTypes struct {
+	U8  int8             `frugal:"1,default,byte"`
+	U16 int16            `frugal:"2,default,i16"`
+	U32 *int32           `frugal:"3,optional,i32"`
+	U64 int64            `frugal:"4,default,i64"`
+	U   int64            `frugal:"5,default,i64"`
+	L   []int32          `frugal:"6,default,list<i32>"`
+	S   []int16          `frugal:"7,default,set<i16>"`
+	M   map[int64]int8   `frugal:"8,default,map<i64:byte>"`
+	ML  map[int32][]int8 `frugal:"9,default,map<i32:list<byte>>"`
+}
+
+type UnsignedRangeCheck struct {
+	U32 uint32           `frugal:"1,default,i32,unsigned,rangecheck"`
+	L   []uint8          `frugal:"2,optional,list<byte>,unsigned,rangecheck"`
+	M   map[uint16]int64 `frugal:"3,optional,map<i16:i64>,unsigned,rangecheck"`
+}
+
+func TestUnsigned(t *testing.T) {
+	u32 := uint32(math.MaxUint32)
+	p0 := &UnsignedTypes{
+		U8: 200, U16: math.MaxUint16, U32: &u32, U64: math.MaxUint64, U: 1,
+		L: []uint32{math.MaxUint32, 1}, S: []uint16{40000},
+		M: map[uint64]uint8{math.MaxUint64: 255}, ML: map[int32][]uint8{1: {128}},
+	}
+	i32 := int32(-1)
+	s0 := &SignedTypes{
+		U8: -56, U16: -1, U32: &i32, U64: -1, U: 1,
+		L: []int32{-1, 1}, S: []int16{-25536},
+		M: map[int64]int8{-1: -1}, ML: map[int32][]int8{1: {-128}},
+	}
+
+	// the same as signed types bit by bit
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	expect, err := Append(nil, s0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, expect, b)
+
+	p1 := &UnsignedTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	p1 = &UnsignedTypes{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+}
+
+func TestUnsignedRangeCheck(t *testing.T) {
+	p := &UnsignedRangeCheck{U32: math.MaxInt32, L: []uint8{127}, M: map[uint16]int64{math.MaxInt16: -1}}
+	_, err := Append(nil, p)
+	assert.Nil(t, err)
+
+	for _, p := range []*UnsignedRangeCheck{
+		{U32: math.MaxInt32 + 1},
+		{L: []uint8{1, 128}},
+		{M: map[uint16]int64{math.MaxInt16 + 1: 0}},
+	} {
+		_, err = Append(nil, p)
+		assert.True(t, err != nil && strings.Contains(err.Error(), "overflows"), err)
+		_, err = AppendCompact(nil, p)
+		assert.True(t, err != nil && strings.Contains(err.Error(), "overflows"), err)
+		_, err = EncodeTo(&bytes.Buffer{}, p)
+		assert.True(t, err != nil && strings.Contains(err.Error(), "overflows"), err)
+	}
+}
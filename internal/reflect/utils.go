@@ -23,62 +23,10 @@ import (
 	"unsafe"
 )
 
-// only be used when NewRequiredFieldNotSetException
-func lookupFieldName(rt reflect.Type, offset uintptr) string {
-	for rt.Kind() == reflect.Ptr {
-		rt = rt.Elem()
-	}
-	for i := 0; i < rt.NumField(); i++ {
-		f := rt.Field(i)
-		if f.Offset == offset {
-			return f.Name
-		}
-	}
-	return "unknown"
-}
-
 func withFieldErr(err error, sd *structDesc, f *tField) error {
 	return fmt.Errorf("%q field %d err: %w", sd.Name(), f.ID, err)
 }
 
-func checkUniqueness(t *tType, h *sliceHeader) error {
-	var uniq bool
-	switch t.T {
-	case tBOOL:
-		uniq = checkUnique(unsafe.Slice((*bool)(h.Data), h.Len))
-	case tI08:
-		uniq = checkUnique(unsafe.Slice((*int8)(h.Data), h.Len))
-	case tI16:
-		uniq = checkUnique(unsafe.Slice((*int16)(h.Data), h.Len))
-	case tI32:
-		uniq = checkUnique(unsafe.Slice((*int32)(h.Data), h.Len))
-	case tI64, tENUM:
-		uniq = checkUnique(unsafe.Slice((*int64)(h.Data), h.Len))
-	case tDOUBLE:
-		uniq = checkUnique(unsafe.Slice((*float64)(h.Data), h.Len))
-	case tSTRING:
-		uniq = checkUnique(unsafe.Slice((*string)(h.Data), h.Len))
-	default: // tSTRUCT?
-		// NOTE: tSTRUCT is not always comparable, and it's not common for set
-		return nil
-	}
-	if !uniq {
-		return fmt.Errorf("%s error writing set field: slice is not unique", t.RT.String())
-	}
-	return nil
-}
-
-func checkUnique[T comparable](vv []T) bool {
-	for i := range vv {
-		for j := i + 1; j < len(vv); j++ {
-			if vv[i] == vv[j] {
-				return false
-			}
-		}
-	}
-	return true
-}
-
 type tmpMapVars struct {
 	k  reflect.Value  // t.K.RT
 	kp unsafe.Pointer // *t.K.RT
@@ -88,6 +36,9 @@ type tmpMapVars struct {
 }
 
 func initOrGetMapTmpVarsPool(t *tType) *sync.Pool {
+	if t.T == tMAPSET {
+		return initOrGetMapSetTmpVarsPool(t)
+	}
 	if t.T != tMAP {
 		return nil
 	}
@@ -105,6 +56,26 @@ func initOrGetMapTmpVarsPool(t *tType) *sync.Pool {
 	}
 }
 
+// initOrGetMapSetTmpVarsPool is like initOrGetMapTmpVarsPool, but for tMAPSET,
+// m.k is the element of the set, and m.v is the value of the map, which is always struct{}{} or true.
+func initOrGetMapSetTmpVarsPool(t *tType) *sync.Pool {
+	return &sync.Pool{
+		New: func() interface{} {
+			m := &tmpMapVars{}
+			m.k = reflect.New(t.V.RT)
+			m.kp = m.k.UnsafePointer()
+			m.k = m.k.Elem()
+			m.v = reflect.New(t.RT.Elem())
+			m.vp = m.v.UnsafePointer()
+			m.v = m.v.Elem()
+			if t.SetBool {
+				m.v.SetBool(true)
+			}
+			return m
+		},
+	}
+}
+
 func appendUint16(b []byte, v uint16) []byte {
 	return append(b,
 		byte(v>>8),
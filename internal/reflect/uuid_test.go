@@ -0,0 +1,159 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudwego/frugal/internal/assert"
+	"github.com/cloudwego/frugal/schema"
+)
+
+type testUUID [16]byte
+
+type UUIDTypes struct {
+	ID    [16]byte              `frugal:"1,default,uuid"`
+	Named testUUID              `frugal:"2,default,uuid"`
+	Opt   *[16]byte             `frugal:"3,optional,uuid"`
+	List  [][16]byte            `frugal:"4,default,list<uuid>"`
+	Set   map[testUUID]struct{} `frugal:"5,default,set<uuid>"`
+	Keys  map[[16]byte]string   `frugal:"6,default,map<uuid:string>"`
+	Vals  map[string]testUUID   `frugal:"7,default,map<string:uuid>"`
+}
+
+type UUIDSkipped struct {
+	Vals map[string]testUUID `frugal:"7,default,map<string:uuid>"`
+}
+
+var testUUIDs = [...][16]byte{
+	{0: 1, 15: 2},
+	{0: 0xff, 8: 0x80, 15: 0xee},
+	{3: 3, 4: 4, 5: 5},
+}
+
+func TestUUID(t *testing.T) {
+	opt := testUUIDs[2]
+	p0 := &UUIDTypes{
+		ID:    testUUIDs[0],
+		Named: testUUIDs[1],
+		Opt:   &opt,
+		List:  [][16]byte{testUUIDs[0], testUUIDs[1]},
+		Set:   map[testUUID]struct{}{testUUIDs[2]: {}},
+		Keys:  map[[16]byte]string{testUUIDs[0]: "a"},
+		Vals:  map[string]testUUID{"b": testUUIDs[1]},
+	}
+
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, EncodedSize(p0), len(b))
+	expect := append([]byte{byte(wUUID), 0, 1}, testUUIDs[0][:]...) // type 16, id 1, 16 bytes
+	assert.BytesEqual(t, expect, b[:len(expect)])
+	p1 := &UUIDTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	buf := &bytes.Buffer{}
+	_, err = EncodeTo(buf, p0)
+	assert.Nil(t, err)
+	assert.BytesEqual(t, b, buf.Bytes())
+	p1 = &UUIDTypes{}
+	_, err = DecodeFrom(bytes.NewReader(b), p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+
+	// uuid fields are skipped as unknown fields
+	s := &UUIDSkipped{}
+	_, err = Decode(b, s)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0.Vals, s.Vals)
+	s = &UUIDSkipped{}
+	_, err = DecodeFrom(bytes.NewReader(b), s)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0.Vals, s.Vals)
+
+	b, err = AppendCompact(nil, p0)
+	assert.Nil(t, err)
+	assert.Equal(t, CompactEncodedSize(p0), len(b))
+	expect = append([]byte{0x10 | byte(ctUUID)}, testUUIDs[0][:]...) // delta 1, ctUUID, 16 bytes
+	assert.BytesEqual(t, expect, b[:len(expect)])
+	p1 = &UUIDTypes{}
+	_, err = DecodeCompact(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0, p1)
+	s = &UUIDSkipped{}
+	_, err = DecodeCompact(b, s)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0.Vals, s.Vals)
+}
+
+func TestSkipBinary(t *testing.T) {
+	p0 := &UUIDTypes{
+		List: [][16]byte{testUUIDs[0], testUUIDs[1]},
+		Keys: map[[16]byte]string{testUUIDs[0]: "a"},
+	}
+	b, err := Append(nil, p0)
+	assert.Nil(t, err)
+	n, err := skipBinary(b, tSTRUCT, maxDepthLimit)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+
+	for i := 0; i < len(b); i++ {
+		_, err = skipBinary(b[:i], tSTRUCT, maxDepthLimit)
+		assert.True(t, err != nil)
+	}
+	_, err = skipBinary([]byte{byte(tLIST), 0, 1, 0xfe, 0, 0, 0, 1, 0, 0, 0, 0, 0}, tSTRUCT, maxDepthLimit)
+	assert.True(t, err != nil)
+	_, err = skipBinary(b, tSTRUCT, 1)
+	assert.True(t, err == errDepthLimitExceeded)
+}
+
+func TestDynamicUUID(t *testing.T) {
+	s := &schema.Struct{Name: "UUIDs", Fields: []*schema.Field{
+		{ID: 1, Name: "id", Type: &schema.Type{Kind: schema.UUID}},
+		{ID: 4, Name: "list", Type: &schema.Type{Kind: schema.LIST, Elem: &schema.Type{Kind: schema.UUID}}},
+		{ID: 6, Name: "keys", Type: &schema.Type{Kind: schema.MAP,
+			Key: &schema.Type{Kind: schema.UUID}, Elem: &schema.Type{Kind: schema.STRING}}},
+	}}
+	v := map[string]interface{}{
+		"id":   testUUIDs[0],
+		"list": []interface{}{testUUIDs[0], testUUIDs[1]},
+		"keys": map[interface{}]interface{}{testUUIDs[0]: "a"},
+	}
+	b, err := AppendDynamic(nil, s, v)
+	assert.Nil(t, err)
+	n, err := DynamicEncodedSize(s, v)
+	assert.Nil(t, err)
+	assert.Equal(t, len(b), n)
+
+	// the same as Go structs on wire
+	p0 := &UUIDTypes{ID: testUUIDs[0], List: [][16]byte{testUUIDs[0], testUUIDs[1]},
+		Keys: map[[16]byte]string{testUUIDs[0]: "a"}}
+	p1 := &UUIDTypes{}
+	_, err = Decode(b, p1)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, p0.Keys, p1.Keys)
+	assert.DeepEqual(t, p0.List, p1.List)
+
+	ret, _, err := DecodeDynamic(b, s)
+	assert.Nil(t, err)
+	assert.DeepEqual(t, v, ret)
+
+	_, err = AppendDynamic(nil, s, map[string]interface{}{"id": testUUIDs[0][:]})
+	assert.True(t, err != nil)
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package reflect
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/cloudwego/frugal/internal/defs"
+	"github.com/cloudwego/frugal/internal/opts"
+)
+
+// The encoders and decoders of the Binary Protocol, the Compact Protocol and streams
+// walk the same structDesc and tType trees, only the way of reading and writing values is different.
+// The logic of structs and containers which doesn't depend on the protocol lives here,
+// so that all of them behave the same.
+
+// checkEncode checks the struct at base before encoding its fields.
+func (sd *structDesc) checkEncode(base unsafe.Pointer) error {
+	if !opts.CheckRequired && !sd.isUnion {
+		return nil // fast path, inlined
+	}
+	return sd.checkEncodeSlow(base)
+}
+
+func (sd *structDesc) checkEncodeSlow(base unsafe.Pointer) error {
+	if opts.CheckRequired {
+		if err := sd.checkRequired(base); err != nil {
+			return err
+		}
+	}
+	if base != nil && sd.isUnion {
+		if _, err := sd.unionField(base); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodedPointer returns the pointer to the field f of the struct at base,
+// or nil if the field is not encoded: the embedded struct of it is nil, or it's optional and not set.
+func (f *tField) encodedPointer(base unsafe.Pointer) unsafe.Pointer {
+	if f.Embeds == nil && !f.CanSkipEncodeIfNil && !f.CanSkipIfDefault {
+		return unsafe.Add(base, f.Offset) // fast path, inlined
+	}
+	return f.encodedPointerSlow(base)
+}
+
+func (f *tField) encodedPointerSlow(base unsafe.Pointer) unsafe.Pointer {
+	p := unsafe.Add(base, f.Offset)
+	if f.Embeds != nil {
+		if p = f.embeddedPointer(base); p == nil {
+			return nil
+		}
+	}
+	if f.CanSkipEncodeIfNil && *(*unsafe.Pointer)(p) == nil {
+		return nil
+	}
+	if f.CanSkipIfDefault && f.Type.Equal(f.Default, p) {
+		return nil
+	}
+	return p
+}
+
+// checkEncodedField checks the value p of the field f before encoding it.
+func (sd *structDesc) checkEncodedField(f *tField, p unsafe.Pointer) error {
+	if !f.RangeCheck {
+		return nil // fast path, inlined
+	}
+	return sd.checkFieldRange(f, p)
+}
+
+func (sd *structDesc) checkFieldRange(f *tField, p unsafe.Pointer) error {
+	if err := checkUnsignedRange(f.Type, p); err != nil {
+		return withFieldErr(err, sd, f)
+	}
+	return nil
+}
+
+// getUnknownFields returns the unknown fields saved in the struct at base, which are encoded as is.
+func (sd *structDesc) getUnknownFields(base unsafe.Pointer) []byte {
+	if !sd.hasUnknownFields {
+		return nil
+	}
+	return *(*[]byte)(unsafe.Add(base, sd.unknownFieldsOffset))
+}
+
+// setUnknownFields saves the skipped fields b to the struct at base.
+func (sd *structDesc) setUnknownFields(base unsafe.Pointer, b []byte) {
+	*(*[]byte)(unsafe.Add(base, sd.unknownFieldsOffset)) = b
+}
+
+// newRequiredBitset returns a bitset for tracking the required fields of sd when decoding,
+// or nil if there's no required field. It must be put back to bitsetPool.
+func (sd *structDesc) newRequiredBitset() *bitset {
+	if len(sd.requiredFieldIDs) == 0 {
+		return nil
+	}
+	bs := bitsetPool.Get().(*bitset)
+	for _, f := range sd.requiredFieldIDs {
+		bs.unset(f)
+	}
+	return bs
+}
+
+// checkDecoded checks the struct after decoding nfields fields, bs is from newRequiredBitset.
+func (sd *structDesc) checkDecoded(bs *bitset, nfields int) error {
+	if sd.isUnion && nfields != 1 {
+		return newUnionFieldsException(sd.Name(), nfields)
+	}
+	for _, fid := range sd.requiredFieldIDs {
+		if !bs.test(fid) {
+			return newRequiredFieldNotSetException(sd.GetField(fid).Name)
+		}
+	}
+	return nil
+}
+
+func (sd *structDesc) skipFieldErr(fid uint16, err error) error {
+	return fmt.Errorf("skip unknown field %d of struct %s err: %w", fid, sd.rt.String(), err)
+}
+
+func (sd *structDesc) decodeFieldErr(fid uint16, err error) error {
+	return fmt.Errorf("decode field %d of struct %s err: %w", fid, sd.rt.String(), err)
+}
+
+// fieldPointer returns the pointer to decode the field f of the struct at base to,
+// embedded structs and the value of pointer fields are allocated if needed.
+func (d *tDecoder) fieldPointer(f *tField, base unsafe.Pointer) unsafe.Pointer {
+	if f.Embeds == nil && !f.Type.IsPointer {
+		return unsafe.Add(base, f.Offset) // fast path, inlined
+	}
+	return d.fieldPointerSlow(f, base)
+}
+
+func (d *tDecoder) fieldPointerSlow(f *tField, base unsafe.Pointer) unsafe.Pointer {
+	p := unsafe.Add(base, f.Offset)
+	if f.Embeds != nil {
+		p = f.mallocEmbeddedPointer(base)
+	}
+	return d.mallocIfPointer(f.Type, p)
+}
+
+// decodeFast returns true if values of t can be decoded by decodeFixedSizeTypes without any check.
+func (t *tType) decodeFast() bool {
+	return t.FixedSize > 0 && !t.Exact
+}
+
+// checkListHeader checks the header of a list or set, which has l elements of wire type tp.
+// et is the expected element type, and elemSize is the bytes allocated for each element.
+//
+// b is the remaining bytes after the header, and ws is the minimum wire sizes of the protocol,
+// l is checked against the remaining bytes before allocating memory for it.
+// ws is nil for streams, the remaining bytes are unknown.
+func (dl *decodeLimits) checkListHeader(et, tp ttype, l, elemSize int, b []byte, ws *[256]int8) error {
+	if l < 0 {
+		return errNegativeSize
+	}
+	if et != tp {
+		return newTypeMismatch(et, tp)
+	}
+	if l == 0 {
+		return nil
+	}
+	if ws != nil && l > len(b)/int(ws[et]) {
+		return newSizeExceedsBufferException(l, len(b))
+	}
+	return dl.checkContainer(l, elemSize)
+}
+
+// checkMapHeader is like checkListHeader, but for a map with l entries of wire types t0 and t1,
+// kt and vt are the expected types.
+func (dl *decodeLimits) checkMapHeader(kt, vt, t0, t1 ttype, l, elemSize int, b []byte, ws *[256]int8) error {
+	if l < 0 {
+		return errNegativeSize
+	}
+	if t0 != kt || t1 != vt {
+		return newTypeMismatchKV(kt, vt, t0, t1)
+	}
+	if l == 0 {
+		return nil
+	}
+	if ws != nil && l > len(b)/(int(ws[kt])+int(ws[vt])) {
+		return newSizeExceedsBufferException(l, len(b))
+	}
+	return dl.checkContainer(l, elemSize)
+}
+
+// setString sets the string or binary p points to with the l bytes at x.
+// an empty binary is set to []byte{} instead of nil, as it's set on the wire.
+func setString(t *tType, p unsafe.Pointer, x *byte, l int) {
+	if t.Tag == defs.T_binary {
+		if l == 0 {
+			*(*[]byte)(p) = []byte{}
+		} else {
+			*(*[]byte)(p) = unsafe.Slice(x, l)
+		}
+	} else {
+		*(*string)(p) = unsafe.String(x, l)
+	}
+}
+
+// checkArrayHeader is like checkListHeader, but for an array of type t, l must be the len of it.
+func checkArrayHeader(t *tType, tp ttype, l int, b []byte, ws *[256]int8) error {
+	et := t.V
+	if et.WT != tp {
+		return newTypeMismatch(et.WT, tp)
+	}
+	if l != t.Len {
+		return newArrayLenMismatch(t, l)
+	}
+	if ws != nil && l > 0 && l > len(b)/int(ws[et.WT]) {
+		return newSizeExceedsBufferException(l, len(b))
+	}
+	return nil
+}
@@ -21,8 +21,68 @@ import (
 )
 
 // Option is the property setter function for opts.Options.
+//
+// Options limit the resources used by decoding, violations return thrift.ProtocolException
+// with SIZE_LIMIT or DEPTH_LIMIT. They're passed to funcs like DecodeObjectWithOptions per call,
+// or set globally by SetDefaultOptions for funcs without options, including DecodeMessage and DecodeDynamic.
+// Options like WithDisallowUnknownFields make decoding of Go structs strict,
+// violations return thrift.ProtocolException with INVALID_DATA.
 type Option func(*opts.Options)
 
+// WithMaxStringSize limits the length of a string or binary, n <= 0 for no limit.
+func WithMaxStringSize(n int) Option {
+	return func(o *opts.Options) { o.MaxStringSize = n }
+}
+
+// WithMaxContainerSize limits the number of elements of a list, set or map, n <= 0 for no limit.
+func WithMaxContainerSize(n int) Option {
+	return func(o *opts.Options) { o.MaxContainerSize = n }
+}
+
+// WithMaxDepth limits the nesting depth of structs and containers, n <= 0 for the default 1023.
+func WithMaxDepth(n int) Option {
+	return func(o *opts.Options) { o.MaxDepth = n }
+}
+
+// WithMaxAllocSize limits the total bytes allocated for strings, binaries and containers by a decoding call,
+// n <= 0 for no limit. Like Go sizes, a []int64 of 10 elements takes 80 bytes, and a string takes its length.
+func WithMaxAllocSize(n int) Option {
+	return func(o *opts.Options) { o.MaxAllocSize = n }
+}
+
+// WithDisallowUnknownFields makes decoding fail on fields of unknown IDs instead of skipping them.
+// The error reports the struct name, the field ID and type, and the offset of the field in the decoded bytes.
+func WithDisallowUnknownFields(v bool) Option {
+	return func(o *opts.Options) { o.DisallowUnknownFields = v }
+}
+
+// WithDisallowMismatchedFieldTypes makes decoding fail on fields of known IDs
+// with wire types other than the expected ones instead of skipping them.
+// The error reports the struct name, the field ID, the expected and received types,
+// and the offset of the field in the decoded bytes.
+func WithDisallowMismatchedFieldTypes(v bool) Option {
+	return func(o *opts.Options) { o.DisallowMismatchedFieldTypes = v }
+}
+
+// SetDefaultOptions sets the options used by decoding funcs without options.
+// Options not given are kept as they are.
+//
+// It's not goroutine-safe, it should be called before decoding, like in init().
+func SetDefaultOptions(options ...Option) {
+	for _, f := range options {
+		f(&opts.DefaultOptions)
+	}
+}
+
+// newOptions returns the default options updated by options.
+func newOptions(options []Option) *opts.Options {
+	o := opts.DefaultOptions
+	for _, f := range options {
+		f(&o)
+	}
+	return &o
+}
+
 // NoJIT ...
 //
 // Deprecated: JIT is deprecated
@@ -62,3 +122,42 @@ func SetMaxInlineDepth(depth int) int {
 func SetMaxInlineILSize(size int) int {
 	return size
 }
+
+// SetNocopyWriteThreshold sets the min size of strings or binaries
+// written by thrift.NocopyWriter in EncodeObject, and returns the previous one.
+// The default value is 4096, it can also be set by env FRUGAL_NOCOPY_WRITE_THRESHOLD.
+// n must be positive like the env, it panics otherwise.
+//
+// It's not goroutine-safe, it should be called before encoding, like in init().
+func SetNocopyWriteThreshold(n int) int {
+	if n <= 0 {
+		panic("frugal: value too small for NocopyWriteThreshold")
+	}
+	old := opts.NocopyWriteThreshold
+	opts.NocopyWriteThreshold = n
+	return old
+}
+
+// SetCheckSetUniqueness enables or disables the uniqueness check of set elements, and returns the previous one.
+// If enabled, encoding fails if a set has duplicate elements, and so does decoding.
+// Elements like structs are compared by values of the fields instead of pointers.
+// It's disabled by default, it can also be enabled by env FRUGAL_CHECK_SET_UNIQUENESS=1.
+//
+// It's not goroutine-safe, it should be called before encoding and decoding, like in init().
+func SetCheckSetUniqueness(v bool) bool {
+	old := opts.CheckSetUniqueness
+	opts.CheckSetUniqueness = v
+	return old
+}
+
+// SetCheckRequired enables or disables the check of required fields when encoding, and returns the previous one.
+// If enabled, encoding fails with INVALID_DATA like decoding if a required field is not set,
+// that's a nil pointer or a nil []byte, see CheckRequired.
+// It's disabled by default, it can also be enabled by env FRUGAL_CHECK_REQUIRED=1.
+//
+// It's not goroutine-safe, it should be called before encoding, like in init().
+func SetCheckRequired(v bool) bool {
+	old := opts.CheckRequired
+	opts.CheckRequired = v
+	return old
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package frugal
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	ireflect "github.com/cloudwego/frugal/internal/reflect"
+	"github.com/cloudwego/frugal/schema"
+)
+
+// FieldSpec declares a field of a struct registered by RegisterStruct,
+// it's the same as a "frugal" tag like `frugal:"1,optional,list<i64>,nocopy"`.
+type FieldSpec struct {
+	Field        string // name of the Go struct field, it must be exported
+	ID           uint16
+	Requiredness schema.Requiredness
+
+	// Type is the Thrift type in the same syntax as tags, like "list<i64>" or "MyEnum".
+	// It can be empty if it's not required by tags.
+	Type string
+
+	NoCopy bool // the string or binary refers to the input buffer when decoding
+
+	// Options are other options of tags, like "unsigned", "rangecheck" or "precisioncheck".
+	Options []string
+}
+
+func (f FieldSpec) tag() string {
+	ss := []string{strconv.Itoa(int(f.ID)), f.Requiredness.String(), f.Type}
+	if f.NoCopy {
+		ss = append(ss, "nocopy")
+	}
+	ss = append(ss, f.Options...)
+	return strings.Join(ss, ",")
+}
+
+// RegisterStruct declares the fields of the struct type vt in code instead of struct tags,
+// for types which can't be changed like types of third-party packages.
+// vt can also be a pointer to a struct. Struct tags of vt are ignored,
+// and fields not in specs are not serialized.
+//
+// The specs are validated like tags. It must be called before vt is used, like in init(),
+// and a type can only be registered once.
+func RegisterStruct(vt reflect.Type, specs []FieldSpec) error {
+	tags := make(map[string]string, len(specs))
+	for _, f := range specs {
+		if _, ok := tags[f.Field]; ok {
+			return fmt.Errorf("duplicated field spec for %s.%s", vt, f.Field)
+		}
+		tags[f.Field] = f.tag()
+	}
+	return ireflect.RegisterStruct(vt, tags)
+}
+
+// RegisterUnion declares the struct type vt as a Thrift union, vt can also be a pointer to a struct.
+// All fields of vt must be optional, and of types which can be nil like pointers, slices and maps.
+//
+// Encoding and decoding unions fail with thrift.INVALID_DATA unless exactly one field is set,
+// fields unknown to vt are not counted when decoding.
+// It must be called before vt is used, like in init(), and a type can only be registered once.
+// It can be called before or after RegisterStruct of vt.
+func RegisterUnion(vt reflect.Type) error {
+	return ireflect.RegisterUnion(vt)
+}
+
+// UnionFieldID returns the ID of the field set in the union v, which is registered by RegisterUnion.
+// It fails unless exactly one field is set.
+func UnionFieldID(v interface{}) (uint16, error) {
+	return ireflect.UnionFieldID(v)
+}
+
+// RegisterEnumValues declares the valid values of the enum type T, like enums generated by Thriftgo.
+// Decoding fails with thrift.INVALID_DATA if a value of T is not one of values.
+//
+// Values of enums which are not registered are not checked.
+// It must be called before T is used, like in init(), and a type can only be registered once.
+func RegisterEnumValues[T ~int64](values ...T) error {
+	m := make(map[int64]struct{}, len(values))
+	for _, v := range values {
+		m[int64(v)] = struct{}{}
+	}
+	return ireflect.RegisterEnum(reflect.TypeOf((*T)(nil)).Elem(), func(v int64) bool {
+		_, ok := m[v]
+		return ok
+	})
+}
+
+// RegisterEnumFromString is like RegisterEnumValues, but the valid values are inferred from
+// the String method of T and fromString, like the generated MyEnumFromString func of Thriftgo.
+// A value v is valid if fromString(v.String()) returns v without errors.
+func RegisterEnumFromString[T interface {
+	~int64
+	String() string
+}](fromString func(s string) (T, error)) error {
+	return ireflect.RegisterEnum(reflect.TypeOf((*T)(nil)).Elem(), func(v int64) bool {
+		x, err := fromString(T(v).String())
+		return err == nil && int64(x) == v
+	})
+}
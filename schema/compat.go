@@ -0,0 +1,117 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Change is a difference between two versions of a struct.
+type Change struct {
+	// Path is where the change is, like "Request.user.tags<elem>".
+	// It consists of the struct name and the old field names,
+	// with <elem>, <key> or <value> for elements of containers.
+	Path string
+
+	// Breaking is true if peers using the different versions can't talk to each other.
+	Breaking bool
+
+	Message string
+}
+
+func (c Change) String() string {
+	if c.Breaking {
+		return c.Path + ": " + c.Message + " (breaking)"
+	}
+	return c.Path + ": " + c.Message
+}
+
+// Compare returns the changes from old to new for the wire compatibility, fields are matched by IDs.
+//
+// The following changes are breaking:
+//   - wire type changes, including list to set
+//   - adding or removing required fields
+//   - making a field required or making a required field not required
+//
+// Others are reported as non-breaking, like renaming fields, changing default values,
+// string to binary, i32 to enum, or adding and removing non-required fields.
+func Compare(old, new *Struct) []Change {
+	c := &comparer{visited: map[[2]*Struct]bool{}}
+	c.compareStruct(old.Name, old, new)
+	return c.changes
+}
+
+type comparer struct {
+	changes []Change
+	visited map[[2]*Struct]bool
+}
+
+func (c *comparer) add(path string, breaking bool, format string, args ...interface{}) {
+	c.changes = append(c.changes, Change{Path: path, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *comparer) compareStruct(path string, old, new *Struct) {
+	k := [2]*Struct{old, new}
+	if c.visited[k] {
+		return
+	}
+	c.visited[k] = true
+	for _, of := range old.Fields {
+		p := path + "." + of.Name
+		nf := new.FieldByID(of.ID)
+		if nf == nil {
+			c.add(p, of.Requiredness == Required, "%s field %d removed", of.Requiredness, of.ID)
+			continue
+		}
+		if of.Name != nf.Name {
+			c.add(p, false, "field %d renamed to %s", of.ID, nf.Name)
+		}
+		if of.Requiredness != nf.Requiredness {
+			breaking := of.Requiredness == Required || nf.Requiredness == Required
+			c.add(p, breaking, "requiredness changed from %s to %s", of.Requiredness, nf.Requiredness)
+		}
+		if !reflect.DeepEqual(of.Default, nf.Default) {
+			c.add(p, false, "default value changed from %v to %v", of.Default, nf.Default)
+		}
+		c.compareType(p, of.Type, nf.Type)
+	}
+	for _, nf := range new.Fields {
+		if old.FieldByID(nf.ID) == nil {
+			c.add(path+"."+nf.Name, nf.Requiredness == Required, "%s field %d added", nf.Requiredness, nf.ID)
+		}
+	}
+}
+
+func (c *comparer) compareType(path string, old, new *Type) {
+	if old.Kind.WireType() != new.Kind.WireType() {
+		c.add(path, true, "type changed from %s to %s", old, new)
+		return
+	}
+	if old.Kind != new.Kind {
+		c.add(path, false, "type changed from %s to %s", old, new)
+	}
+	switch old.Kind.WireType() {
+	case uint8(STRUCT):
+		c.compareStruct(path, old.Struct, new.Struct)
+	case uint8(MAP):
+		c.compareType(path+"<key>", old.Key, new.Key)
+		c.compareType(path+"<value>", old.Elem, new.Elem)
+	case uint8(SET), uint8(LIST):
+		c.compareType(path+"<elem>", old.Elem, new.Elem)
+	}
+}
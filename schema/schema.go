@@ -0,0 +1,272 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package schema describes Thrift types at runtime.
+//
+// A schema can be built by hand, loaded from IDL, or described from frugal-tagged Go types.
+// It's used for encoding and decoding without Go structs, see frugal.DecodeDynamic.
+//
+// A schema must not be changed after it's used for encoding or decoding,
+// the descriptor compiled for it is kept in it, and freed together with it.
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// Kind is the kind of a Thrift type.
+//
+// The values of wire types are the same as the Thrift type IDs,
+// BINARY and ENUM are encoded as STRING and I32 respectively.
+type Kind uint8
+
+const (
+	BOOL   Kind = 2
+	BYTE   Kind = 3
+	DOUBLE Kind = 4
+	I16    Kind = 6
+	I32    Kind = 8
+	I64    Kind = 10
+	STRING Kind = 11
+	STRUCT Kind = 12
+	MAP    Kind = 13
+	SET    Kind = 14
+	LIST   Kind = 15
+	UUID   Kind = 16
+
+	ENUM   Kind = 0x80
+	BINARY Kind = 0x81
+)
+
+var kindNames = [256]string{
+	BOOL:   "bool",
+	BYTE:   "byte",
+	DOUBLE: "double",
+	I16:    "i16",
+	I32:    "i32",
+	I64:    "i64",
+	STRING: "string",
+	STRUCT: "struct",
+	MAP:    "map",
+	SET:    "set",
+	LIST:   "list",
+	UUID:   "uuid",
+	ENUM:   "enum",
+	BINARY: "binary",
+}
+
+// String returns the IDL name of the Kind.
+func (k Kind) String() string {
+	if s := kindNames[k]; s != "" {
+		return s
+	}
+	return "Kind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// WireType returns the Thrift type ID used on the wire.
+func (k Kind) WireType() uint8 {
+	switch k {
+	case ENUM:
+		return uint8(I32)
+	case BINARY:
+		return uint8(STRING)
+	}
+	return uint8(k)
+}
+
+// Requiredness is the requiredness of a field.
+type Requiredness uint8
+
+const (
+	Default Requiredness = iota
+	Required
+	Optional
+)
+
+// String returns the IDL keyword of the Requiredness.
+func (r Requiredness) String() string {
+	switch r {
+	case Required:
+		return "required"
+	case Optional:
+		return "optional"
+	}
+	return "default"
+}
+
+// Type is a Thrift type.
+type Type struct {
+	Kind Kind
+
+	Key    *Type   // for MAP
+	Elem   *Type   // for LIST, SET, and value of MAP
+	Struct *Struct // for STRUCT
+	Enum   *Enum   // for ENUM, optional
+}
+
+// String returns the type in IDL.
+func (t *Type) String() string {
+	switch t.Kind {
+	case MAP:
+		return "map<" + t.Key.String() + "," + t.Elem.String() + ">"
+	case SET, LIST:
+		return t.Kind.String() + "<" + t.Elem.String() + ">"
+	case STRUCT:
+		if t.Struct != nil && t.Struct.Name != "" {
+			return t.Struct.Name
+		}
+	case ENUM:
+		if t.Enum != nil && t.Enum.Name != "" {
+			return t.Enum.Name
+		}
+	}
+	return t.Kind.String()
+}
+
+// Field is a field of a Struct.
+type Field struct {
+	ID           int16
+	Name         string
+	Type         *Type
+	Requiredness Requiredness
+
+	// Default is the default value in the dynamic value model, nil if no default value.
+	Default interface{}
+
+	// The following are only for fields of Go structs, see frugal.Describe
+	GoName string // name of the Go struct field
+	NoCopy bool   // the string or binary refers to the input buffer when decoding
+}
+
+// Struct is a Thrift struct, union or exception.
+type Struct struct {
+	Name   string
+	Fields []*Field
+
+	Union     bool
+	Exception bool
+
+	compiled atomic.Value // see Compiled
+}
+
+// Compiled returns the value stored by SetCompiled, or nil if s is not compiled yet.
+//
+// It's used by frugal to keep the descriptor compiled from s for encoding and decoding,
+// so that the descriptor is freed together with s.
+func (s *Struct) Compiled() interface{} {
+	return s.compiled.Load()
+}
+
+// SetCompiled stores the value compiled from s, see Compiled.
+// The type of v must be the same for all calls.
+func (s *Struct) SetCompiled(v interface{}) {
+	s.compiled.Store(v)
+}
+
+// FieldByID returns the field with the given ID, or nil if not found.
+func (s *Struct) FieldByID(id int16) *Field {
+	for _, f := range s.Fields {
+		if f.ID == id {
+			return f
+		}
+	}
+	return nil
+}
+
+// FieldByName returns the field with the given name, or nil if not found.
+func (s *Struct) FieldByName(name string) *Field {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Validate checks the struct and the types it refers to recursively.
+func (s *Struct) Validate() error {
+	return s.validate(map[*Struct]bool{})
+}
+
+func (s *Struct) validate(visited map[*Struct]bool) error {
+	if visited[s] {
+		return nil
+	}
+	visited[s] = true
+	ids := make(map[int16]bool, len(s.Fields))
+	names := make(map[string]bool, len(s.Fields))
+	for _, f := range s.Fields {
+		if ids[f.ID] {
+			return fmt.Errorf("struct %s: duplicated field id %d", s.Name, f.ID)
+		}
+		if names[f.Name] {
+			return fmt.Errorf("struct %s: duplicated field name %q", s.Name, f.Name)
+		}
+		ids[f.ID] = true
+		names[f.Name] = true
+		if err := f.Type.validate(visited); err != nil {
+			return fmt.Errorf("struct %s: field %s: %w", s.Name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (t *Type) validate(visited map[*Struct]bool) error {
+	if t == nil {
+		return fmt.Errorf("type is nil")
+	}
+	switch t.Kind {
+	case BOOL, BYTE, DOUBLE, I16, I32, I64, STRING, BINARY, ENUM, UUID:
+		return nil
+	case STRUCT:
+		if t.Struct == nil {
+			return fmt.Errorf("struct is nil")
+		}
+		return t.Struct.validate(visited)
+	case MAP:
+		if err := t.Key.validate(visited); err != nil {
+			return err
+		}
+		return t.Elem.validate(visited)
+	case SET, LIST:
+		return t.Elem.validate(visited)
+	}
+	return fmt.Errorf("unknown kind %s", t.Kind)
+}
+
+// Enum is a Thrift enum.
+type Enum struct {
+	Name   string
+	Values []EnumValue
+}
+
+// EnumValue is a value of an Enum.
+type EnumValue struct {
+	Name  string
+	Value int32
+}
+
+// ValueByName returns the value with the given name.
+func (e *Enum) ValueByName(name string) (int32, bool) {
+	for _, v := range e.Values {
+		if v.Name == name {
+			return v.Value, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/schema"
+	"github.com/stretchr/testify/require"
+)
+
+// ipv4 is encoded as a string like "127.0.0.1" with a custom codec, instead of binary of arrays.
+type ipv4 [4]byte
+
+func (ip ipv4) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3])
+}
+
+func init() {
+	if err := frugal.RegisterTimeCodec(); err != nil {
+		panic(err)
+	}
+	err := frugal.RegisterCodec(schema.STRING,
+		func(b []byte, v ipv4) ([]byte, error) {
+			s := v.String()
+			b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+			return append(b, s...), nil
+		},
+		func(b []byte) (ipv4, int, error) {
+			var ip ipv4
+			if len(b) < 4 || len(b) < 4+int(binary.BigEndian.Uint32(b)) {
+				return ip, 0, io.ErrShortBuffer
+			}
+			n := 4 + int(binary.BigEndian.Uint32(b))
+			_, err := fmt.Sscanf(string(b[4:n]), "%d.%d.%d.%d", &ip[0], &ip[1], &ip[2], &ip[3])
+			return ip, n, err
+		},
+		func(v ipv4) int { return 4 + len(v.String()) },
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+type codecFields struct {
+	T   time.Time            `frugal:"1,default,i64"`
+	PT  *time.Time           `frugal:"2,optional,i64"`
+	TL  []time.Time          `frugal:"3,default,list<i64>"`
+	TM  map[time.Time]string `frugal:"4,default,map<i64:string>"`
+	IP  ipv4                 `frugal:"5,default,string"`
+	IPs map[string]ipv4      `frugal:"6,default,map<string:string>"`
+	D   time.Duration        `frugal:"7,default,i64"`
+}
+
+type codecWireFields struct {
+	T   int64             `frugal:"1,default,i64"`
+	PT  *int64            `frugal:"2,optional,i64"`
+	TL  []int64           `frugal:"3,default,list<i64>"`
+	TM  map[int64]string  `frugal:"4,default,map<i64:string>"`
+	IP  string            `frugal:"5,default,string"`
+	IPs map[string]string `frugal:"6,default,map<string:string>"`
+	D   int64             `frugal:"7,default,i64"`
+}
+
+func TestRegisterCodec(t *testing.T) {
+	t0 := time.Unix(1700000000, 123).UTC()
+	t1 := time.Unix(-1, 0).UTC()
+	v := &codecFields{
+		T: t0, PT: &t1, TL: []time.Time{t0, {}}, TM: map[time.Time]string{t1: "t1"},
+		IP: ipv4{127, 0, 0, 1}, IPs: map[string]ipv4{"dns": {8, 8, 4, 4}}, D: time.Second,
+	}
+	n1 := t1.UnixNano()
+	w := &codecWireFields{
+		T: t0.UnixNano(), PT: &n1, TL: []int64{t0.UnixNano(), 0}, TM: map[int64]string{n1: "t1"},
+		IP: "127.0.0.1", IPs: map[string]string{"dns": "8.8.4.4"}, D: int64(time.Second),
+	}
+
+	expect := make([]byte, frugal.EncodedSize(w))
+	_, err := frugal.EncodeObject(expect, nil, w)
+	require.NoError(t, err)
+	buf := make([]byte, frugal.EncodedSize(v))
+	_, err = frugal.EncodeObject(buf, nil, v)
+	require.NoError(t, err)
+	require.Equal(t, expect, buf)
+
+	got := &codecFields{}
+	_, err = frugal.DecodeObject(buf, got)
+	require.NoError(t, err)
+	require.Equal(t, v, got)
+
+	var bb bytes.Buffer
+	_, err = frugal.EncodeTo(&bb, v)
+	require.NoError(t, err)
+	require.Equal(t, expect, bb.Bytes())
+	got = &codecFields{}
+	_, err = frugal.DecodeFrom(&bb, got)
+	require.NoError(t, err)
+	require.Equal(t, v, got)
+
+	// values are converted for compact protocol
+	expect = make([]byte, frugal.CompactEncodedSize(w))
+	_, err = frugal.EncodeCompact(expect, w)
+	require.NoError(t, err)
+	buf = make([]byte, frugal.CompactEncodedSize(v))
+	_, err = frugal.EncodeCompact(buf, v)
+	require.NoError(t, err)
+	require.Equal(t, expect, buf)
+	got = &codecFields{}
+	_, err = frugal.DecodeCompact(buf, got)
+	require.NoError(t, err)
+	require.Equal(t, v, got)
+
+	// described as the wire types
+	s, err := frugal.Describe(reflect.TypeOf(v))
+	require.NoError(t, err)
+	require.Equal(t, schema.I64, s.Fields[0].Type.Kind)
+	require.Equal(t, schema.STRING, s.Fields[5].Type.Elem.Kind)
+
+	// invalid registrations
+	require.Error(t, frugal.RegisterTimeCodec())
+	noop := func(b []byte, v int) ([]byte, error) { return b, nil }
+	require.Error(t, frugal.RegisterCodec(schema.BOOL, noop, nil, nil))
+	require.Error(t, frugal.RegisterCodec(schema.LIST, noop, nil, nil))
+	require.Error(t, frugal.RegisterCodec(schema.I64, func(b []byte, v *int) ([]byte, error) { return b, nil }, nil, nil))
+}
+
+// the numbers of bytes read returned by the codecs of badCodecI32 and badCodecStr
+var badCodecI32N, badCodecStrN int
+
+type (
+	badCodecI32 int32
+	badCodecStr string
+)
+
+type badCodecFields struct {
+	I badCodecI32 `frugal:"1,default,i32"`
+	S badCodecStr `frugal:"2,default,string"`
+}
+
+func TestCodecDecodedSize(t *testing.T) {
+	err := frugal.RegisterCodec(schema.I32,
+		func(b []byte, v badCodecI32) ([]byte, error) { return binary.BigEndian.AppendUint32(b, uint32(v)), nil },
+		func(b []byte) (badCodecI32, int, error) {
+			return badCodecI32(binary.BigEndian.Uint32(b)), badCodecI32N, nil
+		},
+		func(v badCodecI32) int { return 4 },
+	)
+	require.NoError(t, err)
+	err = frugal.RegisterCodec(schema.STRING,
+		func(b []byte, v badCodecStr) ([]byte, error) {
+			b = binary.BigEndian.AppendUint32(b, uint32(len(v)))
+			return append(b, v...), nil
+		},
+		func(b []byte) (badCodecStr, int, error) { return badCodecStr(b[4:]), badCodecStrN, nil },
+		func(v badCodecStr) int { return 4 + len(v) },
+	)
+	require.NoError(t, err)
+
+	v := &badCodecFields{I: 1, S: "abc"}
+	buf := make([]byte, frugal.EncodedSize(v))
+	_, err = frugal.EncodeObject(buf, nil, v)
+	require.NoError(t, err)
+	cbuf := make([]byte, frugal.CompactEncodedSize(v))
+	_, err = frugal.EncodeCompact(cbuf, v)
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		i32n, strn int
+		err        string
+	}{
+		{4, 7, ""},
+		{-1, 7, "decoded -1 bytes, not a valid I32 of 4 bytes"},
+		{0, 7, "decoded 0 bytes, not a valid I32 of 4 bytes"},
+		{5, 7, "decoded 5 bytes, not a valid I32 of 4 bytes"},
+		{1 << 20, 7, "not a valid I32"},
+		{4, 6, "decoded 6 bytes, not a valid STRING of 7 bytes"},
+		{4, 8, "decoded 8 bytes, not a valid STRING of 7 bytes"},
+	} {
+		badCodecI32N, badCodecStrN = tc.i32n, tc.strn
+		check := func(err error) {
+			t.Helper()
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+			}
+		}
+		got := &badCodecFields{}
+		_, err = frugal.DecodeObject(buf, got)
+		check(err)
+		_, err = frugal.DecodeFrom(bytes.NewReader(buf), &badCodecFields{})
+		check(err)
+		_, err = frugal.DecodeCompact(cbuf, &badCodecFields{})
+		check(err)
+		if tc.err == "" {
+			require.Equal(t, v, got)
+		}
+	}
+}
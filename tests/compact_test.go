@@ -0,0 +1,87 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/tests/baseline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactMarshalCompare(t *testing.T) {
+	var v baseline.Nesting2
+	loaddata(t, &v)
+	nb := frugal.CompactEncodedSize(&v)
+	buf := make([]byte, nb)
+	ret, err := frugal.EncodeCompact(buf, v)
+	require.NoError(t, err)
+	require.Equal(t, nb, ret)
+
+	var v1 baseline.Nesting2
+	var v2 baseline.Nesting2
+	mm := thrift.NewTMemoryBuffer()
+	_, _ = mm.Write(buf)
+	err = v1.Read(thrift.NewTCompactProtocol(mm))
+	require.NoError(t, err)
+	_, err = frugal.DecodeCompact(buf, &v2)
+	require.NoError(t, err)
+	assert.Equal(t, dumpval(v1), dumpval(v2))
+}
+
+func TestCompactUnmarshalCompare(t *testing.T) {
+	var v baseline.Nesting2
+	loaddata(t, &v)
+	mm := thrift.NewTMemoryBuffer()
+	err := v.Write(thrift.NewTCompactProtocol(mm))
+	require.NoError(t, err)
+	buf := mm.Bytes()
+
+	require.Equal(t, len(buf), frugal.CompactEncodedSize(v))
+
+	var v1 baseline.Nesting2
+	var v2 baseline.Nesting2
+	err = v1.Read(thrift.NewTCompactProtocol(thrift.NewStreamTransportR(bytes.NewReader(buf))))
+	require.NoError(t, err)
+	ret, err := frugal.DecodeCompact(buf, &v2)
+	require.NoError(t, err)
+	require.Equal(t, len(buf), ret)
+	assert.Equal(t, dumpval(v1), dumpval(v2))
+}
+
+func TestCompactWithDefaultCompare(t *testing.T) {
+	var v baseline.OptionalDefaultValues
+	v.InitDefault()
+	mm := thrift.NewTMemoryBuffer()
+	err := v.Write(thrift.NewTCompactProtocol(mm))
+	require.NoError(t, err)
+	nb := frugal.CompactEncodedSize(v)
+	require.Equal(t, mm.Len(), nb)
+	buf := make([]byte, nb)
+	ret, err := frugal.EncodeCompact(buf, v)
+	require.NoError(t, err)
+	require.Equal(t, nb, ret)
+
+	v1 := baseline.NewOptionalDefaultValues()
+	err = v1.Read(thrift.NewTCompactProtocol(thrift.NewStreamTransportR(bytes.NewReader(buf))))
+	require.NoError(t, err)
+	assert.Equal(t, dumpval(&v), dumpval(v1))
+}
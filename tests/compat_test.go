@@ -0,0 +1,84 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/schema"
+	"github.com/cloudwego/frugal/tests/baseline"
+	"github.com/stretchr/testify/require"
+)
+
+type compatItemV1 struct {
+	Name string  `frugal:"1,default,string"`
+	Tags []int32 `frugal:"2,default,list<i32>"`
+}
+
+type compatItemV2 struct {
+	Name string  `frugal:"1,default,string"`
+	Tags []int32 `frugal:"2,default,set<i32>"`
+}
+
+type compatV1 struct {
+	ID      int64            `frugal:"1,required,i64"`
+	Name    string           `frugal:"2,default,string"`
+	Data    string           `frugal:"3,default,string"`
+	Count   int32            `frugal:"4,optional,i32"`
+	Items   []*compatItemV1  `frugal:"5,default,list<compatItemV1>"`
+	Removed *string          `frugal:"6,optional,string"`
+	Key     string           `frugal:"7,required,string"`
+	Next    *compatV1        `frugal:"8,optional,compatV1"`
+	M       map[string]int32 `frugal:"9,default,map<string:i32>"`
+}
+
+type compatV2 struct {
+	ID    int64            `frugal:"1,required,i64"`
+	Title string           `frugal:"2,default,string"`
+	Data  []byte           `frugal:"3,default,binary"`
+	Count int64            `frugal:"4,optional,i64"`
+	Items []*compatItemV2  `frugal:"5,default,list<compatItemV2>"`
+	Key   string           `frugal:"7,optional,string"`
+	Next  *compatV2        `frugal:"8,optional,compatV2"`
+	M     map[string]int32 `frugal:"9,default,map<string:i32>"`
+	Added string           `frugal:"10,required,string"`
+	Extra *string          `frugal:"11,optional,string"`
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	changes, err := frugal.CheckCompatibility(reflect.TypeOf(compatV1{}), reflect.TypeOf(&compatV2{}))
+	require.NoError(t, err)
+	require.Equal(t, []schema.Change{
+		{Path: "compatV1.Name", Message: "field 2 renamed to Title"},
+		{Path: "compatV1.Data", Message: "type changed from string to binary"},
+		{Path: "compatV1.Count", Breaking: true, Message: "type changed from i32 to i64"},
+		{Path: "compatV1.Items<elem>.Tags", Breaking: true, Message: "type changed from list<i32> to set<i32>"},
+		{Path: "compatV1.Removed", Message: "optional field 6 removed"},
+		{Path: "compatV1.Key", Breaking: true, Message: "requiredness changed from required to optional"},
+		{Path: "compatV1.Added", Breaking: true, Message: "required field 10 added"},
+		{Path: "compatV1.Extra", Message: "optional field 11 added"},
+	}, changes)
+
+	changes, err = frugal.CheckCompatibility(reflect.TypeOf(baseline.Nesting2{}), reflect.TypeOf(baseline.Nesting2{}))
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	_, err = frugal.CheckCompatibility(reflect.TypeOf(compatV1{}), reflect.TypeOf(0))
+	require.Error(t, err)
+}
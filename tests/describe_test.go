@@ -0,0 +1,80 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/idl"
+	"github.com/cloudwego/frugal/tests/baseline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeCompareIDL(t *testing.T) {
+	f, err := idl.ParseFile("baseline.thrift")
+	require.NoError(t, err)
+	for _, v := range []interface{}{
+		&baseline.Nesting2{},
+		&baseline.DefaultValues{},
+		&baseline.OptionalDefaultValues{},
+	} {
+		s, err := frugal.Describe(reflect.TypeOf(v))
+		require.NoError(t, err)
+		x := f.Struct(s.Name)
+		require.NotNil(t, x, s.Name)
+		require.Equal(t, len(x.Fields), len(s.Fields))
+		for i, fd := range s.Fields {
+			xf := x.Fields[i]
+			require.Equal(t, xf.ID, fd.ID)
+			require.Equal(t, xf.Name, fd.Name)
+			require.Equal(t, xf.Requiredness, fd.Requiredness)
+			require.Equal(t, xf.Type.String(), fd.Type.String())
+			require.Equal(t, xf.Default, fd.Default, fd.Name)
+		}
+	}
+}
+
+func TestExportIDL(t *testing.T) {
+	s, err := frugal.ExportIDL(reflect.TypeOf(&baseline.Nesting{}), reflect.TypeOf(&baseline.DefaultValues{}))
+	require.NoError(t, err)
+	f, err := idl.Parse("exported.thrift", []byte(s))
+	require.NoError(t, err, s)
+	require.Len(t, f.Structs, 3) // Simple, Nesting and DefaultValues
+
+	// the exported IDL works with the Go structs
+	v := getNestingValue()
+	buf := make([]byte, frugal.EncodedSize(v))
+	_, err = frugal.EncodeObject(buf, nil, v)
+	require.NoError(t, err)
+	m, _, err := frugal.DecodeDynamic(buf, f.Struct("Nesting"))
+	require.NoError(t, err)
+	require.Equal(t, v.String_, m["String"])
+
+	m, _, err = frugal.DecodeDynamic([]byte{0}, f.Struct("DefaultValues"))
+	require.NoError(t, err)
+	sz, err := frugal.DynamicEncodedSize(f.Struct("DefaultValues"), m)
+	require.NoError(t, err)
+	buf = make([]byte, sz)
+	_, err = frugal.EncodeDynamic(buf, f.Struct("DefaultValues"), m)
+	require.NoError(t, err)
+	v1 := baseline.NewDefaultValues()
+	_, err = frugal.DecodeObject(buf, v1)
+	require.NoError(t, err)
+	require.Equal(t, dumpval(baseline.NewDefaultValues()), dumpval(v1))
+}
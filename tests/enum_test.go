@@ -0,0 +1,91 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/frugal"
+	kthrift "github.com/cloudwego/gopkg/protocol/thrift"
+	"github.com/stretchr/testify/require"
+)
+
+// colorEnum is like enums generated by Thriftgo
+type colorEnum int64
+
+const (
+	colorEnum_Red  colorEnum = 1
+	colorEnum_Blue colorEnum = 3
+)
+
+func (p colorEnum) String() string {
+	switch p {
+	case colorEnum_Red:
+		return "Red"
+	case colorEnum_Blue:
+		return "Blue"
+	}
+	return "<UNSET>"
+}
+
+func colorEnumFromString(s string) (colorEnum, error) {
+	switch s {
+	case "Red":
+		return colorEnum_Red, nil
+	case "Blue":
+		return colorEnum_Blue, nil
+	}
+	return colorEnum(0), errors.New("not a valid colorEnum string")
+}
+
+type sizeEnum int64
+
+type enumHolder struct {
+	C  colorEnum  `frugal:"1,default,colorEnum"`
+	Ss []sizeEnum `frugal:"2,default,list<sizeEnum>"`
+	Cp *colorEnum `frugal:"3,optional,colorEnum"`
+}
+
+func TestRegisterEnum(t *testing.T) {
+	require.NoError(t, frugal.RegisterEnumFromString(colorEnumFromString))
+	require.Error(t, frugal.RegisterEnumFromString(colorEnumFromString))
+	require.NoError(t, frugal.RegisterEnumValues[sizeEnum](10, 20))
+
+	decode := func(v *enumHolder) error {
+		buf := make([]byte, frugal.EncodedSize(v))
+		_, err := frugal.EncodeObject(buf, nil, v)
+		require.NoError(t, err)
+		_, err = frugal.DecodeObject(buf, &enumHolder{})
+		return err
+	}
+	requireInvalidEnum := func(err error) {
+		t.Helper()
+		var pe *kthrift.ProtocolException
+		require.True(t, errors.As(err, &pe), err)
+		require.Equal(t, int32(kthrift.INVALID_DATA), pe.TypeId())
+		require.Contains(t, err.Error(), "unknown value")
+	}
+
+	blue := colorEnum_Blue
+	require.NoError(t, decode(&enumHolder{C: colorEnum_Red, Ss: []sizeEnum{10, 20}, Cp: &blue}))
+	requireInvalidEnum(decode(&enumHolder{C: 2, Ss: []sizeEnum{10}}))
+	requireInvalidEnum(decode(&enumHolder{C: 0, Ss: []sizeEnum{10}}))
+	requireInvalidEnum(decode(&enumHolder{C: colorEnum_Red, Ss: []sizeEnum{10, 15}}))
+	unset := colorEnum(4)
+	requireInvalidEnum(decode(&enumHolder{C: colorEnum_Red, Cp: &unset}))
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/idl"
+	"github.com/cloudwego/frugal/tests/baseline"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDLDynamicCompare(t *testing.T) {
+	f, err := idl.ParseFile("baseline.thrift")
+	require.NoError(t, err)
+	s := f.Struct("Nesting")
+	require.NotNil(t, s)
+
+	v := getNestingValue()
+	buf := make([]byte, frugal.EncodedSize(v))
+	_, err = frugal.EncodeObject(buf, nil, v)
+	require.NoError(t, err)
+	m, n, err := frugal.DecodeDynamic(buf, s)
+	require.NoError(t, err)
+	require.Equal(t, len(buf), n)
+
+	sz, err := frugal.DynamicEncodedSize(s, m)
+	require.NoError(t, err)
+	require.Equal(t, len(buf), sz)
+	out := make([]byte, sz)
+	n, err = frugal.EncodeDynamic(out, s, m)
+	require.NoError(t, err)
+	require.Equal(t, sz, n)
+
+	var v1, v2 baseline.Nesting
+	_, err = frugal.DecodeObject(buf, &v1)
+	require.NoError(t, err)
+	_, err = frugal.DecodeObject(out, &v2)
+	require.NoError(t, err)
+	require.Equal(t, dumpval(v1), dumpval(v2))
+}
+
+func TestIDLDefaultValues(t *testing.T) {
+	f, err := idl.ParseFile("baseline.thrift")
+	require.NoError(t, err)
+
+	// all fields are absent, so the defaults in IDL are used
+	m, _, err := frugal.DecodeDynamic([]byte{0}, f.Struct("DefaultValues"))
+	require.NoError(t, err)
+	sz, err := frugal.DynamicEncodedSize(f.Struct("DefaultValues"), m)
+	require.NoError(t, err)
+	buf := make([]byte, sz)
+	_, err = frugal.EncodeDynamic(buf, f.Struct("DefaultValues"), m)
+	require.NoError(t, err)
+
+	v := baseline.NewDefaultValues()
+	_, err = frugal.DecodeObject(buf, v)
+	require.NoError(t, err)
+	require.Equal(t, dumpval(baseline.NewDefaultValues()), dumpval(v))
+}
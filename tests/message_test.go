@@ -0,0 +1,91 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/tests/baseline"
+	kthrift "github.com/cloudwego/gopkg/protocol/thrift"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageCompare(t *testing.T) {
+	var v baseline.Nesting2
+	loaddata(t, &v)
+	for _, strict := range []bool{true, false} {
+		mm := thrift.NewTMemoryBuffer()
+		p := thrift.NewTBinaryProtocol(mm, strict, strict)
+		require.NoError(t, p.WriteMessageBegin("Echo", thrift.REPLY, 99))
+		require.NoError(t, v.Write(p))
+		require.NoError(t, p.WriteMessageEnd())
+
+		buf := make([]byte, frugal.MessageEncodedSize("Echo", kthrift.REPLY, v))
+		encode := frugal.EncodeMessage
+		if !strict {
+			encode = frugal.EncodeMessageNonStrict
+		}
+		n, err := encode(buf, "Echo", kthrift.REPLY, 99, v)
+		require.NoError(t, err)
+		require.Equal(t, mm.Len(), n)
+
+		var v1 baseline.Nesting2
+		h, n, err := frugal.DecodeMessage(mm.Bytes(), &v1)
+		require.NoError(t, err)
+		require.Equal(t, mm.Len(), n)
+		require.Equal(t, frugal.MessageHeader{Name: "Echo", Type: kthrift.REPLY, SeqID: 99, Strict: strict}, h)
+
+		var v2 baseline.Nesting2
+		h, _, err = frugal.DecodeMessage(buf[:n], &v2)
+		require.NoError(t, err)
+		require.Equal(t, strict, h.Strict)
+		require.Equal(t, dumpval(v1), dumpval(v2))
+	}
+}
+
+func TestMessageExceptionCompare(t *testing.T) {
+	mm := thrift.NewTMemoryBuffer()
+	p := thrift.NewTBinaryProtocolTransport(mm)
+	require.NoError(t, p.WriteMessageBegin("Echo", thrift.EXCEPTION, 1))
+	require.NoError(t, thrift.NewTApplicationException(thrift.WRONG_METHOD_NAME, "wrong method").Write(p))
+	require.NoError(t, p.WriteMessageEnd())
+
+	_, n, err := frugal.DecodeMessage(mm.Bytes(), &baseline.Simple{})
+	require.Equal(t, mm.Len(), n)
+	require.Equal(t, kthrift.NewApplicationException(kthrift.WRONG_METHOD_NAME, "wrong method"), err)
+
+	ex := kthrift.NewApplicationException(kthrift.INTERNAL_ERROR, "internal error")
+	buf := make([]byte, frugal.MessageEncodedSize("Echo", kthrift.EXCEPTION, ex))
+	n, err = frugal.EncodeMessage(buf, "Echo", kthrift.EXCEPTION, 1, ex)
+	require.NoError(t, err)
+	require.Equal(t, len(buf), n)
+
+	mm = thrift.NewTMemoryBuffer()
+	_, _ = mm.Write(buf)
+	p = thrift.NewTBinaryProtocolTransport(mm)
+	name, typeID, seq, err := p.ReadMessageBegin()
+	require.NoError(t, err)
+	require.Equal(t, "Echo", name)
+	require.Equal(t, thrift.EXCEPTION, typeID)
+	require.Equal(t, int32(1), seq)
+	ex1 := thrift.NewTApplicationException(0, "")
+	require.NoError(t, ex1.Read(p))
+	require.Equal(t, int32(kthrift.INTERNAL_ERROR), ex1.TypeId())
+	require.Equal(t, "internal error", ex1.Error())
+}
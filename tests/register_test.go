@@ -0,0 +1,142 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/schema"
+	"github.com/cloudwego/frugal/tests/baseline"
+	"github.com/stretchr/testify/require"
+)
+
+// untaggedSimple is like baseline.Simple without tags, as if it's from a third-party package.
+type untaggedSimple struct {
+	ByteField   int8
+	I64Field    int64
+	DoubleField float64
+	I32Field    int32
+	StringField string
+	BinaryField []byte
+	EnumField   baseline.Enums
+
+	state int // not serialized
+}
+
+type untaggedNesting struct {
+	Simple  *untaggedSimple
+	Simples map[string]*untaggedSimple
+}
+
+func TestRegisterStruct(t *testing.T) {
+	require.NoError(t, frugal.RegisterStruct(reflect.TypeOf(&untaggedSimple{}), []frugal.FieldSpec{
+		{Field: "ByteField", ID: 1, Type: "byte"},
+		{Field: "I64Field", ID: 2},
+		{Field: "DoubleField", ID: 3},
+		{Field: "I32Field", ID: 4},
+		{Field: "StringField", ID: 5, NoCopy: true},
+		{Field: "BinaryField", ID: 6},
+		{Field: "EnumField", ID: 7, Type: "Enums"},
+	}))
+	require.NoError(t, frugal.RegisterStruct(reflect.TypeOf(untaggedNesting{}), []frugal.FieldSpec{
+		{Field: "Simple", ID: 8, Requiredness: schema.Optional},
+		{Field: "Simples", ID: 15, Type: "map<string:untaggedSimple>"},
+	}))
+
+	p0 := getSimpleValue()
+	v := &untaggedSimple{
+		ByteField: p0.ByteField, I64Field: p0.I64Field, DoubleField: p0.DoubleField, I32Field: p0.I32Field,
+		StringField: p0.StringField, BinaryField: p0.BinaryField, EnumField: baseline.Enums_ValueB, state: 1,
+	}
+	p0.EnumField = baseline.Enums_ValueB
+	expect := make([]byte, frugal.EncodedSize(p0))
+	_, err := frugal.EncodeObject(expect, nil, p0)
+	require.NoError(t, err)
+	buf := make([]byte, frugal.EncodedSize(v))
+	_, err = frugal.EncodeObject(buf, nil, v)
+	require.NoError(t, err)
+	require.Equal(t, expect, buf)
+
+	// it's compatible with the part of baseline.Nesting
+	var n baseline.Nesting
+	n.SimpleStruct = p0
+	n.MapStringSimple = map[string]*baseline.Simple{"k": p0}
+	buf = make([]byte, frugal.EncodedSize(&n))
+	_, err = frugal.EncodeObject(buf, nil, &n)
+	require.NoError(t, err)
+	var un untaggedNesting
+	_, err = frugal.DecodeObject(buf, &un)
+	require.NoError(t, err)
+	v.state = 0
+	require.Equal(t, v, un.Simple)
+	require.Equal(t, map[string]*untaggedSimple{"k": v}, un.Simples)
+
+	// types can only be registered before used
+	err = frugal.RegisterStruct(reflect.TypeOf(untaggedSimple{}), nil)
+	require.ErrorContains(t, err, "already")
+	err = frugal.RegisterStruct(reflect.TypeOf(baseline.Simple{}), nil)
+	require.ErrorContains(t, err, "already in use")
+	err = frugal.RegisterStruct(reflect.TypeOf(struct{ A, B int }{}), []frugal.FieldSpec{{Field: "A"}, {Field: "A"}})
+	require.ErrorContains(t, err, "duplicated field spec")
+}
+
+// untaggedOptions has fields which need tag options, as if it's from a third-party package.
+type untaggedOptions struct {
+	U32 uint32
+	F   float32
+}
+
+func TestRegisterStructOptions(t *testing.T) {
+	require.NoError(t, frugal.RegisterStruct(reflect.TypeOf(untaggedOptions{}), []frugal.FieldSpec{
+		{Field: "U32", ID: 1, Options: []string{"unsigned", "rangecheck"}},
+		{Field: "F", ID: 2, Type: "double", Options: []string{"precisioncheck"}},
+	}))
+
+	v := &untaggedOptions{U32: math.MaxInt32, F: 0.5}
+	buf := make([]byte, frugal.EncodedSize(v))
+	_, err := frugal.EncodeObject(buf, nil, v)
+	require.NoError(t, err)
+	var p untaggedOptions
+	_, err = frugal.DecodeObject(buf, &p)
+	require.NoError(t, err)
+	require.Equal(t, *v, p)
+
+	// "rangecheck" rejects values which don't fit in i32
+	v.U32 = math.MaxInt32 + 1
+	_, err = frugal.EncodeObject(make([]byte, frugal.EncodedSize(v)), nil, v)
+	require.ErrorContains(t, err, "overflows")
+
+	// "precisioncheck" rejects doubles which can't be narrowed to float32 exactly
+	type doubleField struct {
+		F float64 `frugal:"2,default,double"`
+	}
+	d := &doubleField{F: 0.1}
+	buf = make([]byte, frugal.EncodedSize(d))
+	_, err = frugal.EncodeObject(buf, nil, d)
+	require.NoError(t, err)
+	_, err = frugal.DecodeObject(buf, &p)
+	require.ErrorContains(t, err, "losing precision")
+
+	// options are validated like tags
+	err = frugal.RegisterStruct(reflect.TypeOf(struct{ A int32 }{}), []frugal.FieldSpec{
+		{Field: "A", ID: 1, Options: []string{"unsigned"}},
+	})
+	require.ErrorContains(t, err, `"unsigned" is only applicable`)
+}
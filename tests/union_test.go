@@ -0,0 +1,219 @@
+/*
+ * Copyright 2026 CloudWeGo Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/frugal"
+	"github.com/cloudwego/frugal/schema"
+	kthrift "github.com/cloudwego/gopkg/protocol/thrift"
+	"github.com/stretchr/testify/require"
+)
+
+type unionValue struct {
+	I *int64  `frugal:"1,optional,i64"`
+	S *string `frugal:"2,optional,string"`
+	L []int32 `frugal:"3,optional,list<i32>"`
+}
+
+// unionLike is the same as unionValue, but it's not registered as a union.
+type unionLike struct {
+	I *int64  `frugal:"1,optional,i64"`
+	S *string `frugal:"2,optional,string"`
+	L []int32 `frugal:"3,optional,list<i32>"`
+}
+
+type unionHolder struct {
+	U  *unionValue   `frugal:"1,optional,unionValue"`
+	Us []*unionValue `frugal:"2,default,list<unionValue>"`
+}
+
+func requireInvalidData(t *testing.T, err error) {
+	t.Helper()
+	var pe *kthrift.ProtocolException
+	require.True(t, errors.As(err, &pe), err)
+	require.Equal(t, int32(kthrift.INVALID_DATA), pe.TypeId())
+	require.Contains(t, err.Error(), "must have exactly one field set")
+}
+
+func TestRegisterUnion(t *testing.T) {
+	require.NoError(t, frugal.RegisterUnion(reflect.TypeOf(&unionValue{})))
+	require.Error(t, frugal.RegisterUnion(reflect.TypeOf(unionValue{})))
+	require.Error(t, frugal.RegisterUnion(reflect.TypeOf(struct {
+		I int64 `frugal:"1,optional,i64"`
+	}{})))
+	require.Error(t, frugal.RegisterUnion(reflect.TypeOf(struct {
+		I *int64 `frugal:"1,default,i64"`
+	}{})))
+
+	s := "s"
+	v := &unionHolder{U: &unionValue{S: &s}, Us: []*unionValue{{L: []int32{}}, {I: new(int64)}}}
+	id, err := frugal.UnionFieldID(v.U)
+	require.NoError(t, err)
+	require.Equal(t, uint16(2), id)
+	id, err = frugal.UnionFieldID(*v.Us[0])
+	require.NoError(t, err)
+	require.Equal(t, uint16(3), id)
+	_, err = frugal.UnionFieldID(&unionLike{})
+	require.Error(t, err)
+
+	buf := make([]byte, frugal.EncodedSize(v))
+	_, err = frugal.EncodeObject(buf, nil, v)
+	require.NoError(t, err)
+	got := &unionHolder{}
+	_, err = frugal.DecodeObject(buf, got)
+	require.NoError(t, err)
+	require.Equal(t, v, got)
+
+	// exactly one field must be set when encoding
+	for _, u := range []*unionValue{{}, {I: new(int64), S: &s}} {
+		v := &unionHolder{U: u}
+		buf := make([]byte, frugal.EncodedSize(v))
+		_, err = frugal.EncodeObject(buf, nil, v)
+		requireInvalidData(t, err)
+		_, err = frugal.EncodeTo(&bytes.Buffer{}, v)
+		requireInvalidData(t, err)
+		buf = make([]byte, frugal.CompactEncodedSize(v))
+		_, err = frugal.EncodeCompact(buf, v)
+		requireInvalidData(t, err)
+		_, err = frugal.UnionFieldID(u)
+		requireInvalidData(t, err)
+	}
+
+	// and decoding
+	for _, u := range []*unionLike{{}, {I: new(int64), L: []int32{1}}} {
+		buf := make([]byte, frugal.EncodedSize(u))
+		_, err = frugal.EncodeObject(buf, nil, u)
+		require.NoError(t, err)
+		_, err = frugal.DecodeObject(buf, &unionValue{})
+		requireInvalidData(t, err)
+		_, err = frugal.DecodeFrom(bytes.NewReader(buf), &unionValue{})
+		requireInvalidData(t, err)
+
+		dyn, err := frugal.Describe(reflect.TypeOf(unionValue{}))
+		require.NoError(t, err)
+		require.True(t, dyn.Union)
+		_, _, err = frugal.DecodeDynamic(buf, dyn)
+		requireInvalidData(t, err)
+
+		buf = make([]byte, frugal.CompactEncodedSize(u))
+		_, err = frugal.EncodeCompact(buf, u)
+		require.NoError(t, err)
+		_, err = frugal.DecodeCompact(buf, &unionValue{})
+		requireInvalidData(t, err)
+	}
+
+	// dynamic values
+	dyn, err := frugal.Describe(reflect.TypeOf(unionValue{}))
+	require.NoError(t, err)
+	_, err = frugal.EncodeDynamic(make([]byte, 64), dyn, map[string]interface{}{"I": int64(1), "S": "s"})
+	requireInvalidData(t, err)
+	m := map[string]interface{}{"S": "s"}
+	n, err := frugal.DynamicEncodedSize(dyn, m)
+	require.NoError(t, err)
+	buf = make([]byte, n)
+	_, err = frugal.EncodeDynamic(buf, dyn, m)
+	require.NoError(t, err)
+	got1 := &unionValue{}
+	_, err = frugal.DecodeObject(buf, got1)
+	require.NoError(t, err)
+	require.Equal(t, &unionValue{S: &s}, got1)
+}
+
+// untaggedUnionA and untaggedUnionB are unions declared by RegisterStruct.
+type untaggedUnionA struct {
+	I *int64
+	S *string
+}
+
+type untaggedUnionB struct {
+	I *int64
+	S *string
+}
+
+func TestRegisterUnionWithStruct(t *testing.T) {
+	specs := []frugal.FieldSpec{
+		{Field: "I", ID: 1, Requiredness: schema.Optional},
+		{Field: "S", ID: 2, Requiredness: schema.Optional},
+	}
+	// the result doesn't depend on the call order
+	require.NoError(t, frugal.RegisterUnion(reflect.TypeOf(untaggedUnionA{})))
+	require.NoError(t, frugal.RegisterStruct(reflect.TypeOf(untaggedUnionA{}), specs))
+	require.NoError(t, frugal.RegisterStruct(reflect.TypeOf(untaggedUnionB{}), specs))
+	require.NoError(t, frugal.RegisterUnion(reflect.TypeOf(untaggedUnionB{})))
+
+	s := "s"
+	for _, v := range []interface{}{&untaggedUnionA{S: &s}, &untaggedUnionB{S: &s}} {
+		id, err := frugal.UnionFieldID(v)
+		require.NoError(t, err)
+		require.Equal(t, uint16(2), id)
+	}
+	_, err := frugal.EncodeObject(make([]byte, 64), nil, &untaggedUnionA{})
+	requireInvalidData(t, err)
+	_, err = frugal.EncodeObject(make([]byte, 64), nil, &untaggedUnionB{})
+	requireInvalidData(t, err)
+
+	// the fields are validated in both orders
+	type badUnion struct{ I int64 }
+	require.NoError(t, frugal.RegisterUnion(reflect.TypeOf(badUnion{})))
+	err = frugal.RegisterStruct(reflect.TypeOf(badUnion{}), []frugal.FieldSpec{{Field: "I", ID: 1}})
+	require.ErrorContains(t, err, "of union must be optional")
+	type badStruct struct{ I int64 }
+	require.NoError(t, frugal.RegisterStruct(reflect.TypeOf(badStruct{}), []frugal.FieldSpec{{Field: "I", ID: 1}}))
+	err = frugal.RegisterUnion(reflect.TypeOf(badStruct{}))
+	require.ErrorContains(t, err, "of union must be optional")
+}
+
+// unionWithExtra is unionValue with a field unknown to it.
+type unionWithExtra struct {
+	I *int64 `frugal:"1,optional,i64"`
+	X *int64 `frugal:"9,optional,i64"`
+}
+
+func TestUnionUnknownFields(t *testing.T) {
+	// unknown fields are not counted
+	i := int64(1)
+	buf := make([]byte, frugal.EncodedSize(&unionWithExtra{I: &i, X: &i}))
+	_, err := frugal.EncodeObject(buf, nil, &unionWithExtra{I: &i, X: &i})
+	require.NoError(t, err)
+	got := &unionValue{}
+	_, err = frugal.DecodeObject(buf, got)
+	require.NoError(t, err)
+	require.Equal(t, &unionValue{I: &i}, got)
+	_, err = frugal.DecodeFrom(bytes.NewReader(buf), &unionValue{})
+	require.NoError(t, err)
+	dyn, err := frugal.Describe(reflect.TypeOf(unionValue{}))
+	require.NoError(t, err)
+	_, _, err = frugal.DecodeDynamic(buf, dyn)
+	require.NoError(t, err)
+	cbuf := make([]byte, frugal.CompactEncodedSize(&unionWithExtra{I: &i, X: &i}))
+	_, err = frugal.EncodeCompact(cbuf, &unionWithExtra{I: &i, X: &i})
+	require.NoError(t, err)
+	_, err = frugal.DecodeCompact(cbuf, &unionValue{})
+	require.NoError(t, err)
+
+	// and a union with only unknown fields has no field set
+	buf = make([]byte, frugal.EncodedSize(&unionWithExtra{X: &i}))
+	_, err = frugal.EncodeObject(buf, nil, &unionWithExtra{X: &i})
+	require.NoError(t, err)
+	_, err = frugal.DecodeObject(buf, &unionValue{})
+	requireInvalidData(t, err)
+}